@@ -0,0 +1,167 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	googleCalendarURL = "https://calendar.google.com/calendar/render"
+	outlookComposeURL = "https://outlook.live.com/calendar/0/action/compose"
+)
+
+// eventAddLinks handles GET /api/schedule/events/{id}/add-links.
+//
+// Returns the links of "add to your calendar" buttons: a Google Calendar and
+// an Outlook web compose URL prefilled with the event, and a direct .ics
+// download of it through the event's short link. Times are UTC, all-day
+// events are sent as dates and floating ones as wall clock times. Only Google
+// takes the rrule; Outlook gets the first occurrence. The .ics link is a
+// short link, so the route is gated like /link.
+func (h *handlers) eventAddLinks(e *core.RequestEvent) error {
+	rec, err := findLinkableEvent(e)
+	if err != nil {
+		return err
+	}
+	ev := calendar.EventFromRecord(rec)
+
+	link, err := shortLink(e.App, ev.ID)
+	if err != nil {
+		return e.InternalServerError("Failed to create the short link.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"google":  googleAddURL(ev),
+		"outlook": outlookAddURL(ev),
+		"ics":     shortURL(baseURL(e), link.GetString("code")) + "/event.ics",
+	})
+}
+
+// allDayEnd is the exclusive end date of an all-day event, a day after its
+// start when it has no length.
+func allDayEnd(ev *calendar.Event) time.Time {
+	if !ev.End.After(ev.Start) {
+		return ev.Start.AddDate(0, 0, 1)
+	}
+	return ev.End
+}
+
+func googleAddURL(ev *calendar.Event) string {
+	var dates string
+	switch {
+	case ev.AllDay:
+		dates = ev.Start.UTC().Format("20060102") + "/" + allDayEnd(ev).UTC().Format("20060102")
+	case ev.Floating:
+		// without a Z Google reads the times in the calendar's own zone
+		dates = ev.Start.UTC().Format("20060102T150405") + "/" + ev.End.UTC().Format("20060102T150405")
+	default:
+		dates = ev.Start.UTC().Format("20060102T150405Z") + "/" + ev.End.UTC().Format("20060102T150405Z")
+	}
+
+	q := url.Values{
+		"action": {"TEMPLATE"},
+		"text":   {ev.Title},
+		"dates":  {dates},
+	}
+	if ev.Location != "" {
+		q.Set("location", ev.Location)
+	}
+	if ev.Notes != "" {
+		q.Set("details", ev.Notes)
+	}
+	if ev.IsRecurring() {
+		q.Set("recur", "RRULE:"+ev.RRule)
+	}
+	if ev.Timezone != "" && !ev.AllDay && !ev.Floating {
+		q.Set("ctz", ev.Timezone)
+	}
+	return googleCalendarURL + "?" + encodeQuery(q)
+}
+
+func outlookAddURL(ev *calendar.Event) string {
+	q := url.Values{
+		"path":    {"/calendar/action/compose"},
+		"rru":     {"addevent"},
+		"subject": {ev.Title},
+	}
+	switch {
+	case ev.AllDay:
+		q.Set("startdt", ev.Start.UTC().Format(time.DateOnly))
+		q.Set("enddt", allDayEnd(ev).UTC().Format(time.DateOnly))
+		q.Set("allday", "true")
+	case ev.Floating:
+		q.Set("startdt", ev.Start.UTC().Format("2006-01-02T15:04:05"))
+		q.Set("enddt", ev.End.UTC().Format("2006-01-02T15:04:05"))
+	default:
+		q.Set("startdt", ev.Start.UTC().Format(time.RFC3339))
+		q.Set("enddt", ev.End.UTC().Format(time.RFC3339))
+	}
+	if ev.Location != "" {
+		q.Set("location", ev.Location)
+	}
+	if ev.Notes != "" {
+		q.Set("body", ev.Notes)
+	}
+	return outlookComposeURL + "?" + encodeQuery(q)
+}
+
+// encodeQuery encodes q with spaces as %20: not every provider reads "+" in
+// a query as a space.
+func encodeQuery(q url.Values) string {
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+// shortLinkICS handles GET /e/{code}/event.ics.
+//
+// Public like the short link itself: the event behind the code (with the
+// detached occurrences of a series) as a single VCALENDAR download.
+func (h *handlers) shortLinkICS(e *core.RequestEvent) error {
+	link, err := e.App.FindFirstRecordByData(shortLinksCollection, "code", e.Request.PathValue("code"))
+	if err != nil {
+		return e.NotFoundError("Link not found.", err)
+	}
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, link.GetString("event"))
+	if err != nil {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	return h.serveEventICS(e, rec)
+}
+
+// eventICS handles GET /api/schedule/events/{id}/event.ics.
+//
+// The event (with the detached occurrences of a series) as a VCALENDAR, like
+// the short link download: DTSTAMP is the last modification and SEQUENCE
+// counts the time and location changes, so a client importing it again
+// tells a new revision from one it already has. Only the owner and users the
+// event is shared with can download it.
+func (h *handlers) eventICS(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || !calendar.CanAccess(e.App, e.Auth, rec, calendar.SharePermissionRead) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	return h.serveEventICS(e, rec)
+}
+
+// serveEventICS answers with rec, and its detached occurrences, as an
+// event.ics download.
+func (h *handlers) serveEventICS(e *core.RequestEvent, rec *core.Record) error {
+	ev := calendar.EventFromRecord(rec)
+	events := []*calendar.Event{ev}
+	if ev.IsRecurring() {
+		detached, err := calendar.FindDetached(e.App, ev.ID)
+		if err != nil {
+			return e.InternalServerError("Failed to load the detached occurrences.", err)
+		}
+		events = append(events, detached...)
+	}
+
+	e.Response.Header().Set("Content-Disposition", `attachment; filename="event.ics"`)
+	return h.writeICS(e, ev.Owner, events, ev.Title)
+}
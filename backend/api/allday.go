@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// toggleAllDay handles POST /api/schedule/events/{id}/toggle-allday.
+//
+// Converts a timed event to all-day or back (see calendar.ToggleAllDay),
+// keeping its recurrence; dates are taken in the event timezone, else
+// ?timezone. Returns the saved event. App users can only change their own
+// events.
+func (h *handlers) toggleAllDay(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	t := calendar.ToggleAllDay(calendar.EventFromRecord(rec), loc)
+	exdates := make([]string, len(t.Exdates))
+	for i, x := range t.Exdates {
+		exdates[i] = calendar.ISO(x)
+	}
+
+	rec.Set("allDay", t.AllDay)
+	rec.Set("start", t.Start)
+	rec.Set("end", t.End)
+	rec.Set("exdates", exdates)
+	calendar.SetChangedBy(rec, e.Auth)
+	if err := e.App.Save(rec); err != nil {
+		return e.BadRequestError("Failed to convert the event.", err)
+	}
+
+	return e.JSON(http.StatusOK, rec)
+}
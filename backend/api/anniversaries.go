@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+	"schedule/recur"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultAnniversaryDays and maxAnniversaryDays bound ?within of
+// /anniversaries.
+const (
+	defaultAnniversaryDays = 30
+	maxAnniversaryDays     = 366
+)
+
+// anniversaryTags mark events as anniversaries whatever their recurrence.
+var anniversaryTags = []string{"anniversary", "birthday"}
+
+// anniversary is an /anniversaries item: the next occurrence of the event and,
+// for yearly series, how many years it is since the first one.
+type anniversary struct {
+	calendar.Occurrence
+	Years *int `json:"years"`
+}
+
+// anniversaries handles GET /api/schedule/anniversaries?within=&timezone=&category=.
+//
+// Lists the next occurrence of every yearly series, and of the events tagged
+// as anniversaries, in the next ?within days (e.g. 30d, the default, at most
+// 366) from the start of today, soonest first. years counts the years since
+// the first start of a yearly series (the age, for a birthday entered on the
+// day of birth), in the event timezone. ?category limits the list to one
+// category, e.g. Personal.
+func (h *handlers) anniversaries(e *core.RequestEvent) error {
+	q := e.Request.URL.Query()
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	days := defaultAnniversaryDays
+	if v := q.Get("within"); v != "" {
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+		if err != nil || n < 1 || n > maxAnniversaryDays {
+			return e.BadRequestError("within must be between 1d and "+strconv.Itoa(maxAnniversaryDays)+"d.", err)
+		}
+		days = n
+	}
+
+	from := calendar.StartOfDay(time.Now(), loc)
+	to := from.AddDate(0, 0, days)
+	v, err := h.loadView(e, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	yearly := map[string]*calendar.Event{}
+	tagged := map[string]bool{}
+	for _, ev := range v.events {
+		if c := q.Get("category"); c != "" && !strings.EqualFold(ev.Category, c) {
+			continue
+		}
+		if rule, err := recur.Parse(ev.RRule); err == nil && rule.Freq == recur.Yearly {
+			yearly[ev.ID] = ev
+		}
+		if slices.ContainsFunc(ev.Tags, func(t string) bool {
+			return slices.Contains(anniversaryTags, strings.ToLower(t))
+		}) {
+			tagged[ev.ID] = true
+		}
+	}
+	v.events = slices.DeleteFunc(v.events, func(ev *calendar.Event) bool {
+		series := ev.ID
+		if ev.IsDetached() {
+			series = ev.SourceID
+		}
+		return yearly[series] == nil && !tagged[series]
+	})
+
+	items := []anniversary{}
+	seen := map[string]bool{}
+	err = v.stream(from, to, loc, func(o calendar.Occurrence) error {
+		series := o.ID
+		if o.SourceID != "" {
+			series = o.SourceID
+		}
+		if seen[series] {
+			return nil
+		}
+		seen[series] = true
+
+		item := anniversary{Occurrence: o}
+		if ev := yearly[series]; ev != nil {
+			zone := ev.Zone(loc)
+			n := o.Start.In(zone).Year() - ev.Start.In(zone).Year()
+			item.Years = &n
+		}
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":     from.UTC(),
+		"to":       to.UTC(),
+		"timezone": loc.String(),
+		"items":    items,
+	})
+}
@@ -0,0 +1,164 @@
+// Package api registers the custom /api/schedule routes on top of the
+// PocketBase router.
+package api
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// expansionCacheSize is how many (event, window) expansions are cached.
+const expansionCacheSize = 4096
+
+// handlers carries the shared state of the schedule routes.
+type handlers struct {
+	cfg *config.Config
+
+	resetByEmail *rateLimiter
+	resetByIP    *rateLimiter
+
+	// expansions caches the per-event expansions of the occurrence routes.
+	expansions *calendar.ExpansionCache
+}
+
+// Register binds the schedule routes to the serve event router.
+func Register(se *core.ServeEvent, cfg *config.Config) {
+	h := &handlers{
+		cfg:          cfg,
+		resetByEmail: newRateLimiter(resetPerEmail, resetWindow),
+		resetByIP:    newRateLimiter(resetPerIP, resetWindow),
+		expansions:   calendar.NewExpansionCache(expansionCacheSize),
+	}
+
+	se.Router.BindFunc(h.limitPasswordReset)
+	se.App.OnRecordRequestPasswordResetRequest(calendar.UsersCollection).BindFunc(rememberResetBaseURL)
+	se.App.OnMailerRecordPasswordResetSend(calendar.UsersCollection).BindFunc(rewriteResetLinks)
+
+	// token authenticated, for calendar apps that can't send headers
+	public := se.Router.Group("/api/schedule")
+	public.GET("/ics", h.icsFeed)
+	public.GET("/export/{file}", h.categoryExport) // {category}.ics
+	public.GET("/export.ics", h.rangeExport)
+
+	// short event links, outside /api so they stay short
+	se.Router.GET("/e/{code}", h.shortLinkRedirect)
+	se.Router.GET("/e/{code}/event.ics", h.shortLinkICS)
+
+	g := se.Router.Group("/api/schedule")
+	g.Bind(apis.RequireAuth())
+
+	g.GET("/occurrences", h.occurrences)
+	g.GET("/occurrences.ndjson", h.occurrencesNDJSON)
+	g.GET("/occurrences.msgpack", h.occurrencesMsgpack)
+	g.GET("/agenda", h.agenda)
+	g.GET("/month", h.month)
+	g.GET("/layout", h.layout)
+	g.GET("/calendar-meta", h.calendarMeta)
+	g.GET("/freebusy", h.freebusy)
+	g.POST("/team-freebusy", h.teamFreebusy)
+	g.GET("/print", h.printAgenda)
+	g.GET("/nearest", h.nearest)
+	g.GET("/busy-now", h.busyNow)
+	g.GET("/free-summary", h.freeSummary)
+	g.GET("/next-focus", h.nextFocus)
+	g.GET("/anniversaries", h.anniversaries)
+	g.POST("/import", h.importICS)
+	g.POST("/suggest", h.suggest)
+	g.POST("/convert-tz", h.convertTZ)
+	g.POST("/rrule/count", h.rruleCount)
+	g.GET("/rrule/validate", h.rruleValidate)
+	g.GET("/reminders/upcoming", h.upcomingReminders)
+	g.GET("/locations", h.locations)
+
+	g.POST("/ics/token", h.icsToken).Bind(apis.RequireAuth(calendar.UsersCollection))
+	g.POST("/ics/rotate", h.icsRotate).Bind(apis.RequireAuth(calendar.UsersCollection))
+	g.POST("/block-day", h.blockDay).Bind(apis.RequireAuth(calendar.UsersCollection))
+
+	g.GET("/push/key", h.pushKey)
+	g.POST("/push/subscriptions", h.pushSubscribe).Bind(apis.RequireAuth(calendar.UsersCollection))
+
+	g.GET("/events/count", h.eventCount)
+	g.GET("/events/summary", h.eventsSummary)
+	g.GET("/events/by-attendee", h.eventsByAttendee)
+	g.GET("/events/similar", h.similarEvents)
+	g.POST("/events/bulk-import", h.bulkImport)
+	g.POST("/events/transfer", h.transferEvents)
+	g.GET("/events/{id}", h.eventView) // also serves HEAD
+	g.GET("/events/{id}/link", h.eventLink)
+	g.GET("/events/{id}/event.ics", h.eventICS)
+	g.GET("/events/{id}/qr.png", h.eventQR)
+	g.GET("/events/{id}/add-links", h.eventAddLinks)
+	g.GET("/events/{id}/occurrences", h.seriesOccurrences)
+	g.GET("/events/{id}/first-occurrence", h.firstOccurrence)
+	g.GET("/events/{id}/history", h.eventHistory)
+	g.POST("/events/{id}/edit-preview", h.editPreview)
+	g.POST("/events/{id}/split", h.splitSeries)
+	g.POST("/events/{id}/delete-occurrence", h.deleteOccurrence)
+	g.POST("/events/{id}/toggle-allday", h.toggleAllDay)
+	g.POST("/events/{id}/limit-future", h.limitFuture)
+	g.POST("/events/{id}/pause", h.pauseSeries)
+	g.POST("/events/{id}/resume", h.resumeSeries)
+	g.POST("/events/{id}/reanchor", h.reanchorSeries)
+	g.POST("/events/{id}/move", h.moveEvent)
+	g.POST("/events/{id}/clone-series", h.cloneSeries)
+	g.POST("/events/{id}/transfer", h.transferEvent)
+	g.POST("/events/{id}/shares", h.shareEvent)
+	g.DELETE("/events/{id}/shares/{user}", h.unshareEvent)
+	g.POST("/events/{id}/checklist/add", h.checklistAdd)
+	g.POST("/events/{id}/checklist/toggle", h.checklistToggle)
+	g.POST("/events/{id}/rsvp", h.rsvp).Bind(apis.RequireAuth(calendar.UsersCollection))
+	g.POST("/events/{id}/dismiss-reminder", h.dismissReminder)
+
+	g.GET("/metrics", h.metrics).Bind(apis.RequireSuperuserAuth())
+	g.GET("/settings", h.settings).Bind(apis.RequireSuperuserAuth())
+	g.GET("/backup.json", h.backup).Bind(apis.RequireSuperuserAuth())
+	g.POST("/restore.json", h.restore).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/rematerialize", h.rematerialize).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/purge", h.purge).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/normalize", h.normalize).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/reminders/reset", h.remindersReset).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/reminders/mark-sent", h.remindersMarkSent).Bind(apis.RequireSuperuserAuth())
+	g.GET("/maintenance/validate", h.validateCalendar).Bind(apis.RequireSuperuserAuth())
+	g.POST("/maintenance/test-email", h.testEmail).Bind(apis.RequireSuperuserAuth())
+	g.POST("/categories/{id}/merge-into/{targetId}", h.mergeCategory).Bind(apis.RequireSuperuserAuth())
+}
+
+// location resolves ?timezone, falling back to the configured default.
+func (h *handlers) location(e *core.RequestEvent) (*time.Location, error) {
+	name := e.Request.URL.Query().Get("timezone")
+	if name == "" {
+		return h.cfg.Timezone, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// calendarIDs resolves ?calendar, a comma separated list of calendar ids the
+// response is limited to. Empty means all calendars.
+func (h *handlers) calendarIDs(e *core.RequestEvent) []string {
+	v := e.Request.URL.Query().Get("calendar")
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// weekStart resolves ?weekStart (0=Sunday .. 6=Saturday), falling back to the
+// configured default.
+func (h *handlers) weekStart(e *core.RequestEvent) (time.Weekday, error) {
+	v := e.Request.URL.Query().Get("weekStart")
+	if v == "" {
+		return h.cfg.WeekStart, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 || n > 6 {
+		return 0, strconv.ErrRange
+	}
+	return time.Weekday(n), nil
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+	"schedule/hooks"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// fixture is a migrated data dir with two users, for the route tests to
+// clone an app from per scenario.
+type fixture struct {
+	t       testing.TB
+	dir     string
+	cfg     *config.Config
+	owner   *core.Record
+	other   *core.Record
+	records map[string]string // fixture name -> record id
+}
+
+// newFixture migrates a fresh app and lets seed add records before its data
+// dir is frozen. The owner and other users exist beforehand.
+func newFixture(t testing.TB, seed func(app core.App, f *fixture)) *fixture {
+	t.Helper()
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{t: t, cfg: cfg, records: map[string]string{}}
+
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app.Cleanup)
+	hooks.Register(app, cfg)
+
+	f.owner = f.user(app, "owner@example.com")
+	f.other = f.user(app, "other@example.com")
+	if seed != nil {
+		seed(app, f)
+	}
+	f.dir = app.DataDir()
+	return f
+}
+
+// factory clones the fixture into an app serving the schedule routes.
+func (f *fixture) factory(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp(f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks.Register(app, f.cfg)
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		Register(se, f.cfg)
+		return se.Next()
+	})
+	return app
+}
+
+func (f *fixture) user(app core.App, email string) *core.Record {
+	f.t.Helper()
+	users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	rec := core.NewRecord(users)
+	rec.SetEmail(email)
+	rec.SetPassword("password123")
+	if err := app.Save(rec); err != nil {
+		f.t.Fatal(err)
+	}
+	return rec
+}
+
+// event saves an event with the given fields under name.
+func (f *fixture) event(app core.App, name string, fields map[string]any) *core.Record {
+	f.t.Helper()
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	rec := core.NewRecord(events)
+	rec.Load(fields)
+	if err := app.Save(rec); err != nil {
+		f.t.Fatal(err)
+	}
+	f.records[name] = rec.Id
+	return rec
+}
+
+// auth is the Authorization header of a user.
+func (f *fixture) auth(user *core.Record) map[string]string {
+	f.t.Helper()
+	token, err := user.NewAuthToken()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return map[string]string{"Authorization": token}
+}
+
+// fixtureDay is the day the fixture events happen on.
+var fixtureDay = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// at is an ISO time of fixtureDay.
+func at(hour, minute int) string {
+	return atDay(0, hour, minute)
+}
+
+// atDay is an ISO time days after fixtureDay.
+func atDay(days, hour, minute int) string {
+	return calendar.ISO(fixtureDay.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute))
+}
+
+func TestOccurrencesScopedToCaller(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.event(app, "standup", map[string]any{"title": "Standup", "start": at(9, 0), "end": at(9, 15), "owner": f.owner.Id})
+		f.event(app, "review", map[string]any{"title": "Review", "start": at(14, 0), "end": at(15, 0), "owner": f.other.Id})
+	})
+	url := "/api/schedule/occurrences?start=2026-03-10&end=2026-03-11&timezone=UTC"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			URL:             url,
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:               "owner only sees their events",
+			Method:             http.MethodGet,
+			URL:                url,
+			Headers:            f.auth(f.owner),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Standup"`},
+			NotExpectedContent: []string{`"title":"Review"`},
+		},
+		{
+			Name:               "other user only sees theirs",
+			Method:             http.MethodGet,
+			URL:                url,
+			Headers:            f.auth(f.other),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Review"`},
+			NotExpectedContent: []string{`"title":"Standup"`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
+
+// The range keywords are resolved against the current time, so the events
+// sit on the days only one of the Sunday- and Monday-start weeks of today
+// holds.
+func TestOccurrencesThisWeek(t *testing.T) {
+	today := calendar.StartOfDay(time.Now(), time.UTC)
+	sunday := today.AddDate(0, 0, -int(today.Weekday()))
+	monday := today.AddDate(0, 0, -int(today.Weekday()+6)%7)
+	sundayOnly, mondayOnly := sunday, sunday.AddDate(0, 0, 7)
+	if monday.Before(sunday) { // today is a Sunday
+		sundayOnly, mondayOnly = monday.AddDate(0, 0, 7), monday
+	}
+
+	f := newFixture(t, func(app core.App, f *fixture) {
+		for title, day := range map[string]time.Time{"Sunday week": sundayOnly, "Monday week": mondayOnly} {
+			f.event(app, title, map[string]any{
+				"title": title, "start": calendar.ISO(day.Add(12 * time.Hour)), "end": calendar.ISO(day.Add(13 * time.Hour)), "owner": f.owner.Id,
+			})
+		}
+	})
+	window := func(from time.Time) string {
+		return `"from":"` + from.Format(time.RFC3339) + `","items":`
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:               "Sunday start",
+			URL:                "/api/schedule/occurrences?range=this-week&timezone=UTC&weekStart=0",
+			ExpectedContent:    []string{window(sunday), `"title":"Sunday week"`, `"to":"` + sunday.AddDate(0, 0, 7).Format(time.RFC3339) + `"`},
+			NotExpectedContent: []string{`"title":"Monday week"`},
+		},
+		{
+			Name:               "Monday start",
+			URL:                "/api/schedule/occurrences?range=this-week&timezone=UTC&weekStart=1",
+			ExpectedContent:    []string{window(monday), `"title":"Monday week"`, `"to":"` + monday.AddDate(0, 0, 7).Format(time.RFC3339) + `"`},
+			NotExpectedContent: []string{`"title":"Sunday week"`},
+		},
+		{
+			Name:               "next week from Monday",
+			URL:                "/api/schedule/occurrences?range=next-week&timezone=UTC&weekStart=1",
+			ExpectedContent:    []string{window(monday.AddDate(0, 0, 7))},
+			NotExpectedContent: []string{`"title":"Monday week"`},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodGet
+		s.Headers = f.auth(f.owner)
+		s.TestAppFactory = f.factory
+		s.ExpectedStatus = http.StatusOK
+		s.Test(t)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxRestoreBytes caps the size of a /restore.json document.
+const maxRestoreBytes = 64 << 20
+
+// backup handles GET /api/schedule/backup.json.
+//
+// Superuser only. Downloads every category, calendar, subscription, event,
+// attendee and share, with the ids and emails of the users and the effective
+// settings, as one versioned JSON document (see calendar.Backup).
+// Unlike PocketBase's backups it is readable and can be restored into another
+// instance with /restore.json.
+func (h *handlers) backup(e *core.RequestEvent) error {
+	b, err := calendar.NewBackup(e.App, h.settingsMap())
+	if err != nil {
+		return e.InternalServerError("Failed to read the data.", err)
+	}
+
+	e.Response.Header().Set("Content-Disposition", `attachment; filename="schedule-backup.json"`)
+	return e.JSON(http.StatusOK, b)
+}
+
+// restore handles POST /api/schedule/restore.json?dryRun=true.
+//
+// Superuser only. Body: a /backup.json document, whose version must be
+// calendar.BackupVersion. Creates or overwrites its records by id in one
+// transaction (see calendar.Restore); with dryRun, or when a record fails,
+// nothing is kept. Returns {dryRun, created, updated, failed}, 400 when
+// something failed, and 400 up front when a user of the document has no
+// account here. The settings of the document are not restored.
+func (h *handlers) restore(e *core.RequestEvent) error {
+	var b calendar.Backup
+	if err := json.NewDecoder(io.LimitReader(e.Request.Body, maxRestoreBytes)).Decode(&b); err != nil {
+		return e.BadRequestError("Invalid backup document.", err)
+	}
+
+	res, err := calendar.Restore(e.App, &b, e.Request.URL.Query().Get("dryRun") == "true", e.Auth)
+	var missing *calendar.MissingUsersError
+	switch {
+	case errors.As(err, &missing):
+		return e.BadRequestError("Invalid backup document.", validation.Errors{
+			"users": validation.NewError("validation_missing_users",
+				"Create the accounts of "+strings.Join(missing.Users, ", ")+" before restoring, users are matched by email."),
+		})
+	case errors.Is(err, calendar.ErrBackupVersion):
+		return e.BadRequestError("Invalid backup document.", validation.Errors{
+			"version": validation.NewError("validation_unsupported_version", "Only version 1 backups can be restored."),
+		})
+	case err != nil:
+		return e.InternalServerError("Failed to restore the backup.", err)
+	case len(res.Failed) > 0:
+		return e.JSON(http.StatusBadRequest, res)
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// TestRestoreIntoAnotherInstance restores the backup of one instance into a
+// fresh one whose accounts have the same emails but other ids.
+func TestRestoreIntoAnotherInstance(t *testing.T) {
+	var sub *core.Record
+	source := newFixture(t, func(app core.App, f *fixture) {
+		standup := f.event(app, "standup", map[string]any{
+			"title": "Standup", "start": at(9, 0), "end": at(9, 15), "rrule": "FREQ=DAILY;COUNT=5", "owner": f.owner.Id,
+		})
+		f.event(app, "moved", map[string]any{
+			"title": "Standup (moved)", "start": atDay(1, 11, 0), "end": atDay(1, 11, 15), "owner": f.owner.Id,
+			"sourceId": standup.Id, "recurrenceId": atDay(1, 9, 0),
+		})
+
+		sub = core.NewRecord(mustCollection(t, app, calendar.ExternalCalendarsCollection))
+		sub.Load(map[string]any{"owner": f.owner.Id, "url": "https://example.org/holidays.ics", "name": "Holidays", "lastSynced": at(0, 0)})
+		if err := app.Save(sub); err != nil {
+			t.Fatal(err)
+		}
+		f.event(app, "holiday", map[string]any{
+			"title": "Holiday", "start": atDay(2, 0, 0), "end": atDay(3, 0, 0), "allDay": true, "owner": f.owner.Id,
+			"calendar": sub.GetString("calendar"), calendar.MirrorKey: true,
+		})
+
+		attendee := core.NewRecord(mustCollection(t, app, calendar.AttendeesCollection))
+		attendee.Load(map[string]any{"event": standup.Id, "email": "other@example.com", "status": "ACCEPTED", "user": f.other.Id})
+		share := core.NewRecord(mustCollection(t, app, calendar.EventSharesCollection))
+		share.Load(map[string]any{"event": standup.Id, "user": f.other.Id, "permission": calendar.SharePermissionRead})
+		for _, rec := range []*core.Record{attendee, share} {
+			if err := app.Save(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+	app, err := tests.NewTestApp(source.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	b, err := calendar.NewBackup(app, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the detached occurrence before its series
+	slices.SortFunc(b.Events, func(x, y map[string]any) int {
+		return strings.Compare(sourceID(y), sourceID(x))
+	})
+	doc, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var admin *core.Record
+	target := newFixture(t, func(app core.App, f *fixture) {
+		admin = core.NewRecord(mustCollection(t, app, core.CollectionNameSuperusers))
+		admin.SetEmail("admin@example.com")
+		admin.SetPassword("password123")
+		if err := app.Save(admin); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// the same document with a user the target has no account for
+	var missing calendar.Backup
+	if err := json.Unmarshal(doc, &missing); err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range missing.Users {
+		if u.Email == "other@example.com" {
+			missing.Users[i].Email = "gone@example.com"
+		}
+	}
+	missingDoc, err := json.Marshal(missing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "user without an account",
+			Body:            bytes.NewReader(missingDoc),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"code":"validation_missing_users"`, `gone@example.com`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if n, _ := app.CountRecords(calendar.EventsCollection); n != 0 {
+					t.Fatalf("%d events restored, want none", n)
+				}
+			},
+		},
+		{
+			Name:            "remapped users",
+			Body:            bytes.NewReader(doc),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"created":`, `"failed":[]`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				find := func(collection, id string) *core.Record {
+					t.Helper()
+					rec, err := app.FindRecordById(collection, id)
+					if err != nil {
+						t.Fatalf("%s %s not restored: %v", collection, id, err)
+					}
+					return rec
+				}
+				standup := find(calendar.EventsCollection, source.records["standup"])
+				if standup.GetString("owner") != target.owner.Id {
+					t.Errorf("standup owned by %q, want the target owner %q", standup.GetString("owner"), target.owner.Id)
+				}
+				moved := find(calendar.EventsCollection, source.records["moved"])
+				if moved.GetString("sourceId") != standup.Id || moved.GetString("owner") != target.owner.Id {
+					t.Errorf("detached occurrence of %q owned by %q", moved.GetString("sourceId"), moved.GetString("owner"))
+				}
+
+				restored := find(calendar.ExternalCalendarsCollection, sub.Id)
+				if restored.GetString("calendar") != sub.GetString("calendar") || restored.GetString("owner") != target.owner.Id {
+					t.Errorf("subscription in calendar %q of %q, want %q", restored.GetString("calendar"), restored.GetString("owner"), sub.GetString("calendar"))
+				}
+				if n, _ := app.CountRecords(calendar.CalendarsCollection, dbx.HashExp{"external": true}); n != 1 {
+					t.Errorf("%d external calendars, want the restored one", n)
+				}
+				if holiday := find(calendar.EventsCollection, source.records["holiday"]); holiday.GetString("calendar") != sub.GetString("calendar") {
+					t.Errorf("mirrored event in calendar %q", holiday.GetString("calendar"))
+				}
+
+				shares, err := app.FindAllRecords(calendar.EventSharesCollection)
+				if err != nil || len(shares) != 1 || shares[0].GetString("user") != target.other.Id {
+					t.Errorf("shares %v (%v), want one with the target other user", shares, err)
+				}
+				attendees, err := app.FindAllRecords(calendar.AttendeesCollection)
+				if err != nil || len(attendees) != 1 || attendees[0].GetString("user") != target.other.Id {
+					t.Errorf("attendees %v (%v), want one with the target other user", attendees, err)
+				}
+			},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodPost
+		s.URL = "/api/schedule/restore.json"
+		s.TestAppFactory = target.factory
+		s.Headers = map[string]string{"Authorization": superuserToken(t, admin)}
+		s.Test(t)
+	}
+}
+
+func sourceID(event map[string]any) string {
+	id, _ := event["sourceId"].(string)
+	return id
+}
+
+func superuserToken(t testing.TB, admin *core.Record) string {
+	t.Helper()
+	token, err := admin.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
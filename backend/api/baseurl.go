@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultAppURL is the PocketBase Application URL until an operator sets it.
+const defaultAppURL = "http://localhost:8090"
+
+// baseURL returns the public origin links sent to users should point at.
+//
+// A configured Application URL always wins. While it is left at the default,
+// X-Forwarded-Proto/Host are honoured only when a trusted proxy is configured
+// (Settings > Trusted proxy), otherwise the request host is used.
+func baseURL(e *core.RequestEvent) string {
+	settings := e.App.Settings()
+	if u := configuredAppURL(settings); u != "" {
+		return u
+	}
+
+	scheme, host := "http", e.Request.Host
+	if e.Request.TLS != nil {
+		scheme = "https"
+	}
+	if len(settings.TrustedProxy.Headers) > 0 {
+		if v := lastHeader(e, "X-Forwarded-Proto"); v != "" {
+			scheme = v
+		}
+		if v := lastHeader(e, "X-Forwarded-Host"); v != "" {
+			host = v
+		}
+	}
+	return scheme + "://" + host
+}
+
+// trustedBaseURL is baseURL, and whether it can be trusted with secrets such
+// as reset tokens: it is the configured Application URL, or comes from behind
+// a trusted proxy. A bare request host is whatever the client sent.
+func trustedBaseURL(e *core.RequestEvent) (string, bool) {
+	settings := e.App.Settings()
+	return baseURL(e), configuredAppURL(settings) != "" || len(settings.TrustedProxy.Headers) > 0
+}
+
+// configuredAppURL is the Application URL without its trailing slash, empty
+// while it is left at the default.
+func configuredAppURL(settings *core.Settings) string {
+	if u := strings.TrimRight(settings.Meta.AppURL, "/"); u != defaultAppURL {
+		return u
+	}
+	return ""
+}
+
+// lastHeader returns the last comma separated value of a header, the one
+// appended by the closest proxy.
+func lastHeader(e *core.RequestEvent, name string) string {
+	v := e.Request.Header.Get(name)
+	if i := strings.LastIndex(v, ","); i >= 0 {
+		v = v[i+1:]
+	}
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxBlockDays caps how many days one /block-day request can block.
+const maxBlockDays = 366
+
+// blockDayTitle is the title of blocked days created without one.
+const blockDayTitle = "Out of office"
+
+// blockDay handles POST /api/schedule/block-day.
+//
+// Body: {"date": "YYYY-MM-DD", "to"?, "title"?, "timezone"?, "perDay"?}.
+// Creates an all-day event blocking date, or the days from date to to
+// (inclusive): one multi-day event, or with perDay one event per day. Days
+// are taken in timezone (else ?timezone), which the events keep. Returns
+// {created, events}; nothing is created when one of them fails.
+func (h *handlers) blockDay(e *core.RequestEvent) error {
+	var body struct {
+		Date     string `json:"date"`
+		To       string `json:"to"`
+		Title    string `json:"title"`
+		Timezone string `json:"timezone"`
+		PerDay   bool   `json:"perDay"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	loc, err := h.location(e)
+	if body.Timezone != "" {
+		loc, err = time.LoadLocation(body.Timezone)
+	}
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", validation.Errors{
+			"timezone": validation.NewError("validation_invalid_timezone", "Unknown time zone."),
+		})
+	}
+
+	errs := validation.Errors{}
+	day := func(field, v string) time.Time {
+		t, err := time.ParseInLocation(time.DateOnly, v, loc)
+		if err != nil {
+			errs[field] = validation.NewError("validation_invalid_date", "Must be a YYYY-MM-DD date.")
+		}
+		return t
+	}
+	first := day("date", body.Date)
+	last := first
+	if body.To != "" {
+		last = day("to", body.To)
+	}
+	days := 0
+	if len(errs) == 0 {
+		days = int(last.Sub(first).Round(24*time.Hour)/(24*time.Hour)) + 1
+		switch {
+		case days < 1:
+			errs["to"] = validation.NewError("validation_range_order", "Must not be before date.")
+		case days > maxBlockDays:
+			errs["to"] = validation.NewError("validation_range_too_long", "At most "+strconv.Itoa(maxBlockDays)+" days can be blocked at once.")
+		}
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid days.", errs)
+	}
+
+	title := body.Title
+	if title == "" {
+		title = blockDayTitle
+	}
+
+	// start and length in days of every event to create
+	type block struct {
+		start time.Time
+		days  int
+	}
+	blocks := []block{{first, days}}
+	if body.PerDay {
+		blocks = make([]block, days)
+		for i := range blocks {
+			blocks[i] = block{first.AddDate(0, 0, i), 1}
+		}
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		return e.InternalServerError("Failed to load the events collection.", err)
+	}
+
+	created := make([]*core.Record, 0, len(blocks))
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		for _, b := range blocks {
+			rec := core.NewRecord(collection)
+			rec.Set("owner", e.Auth.Id)
+			rec.Set("title", title)
+			rec.Set("allDay", true)
+			rec.Set("start", b.start)
+			rec.Set("end", b.start.AddDate(0, 0, b.days))
+			rec.Set("multiDay", b.days > 1)
+			rec.Set("timezone", loc.String())
+			calendar.SetChangedBy(rec, e.Auth)
+			if err := txApp.Save(rec); err != nil {
+				return err
+			}
+			created = append(created, rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to block the days.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"created": len(created),
+		"events":  created,
+	})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"schedule/calendar"
+	"schedule/recur"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// maxBulkItems and maxBulkBytes cap the size of one /events/bulk-import
+// request.
+const (
+	maxBulkItems = 1000
+	maxBulkBytes = 8 << 20
+)
+
+// errBulkFailed rolls back an atomic bulk import.
+var errBulkFailed = errors.New("bulk import failed")
+
+// bulkItem is the outcome of one /events/bulk-import entry: the created id, or
+// the error with its field errors when there are any.
+type bulkItem struct {
+	Index int            `json:"index"`
+	ID    string         `json:"id,omitempty"`
+	Error string         `json:"error,omitempty"`
+	Data  map[string]any `json:"data,omitempty"`
+}
+
+// bulkImport handles POST /api/schedule/events/bulk-import.
+//
+// Body: {"atomic": bool, "items": [<events record fields>...]}, or the bare
+// array with ?atomic=true|false. Every item is
+// validated like a records API create (plus a parsable rrule) and saved in one
+// transaction. With atomic any failure rolls back the whole batch (400);
+// otherwise the valid items are kept. The response lists each item's id or
+// error. App users always own what they import.
+func (h *handlers) bulkImport(e *core.RequestEvent) error {
+	var body struct {
+		Atomic bool             `json:"atomic"`
+		Items  []map[string]any `json:"items"`
+	}
+	raw, err := io.ReadAll(io.LimitReader(e.Request.Body, maxBulkBytes))
+	if err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+		body.Atomic = e.Request.URL.Query().Get("atomic") == "true"
+		err = json.Unmarshal(raw, &body.Items)
+	} else {
+		err = json.Unmarshal(raw, &body)
+	}
+	if err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	if len(body.Items) == 0 || len(body.Items) > maxBulkItems {
+		return e.BadRequestError(fmt.Sprintf("items must hold 1 to %d events.", maxBulkItems), nil)
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		return e.InternalServerError("Failed to load the events collection.", err)
+	}
+
+	results := make([]bulkItem, len(body.Items))
+	created, failed := 0, 0
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		for i, item := range body.Items {
+			results[i] = bulkItem{Index: i}
+
+			rec := core.NewRecord(collection)
+			rec.Load(item)
+			if !e.HasSuperuserAuth() {
+				rec.Set("owner", e.Auth.Id)
+			}
+			calendar.SetChangedBy(rec, e.Auth)
+
+			err := validateRRule(rec)
+			if err == nil {
+				err = txApp.Save(rec)
+			}
+			if err != nil {
+				// same {"<field>": {code, message}} shape as the records API
+				apiErr := router.NewBadRequestError("Failed to create the event.", err)
+				results[i].Error, results[i].Data = apiErr.Message, apiErr.Data
+				failed++
+				continue
+			}
+			results[i].ID = rec.Id
+			created++
+		}
+		if body.Atomic && failed > 0 {
+			return errBulkFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkFailed) {
+		return e.InternalServerError("Failed to import the events.", err)
+	}
+
+	res := map[string]any{
+		"atomic":  body.Atomic,
+		"created": created,
+		"failed":  failed,
+		"items":   results,
+	}
+	if errors.Is(err, errBulkFailed) {
+		for i := range results {
+			results[i].ID = "" // rolled back
+		}
+		res["created"] = 0
+		return e.JSON(http.StatusBadRequest, res)
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+// validateRRule rejects an rrule the expansion couldn't parse.
+func validateRRule(rec *core.Record) error {
+	rule := rec.GetString("rrule")
+	if rule == "" {
+		return nil
+	}
+	if _, err := recur.Parse(rule); err != nil {
+		return validation.Errors{"rrule": validation.NewError("validation_invalid_rrule", "Invalid recurrence rule.")}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// busyLookahead bounds how far /busy-now follows back-to-back events to find
+// when the caller is free again.
+const busyLookahead = 24 * time.Hour
+
+// busyNow handles GET /api/schedule/busy-now?timezone=.
+//
+// For status integrations: whether a timed occurrence is in progress now,
+// the one started last as event, and until, the end of the busy stretch
+// (overlapping and back-to-back occurrences included, up to a day ahead).
+// All-day events don't make anyone busy.
+func (h *handlers) busyNow(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	now := time.Now()
+	v, err := h.loadView(e, now, now.Add(busyLookahead))
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	var current *calendar.Occurrence
+	var until time.Time
+	err = v.stream(now, now.Add(busyLookahead), loc, func(o calendar.Occurrence) error {
+		if o.AllDay || !o.End.After(o.Start) {
+			return nil
+		}
+		switch {
+		case !o.Start.After(now):
+			current = &o // occurrences come by start, so the last one wins
+		case current == nil || o.Start.After(until):
+			return errFound
+		}
+		if o.End.After(until) {
+			until = o.End
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	res := map[string]any{
+		"at":    now.UTC(),
+		"busy":  current != nil,
+		"until": nil,
+		"event": current,
+	}
+	if current != nil {
+		res["until"] = until.UTC()
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// mergeCategory handles POST /api/schedule/categories/{id}/merge-into/{targetId}.
+//
+// Superusers only. Moves the events of category id to targetId and deletes
+// id (see calendar.MergeCategory). The target's color and default reminders
+// are kept; the source's are dropped with it. The configured default
+// category can't be the source.
+func (h *handlers) mergeCategory(e *core.RequestEvent) error {
+	source, err := e.App.FindRecordById(calendar.CategoriesCollection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("Category not found.", err)
+	}
+	target, err := e.App.FindRecordById(calendar.CategoriesCollection, e.Request.PathValue("targetId"))
+	if err != nil {
+		return e.NotFoundError("Target category not found.", err)
+	}
+
+	// new events would keep getting the deleted name
+	if h.cfg.DefaultCategory != "" && source.GetString("name") == h.cfg.DefaultCategory {
+		return e.BadRequestError("The default category cannot be merged away, change SCHEDULE_DEFAULT_CATEGORY first.", nil)
+	}
+
+	moved, err := calendar.MergeCategory(e.App, source, target, e.Auth)
+	if errors.Is(err, calendar.ErrMergeIntoSelf) {
+		return e.BadRequestError("A category cannot be merged into itself.", nil)
+	}
+	if err != nil {
+		return e.BadRequestError("Failed to merge the categories.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"source":     source.GetString("name"),
+		"target":     target.GetString("name"),
+		"reassigned": moved,
+	})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestMergeCategory(t *testing.T) {
+	var admin *core.Record
+	f := newFixture(t, func(app core.App, f *fixture) {
+		admin = core.NewRecord(mustCollection(t, app, core.CollectionNameSuperusers))
+		admin.SetEmail("admin@example.com")
+		admin.SetPassword("password123")
+		if err := app.Save(admin); err != nil {
+			t.Fatal(err)
+		}
+		f.event(app, "lecture", map[string]any{"title": "Lecture", "start": at(9, 0), "end": at(10, 0), "category": "College", "owner": f.owner.Id})
+	})
+	f.cfg.DefaultCategory = "College"
+
+	ids := map[string]string{}
+	app, err := tests.NewTestApp(f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"College", "Personal", "Other"} {
+		rec, err := app.FindFirstRecordByData(calendar.CategoriesCollection, "name", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[name] = rec.Id
+	}
+	app.Cleanup()
+
+	merge := func(source, target string) string {
+		return "/api/schedule/categories/" + ids[source] + "/merge-into/" + ids[target]
+	}
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "default category",
+			URL:             merge("College", "Other"),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"message":"The default category cannot be merged away`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindRecordById(calendar.CategoriesCollection, ids["College"]); err != nil {
+					t.Fatalf("default category deleted: %v", err)
+				}
+			},
+		},
+		{
+			Name:            "into the default category",
+			URL:             merge("Personal", "College"),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"source":"Personal"`, `"target":"College"`, `"reassigned":0`},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodPost
+		s.TestAppFactory = f.factory
+		s.Headers = map[string]string{"Authorization": superuserToken(t, admin)}
+		s.Test(t)
+	}
+}
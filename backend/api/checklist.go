@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// checklistAdd handles POST /api/schedule/events/{id}/checklist/add.
+//
+// Body: {"text": "..."}. Appends an open item to the event's checklist. App
+// users can only change their own events.
+func (h *handlers) checklistAdd(e *core.RequestEvent) error {
+	var body struct {
+		Text string `json:"text"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	return h.updateChecklist(e, func(items []calendar.ChecklistItem) ([]calendar.ChecklistItem, error) {
+		text := strings.TrimSpace(body.Text)
+		if text == "" {
+			return nil, errors.New("text is required")
+		}
+		return append(items, calendar.ChecklistItem{Text: text}), nil
+	})
+}
+
+// checklistToggle handles POST /api/schedule/events/{id}/checklist/toggle.
+//
+// Body: {"index": n, "done": bool}. Flips the done flag of item n (0-based),
+// or sets it when done is given. App users can only change their own events.
+func (h *handlers) checklistToggle(e *core.RequestEvent) error {
+	var body struct {
+		Index *int  `json:"index"`
+		Done  *bool `json:"done"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	return h.updateChecklist(e, func(items []calendar.ChecklistItem) ([]calendar.ChecklistItem, error) {
+		if body.Index == nil || *body.Index < 0 || *body.Index >= len(items) {
+			return nil, errors.New("index must point to an item")
+		}
+		it := &items[*body.Index]
+		if body.Done != nil {
+			it.Done = *body.Done
+		} else {
+			it.Done = !it.Done
+		}
+		return items, nil
+	})
+}
+
+// updateChecklist replaces the checklist of the event {id} with the one
+// change derives from the current one. As in updatePauses, the event is
+// re-read inside the transaction so concurrent changes don't drop each
+// other's items. It responds with the stored checklist.
+func (h *handlers) updateChecklist(e *core.RequestEvent, change func([]calendar.ChecklistItem) ([]calendar.ChecklistItem, error)) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	var items []calendar.ChecklistItem
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		rec, err = txApp.FindRecordById(calendar.EventsCollection, rec.Id)
+		if err != nil {
+			return err
+		}
+		items = []calendar.ChecklistItem{}
+		_ = rec.UnmarshalJSONField("checklist", &items)
+		if items, err = change(items); err != nil {
+			return err
+		}
+		if err := calendar.ValidateChecklist(items); err != nil {
+			return err
+		}
+		rec.Set("checklist", items)
+		calendar.SetChangedBy(rec, e.Auth)
+		return txApp.Save(rec)
+	})
+	if err != nil {
+		return e.BadRequestError("Invalid checklist change: "+err.Error()+".", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":     rec.Id,
+		"checklist": items,
+	})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// cloneSkipFields are the events fields a cloned series doesn't take from its
+// source: identity, sync and dependency bookkeeping of the original.
+var cloneSkipFields = []string{"id", "created", "updated", "uid", "importHash", "sourceId", "recurrenceId", "dependsOn"}
+
+// cloneSeries handles POST /api/schedule/events/{id}/clone-series.
+//
+// Body (all optional): {"start": "...", "category": "...", "color": "..."}.
+// Creates a new series with the fields of the given one (rule, exdates,
+// pauses, reminders, checklist, ...), owned by the same user, starting at
+// start (in the event timezone, else ?timezone). The exdates move along with
+// the start like in /reanchor, except for those of instances replaced by
+// detached occurrences, so the clone has the regular instance there, and
+// those outside the series. Detached occurrences and attendees aren't
+// copied, and there are no rdates to copy (RDATE isn't supported). Returns
+// {id, event}.
+// App users can only clone their own events.
+func (h *handlers) cloneSeries(e *core.RequestEvent) error {
+	src, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && src.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var body struct {
+		Start    string  `json:"start"`
+		Category *string `json:"category"`
+		Color    *string `json:"color"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	ev := calendar.EventFromRecord(src)
+	if !ev.IsRecurring() {
+		return e.BadRequestError("The event is not a recurring series.", nil)
+	}
+	start := ev.Start
+	if body.Start != "" {
+		if start, err = eventTime(ev, body.Start, ev.Zone(loc)); err != nil {
+			return e.BadRequestError("Invalid start.", err)
+		}
+	}
+	detached, err := calendar.FindDetached(e.App, ev.ID)
+	if err != nil {
+		return e.InternalServerError("Failed to load detached occurrences.", err)
+	}
+	moved, err := calendar.Reanchor(ev, detached, start, false, loc)
+	if err != nil {
+		return e.BadRequestError("Invalid start: "+err.Error()+".", err)
+	}
+
+	// exdates left past the end of a shortened rule would be refused
+	clone := *ev
+	clone.Start, clone.End, clone.Exdates = moved.Start, moved.End, moved.Exdates
+	drop := append(slices.Collect(maps.Values(moved.RecurrenceIDs)), calendar.ExdatesOutside(&clone, loc)...)
+	exdates := []string{}
+	for _, x := range moved.Exdates {
+		if !slices.ContainsFunc(drop, x.Equal) {
+			exdates = append(exdates, calendar.ISO(x))
+		}
+	}
+
+	rec := core.NewRecord(src.Collection())
+	for name, value := range src.FieldsData() {
+		if !slices.Contains(cloneSkipFields, name) {
+			rec.Set(name, value)
+		}
+	}
+	rec.Set("start", moved.Start)
+	rec.Set("end", moved.End)
+	rec.Set("exdates", exdates)
+	if body.Category != nil {
+		rec.Set("category", *body.Category)
+	}
+	if body.Color != nil {
+		rec.Set("color", *body.Color)
+	}
+	calendar.SetChangedBy(rec, e.Auth)
+
+	if err := e.App.Save(rec); err != nil {
+		return e.BadRequestError("Failed to clone the series.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"id":    rec.Id,
+		"event": rec,
+	})
+}
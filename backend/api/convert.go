@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxConvertItems caps the size of one /convert-tz request.
+const maxConvertItems = 1000
+
+// wallClockLayouts are the offset-less date-times /convert-tz reads as wall
+// clock times of the source zone.
+var wallClockLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+type convertSpan struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// convertTZ handles POST /api/schedule/convert-tz.
+//
+// Body: {"from": "<zone>", "to": "<zone>", "items": [{"start", "end"}]}. Values
+// with an offset are instants; offset-less ones (and dates) are wall clock
+// times in from. Each span comes back as RFC 3339 in to, using the same zone
+// database as the rest of the API (a wall time skipped by DST moves forward,
+// as in time.Date).
+func (h *handlers) convertTZ(e *core.RequestEvent) error {
+	var body struct {
+		From  string        `json:"from"`
+		To    string        `json:"to"`
+		Items []convertSpan `json:"items"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	errs := validation.Errors{}
+	zone := func(field, name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil || name == "" {
+			errs[field] = validation.NewError("validation_invalid_timezone", "Unknown time zone.")
+		}
+		return loc
+	}
+	from, to := zone("from", body.From), zone("to", body.To)
+	if len(body.Items) > maxConvertItems {
+		errs["items"] = validation.NewError("validation_too_many_items", "At most 1000 items per request.")
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid conversion.", errs)
+	}
+
+	out := make([]convertSpan, len(body.Items))
+	for i, it := range body.Items {
+		convert := func(field, v string) string {
+			t, err := parseWallClock(v, from)
+			if err != nil {
+				errs[fmt.Sprintf("items.%d.%s", i, field)] = validation.NewError("validation_invalid_date", "Invalid date.")
+				return ""
+			}
+			return t.In(to).Format(time.RFC3339)
+		}
+		out[i] = convertSpan{Start: convert("start", it.Start), End: convert("end", it.End)}
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid conversion.", errs)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":  from.String(),
+		"to":    to.String(),
+		"items": out,
+	})
+}
+
+// parseWallClock parses s like calendar.ParseTime, additionally reading
+// offset-less date-times as wall clock times in loc.
+func parseWallClock(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range wallClockLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return calendar.ParseTime(s, loc)
+}
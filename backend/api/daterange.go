@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// dateRange parses the startParam/endParam query pair every range route
+// takes: RFC 3339 or date-only values (midnight in loc), start <= end and a
+// span of at most the configured horizon.
+//
+// The returned error is already the 400 response, with the offending
+// parameters as validation errors under "data", so every route reports bad
+// ranges the same way.
+func (h *handlers) dateRange(e *core.RequestEvent, startParam, endParam string, loc *time.Location) (time.Time, time.Time, error) {
+	q := e.Request.URL.Query()
+	errs := validation.Errors{}
+
+	parse := func(param string) time.Time {
+		v := q.Get(param)
+		if v == "" {
+			errs[param] = validation.NewError("validation_required", "Missing "+param+".")
+			return time.Time{}
+		}
+		t, err := calendar.ParseTime(v, loc)
+		if err != nil {
+			errs[param] = validation.NewError("validation_invalid_date", "Must be an RFC 3339 date-time or a YYYY-MM-DD date.")
+		}
+		return t
+	}
+	from, to := parse(startParam), parse(endParam)
+
+	if len(errs) == 0 {
+		switch {
+		case to.Before(from):
+			errs[endParam] = validation.NewError("validation_range_order", fmt.Sprintf("Must not be before %s.", startParam))
+		case to.Sub(from) > h.cfg.Horizon:
+			errs[endParam] = validation.NewError("validation_range_too_long", fmt.Sprintf("The range can span at most %d days.", int(h.cfg.Horizon.Hours()/24)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return time.Time{}, time.Time{}, e.BadRequestError("Invalid date range.", errs)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// moveEvent handles POST /api/schedule/events/{id}/move.
+//
+// Body: {"start": "..."}. Moves the event to start, keeping its duration, and
+// pushes the events depending on it (dependsOn) that would start before its
+// new end back to that end, down the chain, whatever CascadeDependencies
+// says. Everything is saved in one transaction. Returns {event, shifted} with
+// the ids of the moved dependents. App users can only move their own events.
+func (h *handlers) moveEvent(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var body struct {
+		Start string `json:"start"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	ev := calendar.EventFromRecord(rec)
+	start, err := eventTime(ev, body.Start, ev.Zone(loc))
+	if err != nil {
+		return e.BadRequestError("Invalid start.", err)
+	}
+
+	shifted := []string{}
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		rec, err = txApp.FindRecordById(calendar.EventsCollection, rec.Id)
+		if err != nil {
+			return err
+		}
+		ev := calendar.EventFromRecord(rec)
+		end := start.Add(ev.End.Sub(ev.Start))
+
+		moved, err := calendar.ShiftDependents(txApp, rec.Id, end, e.Auth)
+		if err != nil {
+			return err
+		}
+		shifted = append(shifted, moved...)
+
+		rec.Set("start", start)
+		rec.Set("end", end)
+		calendar.SetChangedBy(rec, e.Auth)
+		return txApp.Save(rec)
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to move the event.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":   rec,
+		"shifted": shifted,
+	})
+}
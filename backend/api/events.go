@@ -0,0 +1,222 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// eventETag derives a strong ETag from the record id and its updated
+// timestamp, plus the remaining occurrences of a bounded series, which change
+// without the record changing.
+func eventETag(rec *core.Record) string {
+	key := rec.Id + "|" + rec.GetDateTime("updated").String()
+	if n, ok := rec.Get("remainingOccurrences").(int); ok {
+		key += "|" + strconv.Itoa(n)
+	}
+	sum := sha1.Sum([]byte(key))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// eventView handles GET and HEAD /api/schedule/events/{id}.
+//
+// Both carry the event ETag (and answer 304 when it matches If-None-Match).
+// HEAD is the cheap existence check used by sync reconciliation: net/http
+// drops the body, and a missing event is a bare 404.
+//
+// A series with COUNT or UNTIL also carries remainingOccurrences, the number
+// of its occurrences starting from now (see calendar.Remaining); timezone is
+// the fallback zone for the count.
+func (h *handlers) eventView(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err == nil {
+		err = canView(e, rec)
+	}
+	if err != nil {
+		if e.Request.Method == http.MethodHead {
+			return e.NoContent(http.StatusNotFound)
+		}
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	if ev := calendar.EventFromRecord(rec); ev.IsRecurring() {
+		loc, err := h.location(e)
+		if err != nil {
+			return e.BadRequestError("Invalid timezone.", err)
+		}
+		detached, err := calendar.FindDetached(e.App, ev.ID)
+		if err != nil {
+			return e.InternalServerError("Failed to load the detached occurrences.", err)
+		}
+		if n, ok := calendar.Remaining(ev, detached, time.Now(), loc); ok {
+			rec.WithCustomData(true).Set("remainingOccurrences", n)
+		}
+	}
+
+	etag := eventETag(rec)
+	e.Response.Header().Set("ETag", etag)
+
+	if e.Request.Header.Get("If-None-Match") == etag {
+		return e.NoContent(http.StatusNotModified)
+	}
+	return e.JSON(http.StatusOK, rec)
+}
+
+// canView applies the collection ViewRule of rec to the request, as the
+// records API view does.
+func canView(e *core.RequestEvent, rec *core.Record) error {
+	info, err := e.RequestInfo()
+	if err != nil {
+		return err
+	}
+	ok, err := e.App.CanAccessRecord(rec, info, rec.Collection().ViewRule)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("the ViewRule rejects the request")
+	}
+	return nil
+}
+
+// eventCount handles GET /api/schedule/events/count?filter=.
+//
+// The filter uses the regular PocketBase filter syntax and the response is
+// just {"count": n}. Only the events the ListRule lets the caller list are
+// counted, and the filter can't reach hidden fields.
+func (h *handlers) eventCount(e *core.RequestEvent) error {
+	collection, err := e.App.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		return e.InternalServerError("Failed to load the events collection.", err)
+	}
+
+	info, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("Invalid request.", err)
+	}
+
+	if !e.HasSuperuserAuth() && collection.ListRule == nil {
+		return e.ForbiddenError("Only superusers can count events.", nil)
+	}
+
+	resolver := core.NewRecordFieldResolver(e.App, collection, info, false)
+	query := e.App.RecordQuery(collection).Select("COUNT(DISTINCT [[" + collection.Name + ".id]])")
+
+	if !e.HasSuperuserAuth() && *collection.ListRule != "" {
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(resolver)
+		if err != nil {
+			return e.InternalServerError("Invalid list rule.", err)
+		}
+		query.AndWhere(expr)
+	}
+
+	if filter := e.Request.URL.Query().Get("filter"); filter != "" {
+		expr, err := search.FilterData(filter).BuildExpr(resolver)
+		if err != nil {
+			return e.BadRequestError("Invalid filter.", err)
+		}
+		query.AndWhere(expr)
+	}
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return e.BadRequestError("Invalid filter.", err)
+	}
+
+	// the resolver may have turned the query into a DISTINCT join; the count
+	// expression already accounts for that
+	query.Distinct(false)
+
+	var count int
+	if err := query.Row(&count); err != nil {
+		return e.InternalServerError("Failed to count events.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]int{"count": count})
+}
+
+// eventHistory handles GET /api/schedule/events/{id}/history.
+//
+// Returns the change log of one event, newest first: each entry has the
+// action, the changed fields with their before/after values and the actor.
+// ?from and ?to limit the entries to a creation window, ?actor to the changes
+// of one auth record; ?page and ?perPage paginate as in /agenda. App users
+// can only read the history of their own events.
+func (h *handlers) eventHistory(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	q := e.Request.URL.Query()
+	filter := dbx.And(dbx.HashExp{"event": rec.Id})
+	errs := validation.Errors{}
+	for _, bound := range []struct{ param, op string }{{"from", ">="}, {"to", "<"}} {
+		v := q.Get(bound.param)
+		if v == "" {
+			continue
+		}
+		t, err := calendar.ParseTime(v, loc)
+		if err != nil {
+			errs[bound.param] = validation.NewError("validation_invalid_date", "Must be an RFC 3339 date-time or a YYYY-MM-DD date.")
+			continue
+		}
+		filter = dbx.And(filter, dbx.NewExp("[[created]] "+bound.op+" {:"+bound.param+"}", dbx.Params{bound.param: t.UTC().Format(types.DefaultDateLayout)}))
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid parameters.", errs)
+	}
+	if actor := q.Get("actor"); actor != "" {
+		filter = dbx.And(filter, dbx.HashExp{"actor": actor})
+	}
+
+	page, perPage := pagination(q)
+
+	total, err := e.App.CountRecords(calendar.EventChangesCollection, filter)
+	if err != nil {
+		return e.InternalServerError("Failed to load the event history.", err)
+	}
+	var changes []*core.Record
+	err = e.App.RecordQuery(calendar.EventChangesCollection).
+		AndWhere(filter).
+		OrderBy("created DESC", "id DESC").
+		Offset(int64((page - 1) * perPage)).
+		Limit(int64(perPage)).
+		All(&changes)
+	if err != nil {
+		return e.InternalServerError("Failed to load the event history.", err)
+	}
+
+	items := make([]map[string]any, len(changes))
+	for i, c := range changes {
+		items[i] = map[string]any{
+			"id":              c.Id,
+			"action":          c.GetString("action"),
+			"changes":         c.Get("changes"),
+			"actor":           c.GetString("actor"),
+			"actorCollection": c.GetString("actorCollection"),
+			"created":         c.GetDateTime("created"),
+		}
+	}
+	return e.JSON(http.StatusOK, map[string]any{
+		"page":       page,
+		"perPage":    perPage,
+		"totalItems": total,
+		"items":      items,
+	})
+}
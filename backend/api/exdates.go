@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// deleteOccurrence handles POST /api/schedule/events/{id}/delete-occurrence.
+//
+// Body: {"start": "<occurrence start>"}. Excludes one instance of a series by
+// adding an exdate. The start is snapped to the nearest computed occurrence
+// (within calendar.ExdateTolerance, in ?timezone for events without one), so
+// a value that is off by an offset or a few seconds still excludes the
+// intended instance. Concurrent calls for other instances don't overwrite each
+// other's exdates. App users can only change their own events.
+func (h *handlers) deleteOccurrence(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	ev := calendar.EventFromRecord(rec)
+	if !ev.IsRecurring() {
+		return e.BadRequestError("Only recurring events have occurrences to delete.", nil)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var body struct {
+		Start string `json:"start"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	start, err := calendar.ParseTime(body.Start, time.UTC)
+	if err != nil {
+		return e.BadRequestError("Invalid start.", err)
+	}
+
+	snapped, ok := calendar.SnapExdate(ev, start, loc)
+	if !ok {
+		return e.BadRequestError("No occurrence of the series near start.", nil)
+	}
+
+	// re-read and append inside a write transaction: writes are serialized,
+	// so concurrent deletions of other instances all keep their exdate
+	exdate := calendar.ISO(snapped)
+	var exdates []string
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		rec, err := txApp.FindRecordById(calendar.EventsCollection, rec.Id)
+		if err != nil {
+			return err
+		}
+		_ = rec.UnmarshalJSONField("exdates", &exdates)
+		if slices.ContainsFunc(calendar.EventFromRecord(rec).Exdates, snapped.Equal) {
+			return nil
+		}
+		exdates = append(exdates, exdate)
+		rec.Set("exdates", exdates)
+		calendar.SetChangedBy(rec, e.Auth)
+		return txApp.Save(rec)
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to delete the occurrence.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"exdate":  exdate,
+		"snapped": !snapped.Equal(start),
+		"exdates": exdates,
+	})
+}
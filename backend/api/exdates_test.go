@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestDeleteOccurrenceSnapsToTheInstance(t *testing.T) {
+	var admin *core.Record
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.event(app, "standup", map[string]any{
+			"title": "Standup", "start": at(9, 0), "end": at(9, 15), "rrule": "FREQ=DAILY;COUNT=5", "owner": f.owner.Id,
+			"exdates": []string{atDay(4, 9, 30)}, // off by half an hour, excludes nothing
+		})
+		admin = core.NewRecord(mustCollection(t, app, core.CollectionNameSuperusers))
+		admin.SetEmail("admin@example.com")
+		admin.SetPassword("password123")
+		if err := app.Save(admin); err != nil {
+			t.Fatal(err)
+		}
+	})
+	url := func(name string) string {
+		return "/api/schedule/events/" + f.records[name] + "/delete-occurrence?timezone=UTC"
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "an hour off",
+			Method:          http.MethodPost,
+			URL:             url("standup"),
+			Body:            strings.NewReader(`{"start":"` + atDay(1, 10, 0) + `"}`),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"exdate":"` + atDay(1, 9, 0) + `"`, `"snapped":true`},
+		},
+		{
+			Name:            "exact",
+			Method:          http.MethodPost,
+			URL:             url("standup"),
+			Body:            strings.NewReader(`{"start":"` + atDay(2, 9, 0) + `"}`),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"exdate":"` + atDay(2, 9, 0) + `"`, `"snapped":false`},
+		},
+		{
+			Name:            "no instance near",
+			Method:          http.MethodPost,
+			URL:             url("standup"),
+			Body:            strings.NewReader(`{"start":"` + atDay(20, 9, 0) + `"}`),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"message":"No occurrence of the series near start."`},
+		},
+		{
+			Name:            "another user's event",
+			Method:          http.MethodPost,
+			URL:             url("standup"),
+			Body:            strings.NewReader(`{"start":"` + atDay(1, 9, 0) + `"}`),
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"message":"Event not found."`},
+		},
+		{
+			Name:            "the audit reports the stray exdate",
+			Method:          http.MethodGet,
+			URL:             "/api/schedule/maintenance/validate",
+			Headers:         f.auth(admin),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"unmatchedExdates":["` + f.records["standup"] + `"]`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
+
+func mustCollection(t testing.TB, app core.App, name string) *core.Collection {
+	t.Helper()
+	c, err := app.FindCollectionByNameOrId(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// serveMux binds the routes of app like a served app, for tests sending
+// several requests to the same data.
+func serveMux(t testing.TB, app *tests.TestApp) http.Handler {
+	t.Helper()
+	router, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mux http.Handler
+	err = app.OnServe().Trigger(&core.ServeEvent{App: app, Router: router}, func(e *core.ServeEvent) error {
+		m, err := e.Router.BuildMux()
+		mux = m
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mux
+}
+
+func TestDeleteOccurrenceAppends(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.event(app, "standup", map[string]any{
+			"title": "Standup", "start": at(9, 0), "end": at(9, 15), "rrule": "FREQ=DAILY;COUNT=20", "owner": f.owner.Id,
+		})
+	})
+	app := f.factory(t)
+	defer app.Cleanup()
+	mux := serveMux(t, app)
+	token := f.auth(f.owner)["Authorization"]
+	deleteAt := func(start string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/schedule/events/"+f.records["standup"]+"/delete-occurrence?timezone=UTC", strings.NewReader(`{"start":"`+start+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	exdates := func() []time.Time {
+		rec, err := app.FindRecordById(calendar.EventsCollection, f.records["standup"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return calendar.EventFromRecord(rec).Exdates
+	}
+
+	// the same instance twice, the second time a few seconds off
+	if code := deleteAt(atDay(1, 9, 0)); code != http.StatusOK {
+		t.Fatalf("first delete: %d", code)
+	}
+	if code := deleteAt(calendar.ISO(fixtureDay.AddDate(0, 0, 1).Add(9*time.Hour + 3*time.Second))); code != http.StatusOK {
+		t.Fatalf("second delete: %d", code)
+	}
+	if got := exdates(); len(got) != 1 {
+		t.Fatalf("exdates after deleting one instance twice = %v, want a single entry", got)
+	}
+
+	// other instances deleted at the same time all keep their exdate
+	var wg sync.WaitGroup
+	for day := 2; day < 12; day++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if code := deleteAt(atDay(day, 9, 0)); code != http.StatusOK {
+				t.Errorf("delete of day %d: %d", day, code)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := exdates(); len(got) != 11 {
+		t.Fatalf("%d exdates after concurrent deletes, want 11: %v", len(got), got)
+	}
+}
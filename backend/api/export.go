@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// categoryExport handles GET /api/schedule/export/{category}.ics.
+//
+// Public route like /ics, so calendar apps can subscribe: a ?token=
+// subscription token limits the feed to its user's events. Authenticated
+// requests work as well; superusers get every event of the category.
+func (h *handlers) categoryExport(e *core.RequestEvent) error {
+	name, ok := strings.CutSuffix(e.Request.PathValue("file"), ".ics")
+	if !ok || name == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	category, err := e.App.FindFirstRecordByData(calendar.CategoriesCollection, "name", name)
+	if err != nil {
+		return e.NotFoundError("Unknown category.", err)
+	}
+
+	owner, err := feedOwner(e)
+	if err != nil {
+		return err
+	}
+
+	events, err := calendar.FindCategoryEvents(e.App, category.GetString("name"), owner)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	return h.writeICS(e, owner, calendar.InCalendars(events, h.calendarIDs(e)), category.GetString("name"))
+}
+
+// feedOwner resolves whose events a feed request may see: the user of a
+// ?token= subscription token or the authenticated user. Superusers see all
+// events ("").
+func feedOwner(e *core.RequestEvent) (string, error) {
+	if token := e.Request.URL.Query().Get("token"); token != "" {
+		user, err := userFromICSToken(e.App, token)
+		if err != nil {
+			return "", e.UnauthorizedError("Invalid or revoked subscription token.", nil)
+		}
+		return user.Id, nil
+	}
+
+	switch {
+	case e.Auth == nil:
+		return "", e.UnauthorizedError("The request requires a subscription token or valid authorization.", nil)
+	case e.Auth.IsSuperuser():
+		return "", nil
+	default:
+		return e.Auth.Id, nil
+	}
+}
+
+// rangeExport handles GET /api/schedule/export.ics?start=&end=.
+//
+// Public like /ics, with the same ?token= or auth as /export/{category}.ics.
+// Without a range every series is exported as its RRULE master; with start
+// and end (dates or date-times in ?timezone) the occurrences in the range
+// are exported as single VEVENTs (see calendar.ExportOccurrencesICS).
+func (h *handlers) rangeExport(e *core.RequestEvent) error {
+	owner, err := feedOwner(e)
+	if err != nil {
+		return err
+	}
+
+	events, err := calendar.FindOwnedEvents(e.App, owner)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+	events = calendar.InCalendars(events, h.calendarIDs(e))
+
+	q := e.Request.URL.Query()
+	if q.Get("start") == "" && q.Get("end") == "" {
+		return h.writeICS(e, owner, events, "Schedule")
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	from, to, err := h.dateRange(e, "start", "end", loc)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("Schedule|%d|%d|%s", from.Unix(), to.Unix(), loc)
+	return h.serveICS(e, owner, events, key, func(w io.Writer, organizers map[string]calendar.Organizer) error {
+		return calendar.ExportOccurrencesICS(w, events, from, to, loc, "Schedule", organizers)
+	})
+}
+
+// writeICS responds with the events of owner's feed ("" for everyone's) as a
+// text/calendar VCALENDAR, or 304 when the client's copy is still current
+// (see feedValidators).
+func (h *handlers) writeICS(e *core.RequestEvent, owner string, events []*calendar.Event, name string) error {
+	return h.serveICS(e, owner, events, name, func(w io.Writer, organizers map[string]calendar.Organizer) error {
+		return calendar.ExportICS(w, events, name, organizers)
+	})
+}
+
+// serveICS answers a feed request with what export writes, after the cache
+// validators of the events and key (see feedValidators).
+func (h *handlers) serveICS(e *core.RequestEvent, owner string, events []*calendar.Event, key string, export func(io.Writer, map[string]calendar.Organizer) error) error {
+	deleted, err := calendar.LastEventDeletion(e.App, owner)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+	etag, modified := feedValidators(events, key, deleted)
+	e.Response.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		e.Response.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+	if notModified(e.Request, etag, modified) {
+		return e.NoContent(http.StatusNotModified)
+	}
+
+	organizers, err := calendar.FindOrganizers(e.App, events)
+	if err != nil {
+		return e.InternalServerError("Failed to load organizers.", err)
+	}
+
+	e.Response.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	e.Response.WriteHeader(http.StatusOK)
+	return export(e.Response, organizers)
+}
+
+// feedValidators derives the cache validators of a feed. The weak ETag
+// hashes the id and updated time of every included event, so deletions
+// change it too. Last-Modified is the latest update, or the latest deletion
+// of one of the feed owner's events when that is more recent; zero for a
+// feed that never had events.
+func feedValidators(events []*calendar.Event, name string, deleted time.Time) (string, time.Time) {
+	hash := sha1.New()
+	hash.Write([]byte(name))
+	modified := deleted
+	for _, ev := range events {
+		fmt.Fprintf(hash, "|%s@%d", ev.ID, ev.Updated.UnixMilli())
+		if ev.Updated.After(modified) {
+			modified = ev.Updated
+		}
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:8]) + `"`, modified.UTC().Truncate(time.Second)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the client
+// sent no ETag, against the feed validators.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.After(since)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFeedLastModifiedFollowsOwnDeletions(t *testing.T) {
+	// the events were last changed an hour ago, the feed clients polled since
+	changed := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.event(app, "standup", map[string]any{"title": "Standup", "start": at(9, 0), "end": at(9, 15), "owner": f.owner.Id})
+		f.event(app, "retro", map[string]any{"title": "Retro", "start": at(16, 0), "end": at(17, 0), "owner": f.owner.Id})
+		f.event(app, "review", map[string]any{"title": "Review", "start": at(14, 0), "end": at(15, 0), "owner": f.other.Id})
+		updated, _ := types.ParseDateTime(changed)
+		if _, err := app.DB().Update(calendar.EventsCollection, dbx.Params{"updated": updated.String()}, nil).Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	deleting := func(name string) func(testing.TB) *tests.TestApp {
+		return func(t testing.TB) *tests.TestApp {
+			app := f.factory(t)
+			rec, err := app.FindRecordById(calendar.EventsCollection, f.records[name])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := app.Delete(rec); err != nil {
+				t.Fatal(err)
+			}
+			return app
+		}
+	}
+	headers := f.auth(f.owner)
+	headers["If-Modified-Since"] = changed.Format(http.TimeFormat)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "unchanged",
+			Headers:        headers,
+			TestAppFactory: f.factory,
+			ExpectedStatus: http.StatusNotModified,
+		},
+		{
+			Name:           "another user's event deleted",
+			Headers:        headers,
+			TestAppFactory: deleting("review"),
+			ExpectedStatus: http.StatusNotModified,
+		},
+		{
+			Name:               "own event deleted",
+			Headers:            headers,
+			TestAppFactory:     deleting("retro"),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{"SUMMARY:Standup"},
+			NotExpectedContent: []string{"SUMMARY:Retro"},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				modified, err := http.ParseTime(res.Header.Get("Last-Modified"))
+				if err != nil || !modified.After(changed) {
+					t.Fatalf("Last-Modified %q should follow the deletion", res.Header.Get("Last-Modified"))
+				}
+			},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodGet
+		s.URL = "/api/schedule/export.ics"
+		s.Test(t)
+	}
+}
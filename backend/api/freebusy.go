@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// freebusy handles GET /api/schedule/freebusy.
+//
+// Same window and ?calendar query as /occurrences. Returns the blocked intervals of the
+// window; travel buffers are listed separately with kind "travel". The
+// private events of others (shared with the caller) come without their id.
+func (h *handlers) freebusy(e *core.RequestEvent) error {
+	from, to, loc, err := h.occurrencesWindow(e)
+	if err != nil {
+		return err
+	}
+
+	// buffered events starting just outside the window still block it
+	wideFrom, wideTo := from.Add(-calendar.MaxTravel), to.Add(calendar.MaxTravel)
+	events, err := calendar.FindEvents(e.App, e.Auth, wideFrom, wideTo)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+	events = calendar.InCalendars(events, h.calendarIDs(e))
+
+	// the private events of others block time opaquely, as in /team-freebusy
+	busy := calendar.FreeBusy(h.expansions.Expand(events, wideFrom, wideTo, loc), from, to)
+	if !e.HasSuperuserAuth() {
+		private := map[string]bool{}
+		for _, ev := range events {
+			if ev.Visibility == calendar.VisibilityPrivate && ev.Owner != e.Auth.Id {
+				private[ev.ID] = true
+			}
+		}
+		for i, b := range busy {
+			if id, _, _ := strings.Cut(b.ID, "::"); private[id] {
+				busy[i].ID = ""
+			}
+		}
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"busy":     busy,
+	})
+}
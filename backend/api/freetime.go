@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultFreeDays = 7
+	maxFreeDays     = 31
+
+	// defaultFocusMinutes is the block length /next-focus looks for without
+	// ?minutes, a typical deep-work session.
+	defaultFocusMinutes = 90
+)
+
+// freeSummary handles GET /api/schedule/free-summary?timezone=&days=.
+//
+// For "you have X hours free this week": the working time of each of the
+// next days (today included, from now on) with the busy time of the visible
+// occurrences subtracted, recurrences expanded and travel buffers counted.
+// Working hours and days are the configured ones, read in the timezone.
+func (h *handlers) freeSummary(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	days := defaultFreeDays
+	if v := e.Request.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxFreeDays {
+			return e.BadRequestError("days must be between 1 and "+strconv.Itoa(maxFreeDays)+".", err)
+		}
+		days = n
+	}
+
+	now := time.Now()
+	first := calendar.StartOfDay(now, loc)
+	y, m, d := first.Date()
+	from, to := first, time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+
+	// buffered occurrences just outside the days still block them
+	wideFrom, wideTo := from.Add(-calendar.MaxTravel), to.Add(calendar.MaxTravel)
+	items, err := h.expand(e, wideFrom, wideTo, loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	work := calendar.WorkHours{Start: h.cfg.WorkStart, End: h.cfg.WorkEnd, Days: h.cfg.WorkDays}
+	summary := calendar.FreeTime(items, work, now, days, loc)
+	total := map[string]int{"workMinutes": 0, "busyMinutes": 0, "freeMinutes": 0}
+	for _, day := range summary {
+		total["workMinutes"] += day.WorkMinutes
+		total["busyMinutes"] += day.BusyMinutes
+		total["freeMinutes"] += day.FreeMinutes
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"at":       now.UTC(),
+		"timezone": loc.String(),
+		"days":     summary,
+		"total":    total,
+	})
+}
+
+// nextFocus handles GET /api/schedule/next-focus?minutes=&timezone=&within=.
+//
+// For focus-time planning: the earliest block of at least ?minutes (default
+// 90) free within the working hours of the next ?within days (e.g. 7d, the
+// default, at most 31; today included, from now on), blocked like in
+// /free-summary. start is null, with a message, when the calendar is too
+// full; otherwise end is where the free time runs out.
+func (h *handlers) nextFocus(e *core.RequestEvent) error {
+	q := e.Request.URL.Query()
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	minutes := defaultFocusMinutes
+	if v := q.Get("minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 24*60 {
+			return e.BadRequestError("minutes must be between 1 and 1440.", err)
+		}
+		minutes = n
+	}
+	days := defaultFreeDays
+	if v := q.Get("within"); v != "" {
+		n, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+		if err != nil || n < 1 || n > maxFreeDays {
+			return e.BadRequestError("within must be between 1d and "+strconv.Itoa(maxFreeDays)+"d.", err)
+		}
+		days = n
+	}
+
+	now := time.Now()
+	first := calendar.StartOfDay(now, loc)
+	y, m, d := first.Date()
+	from, to := first, time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+
+	items, err := h.expand(e, from.Add(-calendar.MaxTravel), to.Add(calendar.MaxTravel), loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	work := calendar.WorkHours{Start: h.cfg.WorkStart, End: h.cfg.WorkEnd, Days: h.cfg.WorkDays}
+	res := map[string]any{
+		"at":       now.UTC(),
+		"timezone": loc.String(),
+		"minutes":  minutes,
+		"within":   strconv.Itoa(days) + "d",
+		"start":    nil,
+		"end":      nil,
+	}
+	block, ok := calendar.NextFree(items, work, now, days, time.Duration(minutes)*time.Minute, loc)
+	if !ok {
+		span := "the next " + strconv.Itoa(days) + " days"
+		if days == 1 {
+			span = "today"
+		}
+		res["message"] = "No free block of " + strconv.Itoa(minutes) + " minutes within the working hours of " + span + "."
+		return e.JSON(http.StatusOK, res)
+	}
+	res["start"] = block.Start.UTC()
+	res["end"] = block.End.UTC()
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// importICS handles POST /api/schedule/import.
+//
+// The calendar is read from a multipart "file" field or, for any other content
+// type, from the raw request body. Events go to ?calendar (and its owner) when
+// given, otherwise app users import into their default calendar.
+// ?allDayEnd=exclusive|inclusive|auto tells how all-day DTENDs are read
+// (default exclusive, see calendar.AllDayEndAuto).
+func (h *handlers) importICS(e *core.RequestEvent) error {
+	var opts calendar.ImportOptions
+	switch v := e.Request.URL.Query().Get("allDayEnd"); v {
+	case "", calendar.AllDayEndExclusive, calendar.AllDayEndInclusive, calendar.AllDayEndAuto:
+		opts.AllDayEnd = v
+	default:
+		return e.BadRequestError("allDayEnd must be exclusive, inclusive or auto.", nil)
+	}
+	if id := e.Request.URL.Query().Get("calendar"); id != "" {
+		cal, err := e.App.FindRecordById(calendar.CalendarsCollection, id)
+		if err != nil || (!e.HasSuperuserAuth() && cal.GetString("owner") != e.Auth.Id) {
+			return e.NotFoundError("Calendar not found.", err)
+		}
+		opts.Calendar = cal.Id
+		opts.Owner = cal.GetString("owner")
+	} else if !e.HasSuperuserAuth() {
+		opts.Owner = e.Auth.Id
+	}
+
+	var r io.Reader = e.Request.Body
+
+	if strings.HasPrefix(e.Request.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := e.Request.FormFile("file")
+		if err != nil {
+			return e.BadRequestError("Missing file.", err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	result, err := calendar.ImportICS(e.App, r, opts)
+	if err != nil {
+		return e.BadRequestError("Failed to import calendar.", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// invitation is one entry of /events/by-attendee: the event and the
+// attendee's answer.
+type invitation struct {
+	AttendeeID string       `json:"attendeeId"`
+	Status     string       `json:"status"`
+	Event      *core.Record `json:"event"`
+}
+
+// eventsByAttendee handles GET /api/schedule/events/by-attendee?email=.
+//
+// Lists the events an email is invited to with its RSVP status, series
+// included (one entry per series, with its rrule). App users see their own
+// invitations (the default email) and, for any other address, only the
+// invitations to events they own. Sorted by event start.
+func (h *handlers) eventsByAttendee(e *core.RequestEvent) error {
+	email := strings.TrimSpace(e.Request.URL.Query().Get("email"))
+
+	owner := ""
+	if !e.HasSuperuserAuth() {
+		if email == "" || strings.EqualFold(email, e.Auth.Email()) {
+			email = e.Auth.Email()
+		} else {
+			owner = e.Auth.Id
+		}
+	}
+	if email == "" {
+		return e.BadRequestError("email is required.", nil)
+	}
+
+	records, err := calendar.FindInvitations(e.App, email, owner)
+	if err != nil {
+		return e.InternalServerError("Failed to load invitations.", err)
+	}
+
+	items := make([]invitation, 0, len(records))
+	for _, r := range records {
+		ev := r.ExpandedOne("event")
+		if ev == nil {
+			continue
+		}
+		a := calendar.AttendeeFromRecord(r)
+		items = append(items, invitation{AttendeeID: a.ID, Status: a.Status, Event: ev})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Event.GetDateTime("start").Time().Before(items[j].Event.GetDateTime("start").Time())
+	})
+
+	return e.JSON(http.StatusOK, map[string]any{"email": email, "items": items})
+}
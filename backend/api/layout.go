@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// layoutItem is a /layout item: a timed occurrence and its timeline column.
+type layoutItem struct {
+	calendar.Occurrence
+	calendar.Placement
+}
+
+// layout handles GET /api/schedule/layout?date=&timezone=.
+//
+// Returns the timed occurrences of one day (?date, default today) in
+// ?timezone with the column to draw them in and the number of columns of
+// their overlap group (see calendar.Layout), in start order. Occurrences
+// running past midnight are placed by their part within the day; all-day
+// ones are left out, they go in the all-day row.
+func (h *handlers) layout(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	day := time.Now()
+	if v := e.Request.URL.Query().Get("date"); v != "" {
+		if day, err = calendar.ParseTime(v, loc); err != nil {
+			return e.BadRequestError("Invalid date.", err)
+		}
+	}
+	from, to := calendar.DayRange(day, loc)
+
+	occurrences, err := h.expand(e, from, to, loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	items := []layoutItem{}
+	for i, p := range calendar.Layout(occurrences, from, to) {
+		if p.ColumnsInGroup > 0 {
+			items = append(items, layoutItem{Occurrence: occurrences[i], Placement: p})
+		}
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"date":     from.Format(time.DateOnly),
+		"timezone": loc.String(),
+		"items":    items,
+	})
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestLayout(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.event(app, "planning", map[string]any{"title": "Planning", "start": at(9, 0), "end": at(12, 0), "owner": f.owner.Id})
+		f.event(app, "standup", map[string]any{"title": "Standup", "start": at(9, 30), "end": at(10, 30), "owner": f.owner.Id})
+		f.event(app, "review", map[string]any{"title": "Review", "start": at(10, 0), "end": at(11, 0), "owner": f.owner.Id})
+		f.event(app, "lunch", map[string]any{"title": "Lunch", "start": at(11, 30), "end": at(13, 0), "owner": f.owner.Id})
+		f.event(app, "gym", map[string]any{"title": "Gym", "start": at(18, 0), "end": at(19, 0), "owner": f.owner.Id})
+		f.event(app, "night", map[string]any{"title": "Night shift", "start": at(23, 0), "end": atDay(1, 7, 0), "owner": f.owner.Id})
+		f.event(app, "holiday", map[string]any{"title": "Holiday", "start": at(0, 0), "end": atDay(1, 0, 0), "allDay": true, "owner": f.owner.Id})
+		f.event(app, "other", map[string]any{"title": "Other", "start": at(9, 0), "end": at(10, 0), "owner": f.other.Id})
+	})
+
+	type placement struct {
+		Column, ColumnsInGroup int
+	}
+	// planning holds column 0 of the morning group, standup and review nest in
+	// it and lunch chains on through planning, taking standup's freed column
+	want := map[string]placement{
+		"Planning":    {0, 3},
+		"Standup":     {1, 3},
+		"Review":      {2, 3},
+		"Lunch":       {1, 3},
+		"Gym":         {0, 1},
+		"Night shift": {0, 1},
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			URL:             "/api/schedule/layout?date=2026-03-10&timezone=UTC",
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "invalid date",
+			URL:             "/api/schedule/layout?date=tuesday&timezone=UTC",
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"message":"Invalid date."`},
+		},
+		{
+			Name:               "day of the owner",
+			URL:                "/api/schedule/layout?date=2026-03-10&timezone=UTC",
+			Headers:            f.auth(f.owner),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"date":"2026-03-10"`, `"timezone":"UTC"`},
+			NotExpectedContent: []string{`"title":"Holiday"`, `"title":"Other"`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				var body struct {
+					Items []struct {
+						Title string
+						placement
+					}
+				}
+				if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				got := map[string]placement{}
+				for _, it := range body.Items {
+					got[it.Title] = it.placement
+				}
+				if len(got) != len(want) {
+					t.Fatalf("items %v, want %v", got, want)
+				}
+				for title, p := range want {
+					if got[title] != p {
+						t.Errorf("%s placed %+v, want %+v", title, got[title], p)
+					}
+				}
+			},
+		},
+		{
+			// the night shift is placed by its part after midnight
+			Name:               "next day",
+			URL:                "/api/schedule/layout?date=2026-03-11&timezone=UTC",
+			Headers:            f.auth(f.owner),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Night shift"`, `"column":0,"columnsInGroup":1`},
+			NotExpectedContent: []string{`"title":"Planning"`},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodGet
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
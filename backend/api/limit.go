@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// limitFuture handles POST /api/schedule/events/{id}/limit-future.
+//
+// Body: {"keepNext": N}. Rewrites the rrule of a series so N occurrences
+// remain from now on (see calendar.LimitFuture), in the event timezone, else
+// ?timezone. Detached occurrences past the new end are deleted with it.
+// Returns the saved event and the new bounds. App users can only change their
+// own events.
+func (h *handlers) limitFuture(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var body struct {
+		KeepNext *int `json:"keepNext"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	if body.KeepNext == nil {
+		return e.BadRequestError("keepNext is required.", nil)
+	}
+
+	now := time.Now()
+	ev := calendar.EventFromRecord(rec)
+	limit, err := calendar.LimitFuture(ev, now, *body.KeepNext, loc)
+	if err != nil {
+		return e.BadRequestError("Invalid limit: "+err.Error()+".", err)
+	}
+
+	detached, err := calendar.FindDetached(e.App, ev.ID)
+	if err != nil {
+		return e.InternalServerError("Failed to load detached occurrences.", err)
+	}
+	end, last := now, any(nil)
+	if !limit.Last.IsZero() {
+		end, last = limit.Last.Add(time.Second), limit.Last
+	}
+
+	removed := 0
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		rec.Set("rrule", limit.RRule)
+		calendar.SetChangedBy(rec, e.Auth)
+		if err := txApp.Save(rec); err != nil {
+			return err
+		}
+		for _, d := range detached {
+			if d.RecurrenceID.Before(end) {
+				continue
+			}
+			r, err := txApp.FindRecordById(calendar.EventsCollection, d.ID)
+			if err != nil {
+				return err
+			}
+			if err := txApp.Delete(r); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to limit the series.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":           rec,
+		"last":            last,
+		"pastCount":       limit.PastCount,
+		"removedDetached": removed,
+	})
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	shortLinksCollection = "short_links"
+
+	// shortCodeAlphabet leaves out look-alike characters (0/O, 1/l/I) so codes
+	// survive being read out or retyped.
+	shortCodeAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	shortCodeLength   = 7
+
+	// shortCodeAttempts bounds the retries on a (very unlikely) code collision.
+	shortCodeAttempts = 5
+)
+
+// eventURL is the SPA deep link that opens an event.
+func eventURL(base, eventID string) string {
+	return base + "/?event=" + url.QueryEscape(eventID)
+}
+
+// shortURL is the redirecting short form of a deep link.
+func shortURL(base, code string) string {
+	return base + "/e/" + code
+}
+
+// eventLink handles GET /api/schedule/events/{id}/link.
+//
+// Returns the event deep link and its short form. The short code is created
+// on first use and reused afterwards, so the same event always shares the same
+// link. Only the owner and users the event is shared with for writing can
+// create it, since the short link serves the event to anyone.
+func (h *handlers) eventLink(e *core.RequestEvent) error {
+	ev, err := findLinkableEvent(e)
+	if err != nil {
+		return err
+	}
+
+	link, err := shortLink(e.App, ev.Id)
+	if err != nil {
+		return e.InternalServerError("Failed to create the short link.", err)
+	}
+
+	base := baseURL(e)
+	return e.JSON(http.StatusOK, map[string]string{
+		"url":      eventURL(base, ev.Id),
+		"shortUrl": shortURL(base, link.GetString("code")),
+		"code":     link.GetString("code"),
+	})
+}
+
+// findLinkableEvent loads the {id} event for a route creating its short
+// link, a 404 unless the caller may change the event.
+func findLinkableEvent(e *core.RequestEvent) (*core.Record, error) {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || !calendar.CanAccess(e.App, e.Auth, rec, calendar.SharePermissionWrite) {
+		return nil, e.NotFoundError("Event not found.", err)
+	}
+	return rec, nil
+}
+
+// shortLink returns the short link of the event, created on first use.
+func shortLink(app core.App, eventID string) (*core.Record, error) {
+	if link, err := app.FindFirstRecordByData(shortLinksCollection, "event", eventID); err == nil {
+		return link, nil
+	}
+	return newShortLink(app, eventID)
+}
+
+// newShortLink stores a fresh random code for the event.
+func newShortLink(app core.App, eventID string) (*core.Record, error) {
+	collection, err := app.FindCollectionByNameOrId(shortLinksCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; ; i++ {
+		code := security.RandomStringWithAlphabet(shortCodeLength, shortCodeAlphabet)
+		exists, _ := app.CountRecords(shortLinksCollection, dbx.HashExp{"code": code})
+		if exists > 0 && i+1 < shortCodeAttempts {
+			continue
+		}
+
+		rec := core.NewRecord(collection)
+		rec.Set("code", code)
+		rec.Set("event", eventID)
+		if err := app.Save(rec); err != nil {
+			return nil, err
+		}
+		return rec, nil
+	}
+}
+
+// shortLinkRedirect handles GET /e/{code}.
+//
+// Public: redirects (302) to the deep link of the event behind the code,
+// built against the same base URL as /link.
+func (h *handlers) shortLinkRedirect(e *core.RequestEvent) error {
+	link, err := e.App.FindFirstRecordByData(shortLinksCollection, "code", e.Request.PathValue("code"))
+	if err != nil {
+		return e.NotFoundError("Link not found.", err)
+	}
+	return e.Redirect(http.StatusFound, eventURL(baseURL(e), link.GetString("event")))
+}
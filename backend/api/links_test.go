@@ -0,0 +1,182 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// linkFixture has an event of the owner shared with other for reading and
+// with a writer for writing.
+func linkFixture(t *testing.T) (f *fixture, writer *core.Record) {
+	return linkFixtureWith(t, nil)
+}
+
+// linkFixtureWith also lets seed add records.
+func linkFixtureWith(t *testing.T, seed func(app core.App, f *fixture)) (f *fixture, writer *core.Record) {
+	f = newFixture(t, func(app core.App, f *fixture) {
+		writer = f.user(app, "writer@example.com")
+		ev := f.event(app, "talk", map[string]any{"title": "Talk", "start": at(18, 0), "end": at(19, 0), "owner": f.owner.Id})
+		if _, err := calendar.ShareEvent(app, ev, f.other, calendar.SharePermissionRead); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := calendar.ShareEvent(app, ev, writer, calendar.SharePermissionWrite); err != nil {
+			t.Fatal(err)
+		}
+		if seed != nil {
+			seed(app, f)
+		}
+	})
+	return f, writer
+}
+
+func TestEventLinkAccess(t *testing.T) {
+	f, writer := linkFixture(t)
+	url := "/api/schedule/events/" + f.records["talk"] + "/link"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "owner",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"shortUrl":`, `"code":`},
+		},
+		{
+			Name:            "write share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(writer),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"shortUrl":`},
+		},
+		{
+			Name:            "read share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
+
+func TestEventQRAccess(t *testing.T) {
+	f, writer := linkFixture(t)
+	url := "/api/schedule/events/" + f.records["talk"] + "/qr.png?size=64"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "owner",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"PNG"},
+		},
+		{
+			Name:            "write share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(writer),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"PNG"},
+		},
+		{
+			Name:            "read share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
+
+func TestEventAddLinksAccess(t *testing.T) {
+	f, writer := linkFixture(t)
+	url := "/api/schedule/events/" + f.records["talk"] + "/add-links"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "owner",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"google":`, `"outlook":`, `/event.ics"`},
+		},
+		{
+			Name:            "write share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(writer),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"google":`},
+		},
+		{
+			Name:            "read share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
+
+func TestEventICSAccess(t *testing.T) {
+	var stranger *core.Record
+	f, _ := linkFixtureWith(t, func(app core.App, f *fixture) {
+		stranger = f.user(app, "stranger@example.com")
+	})
+	url := "/api/schedule/events/" + f.records["talk"] + "/event.ics"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "owner",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"BEGIN:VEVENT", "SUMMARY:Talk"},
+		},
+		{
+			Name:            "read share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{"SUMMARY:Talk"},
+		},
+		{
+			Name:            "no share",
+			Method:          http.MethodGet,
+			URL:             url,
+			Headers:         f.auth(stranger),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultLocations and maxLocations bound ?limit of /locations.
+const (
+	defaultLocations = 20
+	maxLocations     = 200
+)
+
+// locations handles GET /api/schedule/locations?q=&limit=.
+//
+// Lists the distinct locations of the caller's events (every event's for
+// superusers) with their usage counts, most used first, for a location
+// autocomplete. q keeps the locations starting with it, ignoring case.
+func (h *handlers) locations(e *core.RequestEvent) error {
+	q := e.Request.URL.Query()
+	limit := defaultLocations
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLocations {
+			return e.BadRequestError("limit must be between 1 and "+strconv.Itoa(maxLocations)+".", err)
+		}
+		limit = n
+	}
+
+	owner := ""
+	if !e.HasSuperuserAuth() {
+		owner = e.Auth.Id
+	}
+	items, err := calendar.Locations(e.App, owner, q.Get("q"))
+	if err != nil {
+		return e.InternalServerError("Failed to load locations.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"total": len(items),
+		"items": items[:min(len(items), limit)],
+	})
+}
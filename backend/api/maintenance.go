@@ -0,0 +1,174 @@
+package api
+
+import (
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+	"schedule/reminders"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+)
+
+// rematerialize handles POST /api/schedule/maintenance/rematerialize.
+//
+// Superuser only. Rebuilds the materialized occurrences starting in
+// [?from, ?to), e.g. after the expansion logic changed. Safe to run while the
+// server is live: events are rebuilt in small transactions.
+func (h *handlers) rematerialize(e *core.RequestEvent) error {
+	from, to, err := h.dateRange(e, "from", "to", time.UTC)
+	if err != nil {
+		return err
+	}
+
+	result, err := calendar.Rematerialize(e.App, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to rematerialize occurrences.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":       from,
+		"to":         to,
+		"events":     result.Events,
+		"deleted":    result.Deleted,
+		"created":    result.Created,
+		"durationMs": result.Duration.Milliseconds(),
+	})
+}
+
+// purge handles POST /api/schedule/maintenance/purge?before=.
+//
+// Superuser only. Deletes the events that are entirely over before the cutoff
+// (see calendar.Purge), in small transactions.
+func (h *handlers) purge(e *core.RequestEvent) error {
+	before, err := calendar.ParseTime(e.Request.URL.Query().Get("before"), time.UTC)
+	if err != nil {
+		return e.BadRequestError("Invalid before.", err)
+	}
+
+	result, err := calendar.Purge(e.App, before)
+	if err != nil {
+		return e.InternalServerError("Failed to purge events.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"before":   before,
+		"single":   result.Single,
+		"series":   result.Series,
+		"detached": result.Detached,
+		"total":    result.Single + result.Series + result.Detached,
+	})
+}
+
+// normalize handles POST /api/schedule/maintenance/normalize.
+//
+// Superuser only. Backfills the normalization new saves get (titles, colors,
+// tags and exdates, see calendar.Normalize) onto every stored event, in small
+// transactions. Events whose save is rejected are listed in failed.
+func (h *handlers) normalize(e *core.RequestEvent) error {
+	result, err := calendar.NormalizeEvents(e.App)
+	if err != nil {
+		return e.InternalServerError("Failed to normalize events.", err)
+	}
+	return e.JSON(http.StatusOK, result)
+}
+
+// remindersReset handles POST /api/schedule/maintenance/reminders/reset?from=&to=.
+//
+// Superuser only. Forgets which reminders of the occurrences starting in the
+// window were sent, so they fire again (e.g. while testing the dispatcher).
+func (h *handlers) remindersReset(e *core.RequestEvent) error {
+	from, to, err := h.dateRange(e, "from", "to", time.UTC)
+	if err != nil {
+		return err
+	}
+
+	n, err := reminders.ResetSent(e.App, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to reset reminders.", err)
+	}
+	return e.JSON(http.StatusOK, map[string]any{"from": from, "to": to, "cleared": n})
+}
+
+// remindersMarkSent handles POST /api/schedule/maintenance/reminders/mark-sent?from=&to=.
+//
+// Superuser only. Records the reminders triggering in the window as sent
+// without delivering them, so past ones never fire after a first deploy.
+func (h *handlers) remindersMarkSent(e *core.RequestEvent) error {
+	from, to, err := h.dateRange(e, "from", "to", time.UTC)
+	if err != nil {
+		return err
+	}
+
+	n, err := reminders.MarkSent(e.App, h.cfg, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to mark reminders as sent.", err)
+	}
+	return e.JSON(http.StatusOK, map[string]any{"from": from, "to": to, "marked": n})
+}
+
+// validateCalendar handles GET /api/schedule/maintenance/validate.
+//
+// Superuser only. A one-shot data-quality audit of every event, grouped by
+// issue type (see calendar.Audit). Events without a timezone are checked in
+// ?timezone.
+func (h *handlers) validateCalendar(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	report, err := calendar.Audit(e.App, time.Now(), loc)
+	if err != nil {
+		return e.InternalServerError("Failed to audit events.", err)
+	}
+	return e.JSON(http.StatusOK, report)
+}
+
+// testEmail handles POST /api/schedule/maintenance/test-email.
+//
+// Superuser only. Body: {"to": "<address>"}. Sends a test message right away
+// through the configured mailer, bypassing the email queue, and reports
+// whether it went out or the mailer's error (e.g. the SMTP server's reply),
+// so a misconfiguration shows up before a reminder goes missing.
+func (h *handlers) testEmail(e *core.RequestEvent) error {
+	var body struct {
+		To string `json:"to"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	to := strings.TrimSpace(body.To)
+	if to == "" || is.EmailFormat.Validate(to) != nil {
+		return e.BadRequestError("Invalid body.", validation.Errors{
+			"to": validation.NewError("validation_invalid_email", "Must be a valid email address."),
+		})
+	}
+
+	settings := e.App.Settings()
+	started := time.Now()
+	err := e.App.NewMailClient().Send(&mailer.Message{
+		From:    mail.Address{Name: settings.Meta.SenderName, Address: settings.Meta.SenderAddress},
+		To:      []mail.Address{{Address: to}},
+		Subject: "Schedule test email",
+		HTML:    "<p>This is a test email from " + settings.Meta.AppName + ". Email delivery works.</p>",
+		Text:    "This is a test email from " + settings.Meta.AppName + ". Email delivery works.",
+	})
+
+	res := map[string]any{
+		"to":         to,
+		"sent":       err == nil,
+		"smtp":       settings.SMTP.Enabled,
+		"durationMs": time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		res["error"] = err.Error()
+		e.App.Logger().Warn("test email failed", "to", to, "error", err)
+	}
+	return e.JSON(http.StatusOK, res)
+}
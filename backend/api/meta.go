@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// calendarMeta handles GET /api/schedule/calendar-meta?date=&timezone=&weekStart=&calendarSystem=.
+//
+// Describes the week of ?date (default today) twice: the display week, which
+// starts on weekStart and drives the grid layout, and the ISO-8601 week, which
+// always starts on Monday and provides the week number.
+//
+// ?calendarSystem (one of calendar.WeekSystems) adds the week numbering and
+// labels of that system: its week of the date, the week labelling the display
+// row and the month the display week is listed under. Without ?weekStart the
+// display week then starts on the system's first day.
+func (h *handlers) calendarMeta(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	weekStart, err := h.weekStart(e)
+	if err != nil {
+		return e.BadRequestError("Invalid weekStart.", err)
+	}
+
+	var system *calendar.WeekSystem
+	if v := e.Request.URL.Query().Get("calendarSystem"); v != "" {
+		s, ok := calendar.WeekSystems[v]
+		if !ok {
+			return e.BadRequestError("calendarSystem must be iso, us or me.", nil)
+		}
+		system = &s
+		if e.Request.URL.Query().Get("weekStart") == "" {
+			weekStart = s.FirstDay
+		}
+	}
+
+	day := time.Now()
+	if v := e.Request.URL.Query().Get("date"); v != "" {
+		if day, err = calendar.ParseTime(v, loc); err != nil {
+			return e.BadRequestError("Invalid date.", err)
+		}
+	}
+
+	weekFrom, weekTo := calendar.WeekRange(day, loc, weekStart)
+	isoFrom, isoTo := calendar.ISOWeekRange(day, loc)
+	iso := calendar.ISOWeekOf(day, loc)
+
+	res := map[string]any{
+		"date":      calendar.StartOfDay(day, loc).Format(time.DateOnly),
+		"timezone":  loc.String(),
+		"weekStart": int(weekStart),
+		"week": map[string]any{
+			"start":   weekFrom,
+			"end":     weekTo,
+			"isoWeek": calendar.RowISOWeek(weekFrom, loc),
+		},
+		"isoWeek": map[string]any{
+			"year":  iso.Year,
+			"week":  iso.Week,
+			"start": isoFrom,
+			"end":   isoTo,
+		},
+	}
+
+	if system != nil {
+		from, to := system.WeekRange(day, loc)
+		year, month := system.MonthOf(weekFrom, loc)
+		res["calendarSystem"] = map[string]any{
+			"name":     system.Name,
+			"firstDay": int(system.FirstDay),
+			"minDays":  system.MinDays,
+			"week": map[string]any{
+				"start": from,
+				"end":   to,
+				"week":  system.WeekOf(day, loc),
+			},
+			"rowWeek": system.RowWeek(weekFrom, loc),
+			"month": map[string]any{
+				"year":  year,
+				"month": int(month),
+				"label": month.String() + " " + strconv.Itoa(year),
+			},
+		}
+	}
+
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestCalendarMetaSundayStart(t *testing.T) {
+	f := newFixture(t, nil)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:    "Sunday start",
+			URL:     "/api/schedule/calendar-meta?date=2026-03-15&timezone=UTC&weekStart=0",
+			Headers: f.auth(f.owner),
+			ExpectedContent: []string{
+				`"weekStart":0`,
+				`"week":{"end":"2026-03-22T00:00:00Z","isoWeek":{"year":2026,"week":12},"start":"2026-03-15T00:00:00Z"}`,
+				`"isoWeek":{"end":"2026-03-16T00:00:00Z","start":"2026-03-09T00:00:00Z","week":11,"year":2026}`,
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:    "Monday start",
+			URL:     "/api/schedule/calendar-meta?date=2026-03-15&timezone=UTC&weekStart=1",
+			Headers: f.auth(f.owner),
+			ExpectedContent: []string{
+				`"week":{"end":"2026-03-16T00:00:00Z","isoWeek":{"year":2026,"week":11},"start":"2026-03-09T00:00:00Z"}`,
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:            "invalid week start",
+			URL:             "/api/schedule/calendar-meta?date=2026-03-15&weekStart=7",
+			Headers:         f.auth(f.owner),
+			ExpectedContent: []string{`"message":"Invalid weekStart."`},
+			ExpectedStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodGet
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/mailqueue"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// metrics handles GET /api/schedule/metrics.
+//
+// Superuser only. Operational counters: the email queue depth per status and
+// the hits and misses of the expansion cache.
+func (h *handlers) metrics(e *core.RequestEvent) error {
+	depth, err := mailqueue.Depth(e.App)
+	if err != nil {
+		return e.InternalServerError("Failed to read the email queue.", err)
+	}
+	hits, misses := h.expansions.Stats()
+	return e.JSON(http.StatusOK, map[string]any{
+		"emailQueue":     depth,
+		"expansionCache": map[string]int64{"hits": hits, "misses": misses},
+	})
+}
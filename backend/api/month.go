@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// monthItem is the minimal occurrence shape the month grid renders.
+type monthItem struct {
+	ID     string    `json:"id"`
+	Title  string    `json:"title"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	AllDay bool      `json:"allDay"`
+	Color  string    `json:"color,omitempty"`
+}
+
+// month handles GET /api/schedule/month?year=&month=&timezone=&weekStart=.
+//
+// Returns the occurrences overlapping the whole month grid, including the
+// leading/trailing days of adjacent months, with only the fields needed to
+// draw pills.
+func (h *handlers) month(e *core.RequestEvent) error {
+	q := e.Request.URL.Query()
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	weekStart, err := h.weekStart(e)
+	if err != nil {
+		return e.BadRequestError("Invalid weekStart.", err)
+	}
+
+	now := time.Now().In(loc)
+	year, month := now.Year(), now.Month()
+	if v := q.Get("year"); v != "" {
+		if year, err = strconv.Atoi(v); err != nil || year < 1 || year > 9999 {
+			return e.BadRequestError("Invalid year.", err)
+		}
+	}
+	if v := q.Get("month"); v != "" {
+		m, err := strconv.Atoi(v)
+		if err != nil || m < 1 || m > 12 {
+			return e.BadRequestError("Invalid month.", err)
+		}
+		month = time.Month(m)
+	}
+
+	from, to := calendar.MonthGridRange(year, month, loc, weekStart)
+
+	v, err := h.loadView(e, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	items := []monthItem{}
+	err = v.stream(from, to, loc, func(o calendar.Occurrence) error {
+		items = append(items, monthItem{
+			ID:     o.ID,
+			Title:  o.Title,
+			Start:  o.Start,
+			End:    o.End,
+			AllDay: o.AllDay,
+			Color:  o.Color,
+		})
+		return nil
+	})
+	if err != nil {
+		return e.InternalServerError("Failed to expand events.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"year":      year,
+		"month":     int(month),
+		"timezone":  loc.String(),
+		"weekStart": int(weekStart),
+		"gridStart": from,
+		"gridEnd":   to,
+		"weeks":     gridWeeks(from, to, loc),
+		"items":     items,
+	})
+}
+
+// gridWeek is one row of the month grid with its ISO week number.
+type gridWeek struct {
+	Start   time.Time        `json:"start"`
+	ISOWeek calendar.ISOWeek `json:"isoWeek"`
+}
+
+func gridWeeks(from, to time.Time, loc *time.Location) []gridWeek {
+	var out []gridWeek
+	for row := from; row.Before(to); row = row.AddDate(0, 0, 7) {
+		out = append(out, gridWeek{Start: row, ISOWeek: calendar.RowISOWeek(row, loc)})
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const msgpackContentType = "application/msgpack"
+
+// msgpackTypes are the media types clients ask for MessagePack with.
+var msgpackTypes = []string{msgpackContentType, "application/x-msgpack", "application/vnd.msgpack"}
+
+// acceptsMsgpack reports whether the Accept header of r lists a MessagePack
+// media type (with a non-zero quality).
+func acceptsMsgpack(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		for _, t := range msgpackTypes {
+			if mediaType == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeMsgpack responds with v encoded as MessagePack. Structs are encoded by
+// their json tags, so the fields match the JSON responses.
+func writeMsgpack(e *core.RequestEvent, status int, v any) error {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return e.InternalServerError("Failed to encode the response.", err)
+	}
+
+	e.Response.Header().Set("Content-Type", msgpackContentType)
+	e.Response.WriteHeader(status)
+	_, err := e.Response.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// errFound stops a stream once the wanted occurrence was seen.
+var errFound = errors.New("found")
+
+// nearest handles GET /api/schedule/nearest?at=&timezone=.
+//
+// For now/next displays: the occurrence in progress at ?at (default now), the
+// one started last if several overlap, and the next one starting after it
+// within the horizon, recurrences expanded. Either is null when there is none.
+// elapsedSeconds and untilNextSeconds are relative to at.
+func (h *handlers) nearest(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	at := time.Now()
+	if v := e.Request.URL.Query().Get("at"); v != "" {
+		if at, err = calendar.ParseTime(v, loc); err != nil {
+			return e.BadRequestError("Invalid at.", err)
+		}
+	}
+
+	to := at.Add(h.cfg.Horizon)
+	v, err := h.loadView(e, at, to)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	var current, next *calendar.Occurrence
+	err = v.stream(at, to, loc, func(o calendar.Occurrence) error {
+		if o.Start.After(at) {
+			next = &o
+			return errFound
+		}
+		if o.End.After(at) || o.Start.Equal(at) {
+			current = &o // occurrences come by start, so the last one wins
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	res := map[string]any{
+		"at":       at.UTC(),
+		"timezone": loc.String(),
+		"current":  current,
+		"next":     next,
+	}
+	if current != nil {
+		res["elapsedSeconds"] = int(at.Sub(current.Start).Seconds())
+	}
+	if next != nil {
+		res["untilNextSeconds"] = int(next.Start.Sub(at).Seconds())
+	}
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 500
+)
+
+// pagination reads ?page (from 1) and ?perPage (default defaultPerPage, at
+// most maxPerPage).
+func pagination(q url.Values) (page, perPage int) {
+	page, _ = strconv.Atoi(q.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ = strconv.Atoi(q.Get("perPage"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	return page, min(perPage, maxPerPage)
+}
+
+// view is what a request expands: the events that may occur in the window,
+// the entries the requesting user hid and the private events of others (ids)
+// whose details they don't see.
+type view struct {
+	events   []*calendar.Event
+	hidden   []calendar.Hidden
+	redacted map[string]bool
+	cache    *calendar.ExpansionCache
+}
+
+// loadView loads the events of [from, to) as seen by the requesting user.
+func (h *handlers) loadView(e *core.RequestEvent, from, to time.Time) (*view, error) {
+	events, err := calendar.FindEvents(e.App, e.Auth, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &view{events: calendar.InCalendars(events, h.calendarIDs(e)), redacted: map[string]bool{}, cache: h.expansions}
+	if e.Auth != nil && !e.Auth.IsSuperuser() {
+		if v.hidden, err = calendar.FindHidden(e.App, e.Auth.Id); err != nil {
+			return nil, err
+		}
+		for _, ev := range v.events {
+			if ev.Visibility == calendar.VisibilityPrivate && ev.Owner != e.Auth.Id {
+				v.redacted[ev.ID] = true
+			}
+		}
+	}
+	return v, nil
+}
+
+// stream calls fn for each visible occurrence in [from, to), in order.
+func (v *view) stream(from, to time.Time, loc *time.Location, fn func(calendar.Occurrence) error) error {
+	return v.cache.Stream(v.events, from, to, loc, func(o calendar.Occurrence) error {
+		if calendar.IsHidden(o, v.hidden) {
+			return nil
+		}
+		if v.redacted[o.ID] || v.redacted[o.SourceID] {
+			o = o.Redacted()
+		}
+		return fn(o)
+	})
+}
+
+// expand loads and expands the visible occurrences of [from, to).
+func (h *handlers) expand(e *core.RequestEvent, from, to time.Time, loc *time.Location) ([]calendar.Occurrence, error) {
+	v, err := h.loadView(e, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []calendar.Occurrence{}
+	err = v.stream(from, to, loc, func(o calendar.Occurrence) error {
+		items = append(items, o)
+		return nil
+	})
+	return items, err
+}
+
+// window resolves the requested time window either from a relative ?range
+// keyword (today, tomorrow, this-week, next-week, this-month) or from explicit
+// ?start/?end values (see dateRange). Errors are 400 responses.
+func (h *handlers) window(e *core.RequestEvent, loc *time.Location) (time.Time, time.Time, error) {
+	keyword := e.Request.URL.Query().Get("range")
+	if keyword == "" {
+		return h.dateRange(e, "start", "end", loc)
+	}
+
+	weekStart, err := h.weekStart(e)
+	if err != nil {
+		return time.Time{}, time.Time{}, e.BadRequestError("Invalid weekStart.", err)
+	}
+	from, to, err := calendar.RelativeRange(keyword, time.Now(), loc, weekStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, e.BadRequestError("Invalid range.", validation.Errors{
+			"range": validation.NewError("validation_invalid_range", "Must be one of "+strings.Join(calendar.RelativeRanges, ", ")+"."),
+		})
+	}
+	return from, to, nil
+}
+
+// occurrencesWindow parses the timezone and window shared by the occurrences
+// routes.
+func (h *handlers) occurrencesWindow(e *core.RequestEvent) (time.Time, time.Time, *time.Location, error) {
+	loc, err := h.location(e)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, e.BadRequestError("Invalid timezone.", err)
+	}
+
+	from, to, err := h.window(e, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+
+	return from, to, loc, nil
+}
+
+// occurrences handles GET /api/schedule/occurrences.
+//
+// Query: range=<keyword> or start=&end= (at most the horizon apart), optional
+// timezone and weekStart. Each item carries hasConflict, set when it overlaps
+// another timed item of the window (see calendar.Overlapping). Clients
+// accepting MessagePack get the response in it, like from
+// /occurrences.msgpack.
+func (h *handlers) occurrences(e *core.RequestEvent) error {
+	e.Response.Header().Add("Vary", "Accept")
+	if acceptsMsgpack(e.Request) {
+		return h.occurrencesMsgpack(e)
+	}
+
+	res, err := h.occurrencesResponse(e)
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, res)
+}
+
+// occurrencesMsgpack handles GET /api/schedule/occurrences.msgpack.
+//
+// The /occurrences response, fields and all, encoded as MessagePack for
+// clients on slow links. Times are MessagePack timestamps. Errors stay JSON.
+func (h *handlers) occurrencesMsgpack(e *core.RequestEvent) error {
+	res, err := h.occurrencesResponse(e)
+	if err != nil {
+		return err
+	}
+	return writeMsgpack(e, http.StatusOK, res)
+}
+
+// occurrencesResponse expands the requested window into the /occurrences
+// response. Errors are error responses.
+func (h *handlers) occurrencesResponse(e *core.RequestEvent) (map[string]any, error) {
+	from, to, loc, err := h.occurrencesWindow(e)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := h.expand(e, from, to, loc)
+	if err != nil {
+		return nil, e.InternalServerError("Failed to load events.", err)
+	}
+
+	conflicts := calendar.Overlapping(items)
+	listed := make([]listedOccurrence, len(items))
+	for i, o := range items {
+		listed[i] = listedOccurrence{Occurrence: o, HasConflict: conflicts[i]}
+	}
+
+	return map[string]any{
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"items":    listed,
+	}, nil
+}
+
+// listedOccurrence is an /occurrences item. HasConflict tells whether it
+// overlaps another timed occurrence of the response.
+type listedOccurrence struct {
+	calendar.Occurrence
+	HasConflict bool `json:"hasConflict"`
+}
+
+// ndjsonFlushEvery is how many lines the NDJSON stream writes between flushes.
+const ndjsonFlushEvery = 64
+
+// occurrencesNDJSON handles GET /api/schedule/occurrences.ndjson.
+//
+// Same query and expansion as /occurrences, but each occurrence is written as
+// its own JSON line while the expansion runs, so neither side has to hold the
+// full result in memory.
+func (h *handlers) occurrencesNDJSON(e *core.RequestEvent) error {
+	from, to, loc, err := h.occurrencesWindow(e)
+	if err != nil {
+		return err
+	}
+
+	// load up front so load errors can still be reported as JSON
+	v, err := h.loadView(e, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	e.Response.Header().Set("Content-Type", "application/x-ndjson")
+	e.Response.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(e.Response)
+	n := 0
+	err = v.stream(from, to, loc, func(o calendar.Occurrence) error {
+		if err := enc.Encode(o); err != nil {
+			return err
+		}
+		if n++; n%ndjsonFlushEvery == 0 {
+			return e.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// the status line is already sent; the client sees a truncated stream
+		e.App.Logger().Debug("occurrences stream aborted", "error", err)
+		return nil
+	}
+
+	return e.Flush()
+}
+
+// agenda handles GET /api/schedule/agenda.
+//
+// Returns the occurrences of one day (?date, default today) in ?timezone,
+// paginated with ?page and ?perPage. Day boundaries are the local midnights, the
+// same logic the relative ranges use. With ?q only the occurrences matching
+// every word of it (see calendar.SearchOccurrence) are listed and paginated,
+// each with the matches to highlight.
+func (h *handlers) agenda(e *core.RequestEvent) error {
+	q := e.Request.URL.Query()
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	day := time.Now()
+	if v := q.Get("date"); v != "" {
+		day, err = calendar.ParseTime(v, loc)
+		if err != nil {
+			return e.BadRequestError("Invalid date.", err)
+		}
+	}
+	from, to := calendar.DayRange(day, loc)
+
+	page, perPage := pagination(q)
+
+	items, err := h.expand(e, from, to, loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	res := map[string]any{
+		"date":     from.Format(time.DateOnly),
+		"timezone": loc.String(),
+		"page":     page,
+		"perPage":  perPage,
+	}
+
+	search := strings.TrimSpace(q.Get("q"))
+	if search == "" {
+		lo, hi := pageBounds(page, perPage, len(items))
+		res["totalItems"], res["items"] = len(items), items[lo:hi]
+		return e.JSON(http.StatusOK, res)
+	}
+
+	found := []agendaMatch{}
+	for _, o := range items {
+		if matches, ok := calendar.SearchOccurrence(o, search); ok {
+			found = append(found, agendaMatch{Occurrence: o, Matches: matches})
+		}
+	}
+	lo, hi := pageBounds(page, perPage, len(found))
+	res["q"], res["totalItems"], res["items"] = search, len(found), found[lo:hi]
+	return e.JSON(http.StatusOK, res)
+}
+
+// agendaMatch is an /agenda?q= item: an occurrence matching the search, with
+// the matches to highlight.
+type agendaMatch struct {
+	calendar.Occurrence
+	Matches []calendar.Highlight `json:"matches"`
+}
+
+// pageBounds returns the slice bounds of a page of total items.
+func pageBounds(page, perPage, total int) (int, int) {
+	lo := min((page-1)*perPage, total)
+	return lo, min(lo+perPage, total)
+}
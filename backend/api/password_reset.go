@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// passwordResetPath is the PocketBase route requesting a reset email.
+	passwordResetPath = "/api/collections/" + calendar.UsersCollection + "/request-password-reset"
+
+	resetWindow   = time.Hour
+	resetPerEmail = 3
+	resetPerIP    = 10
+
+	// resetBaseURLKey is a custom (non-persisted) record key carrying the
+	// request base URL from the reset request to the mailer hook.
+	resetBaseURLKey = "@resetBaseURL"
+)
+
+// limitPasswordReset throttles reset requests per email and per client IP.
+// It runs before PocketBase looks up the email, so the answer is the same
+// whether or not an account exists.
+func (h *handlers) limitPasswordReset(e *core.RequestEvent) error {
+	if e.Request.Method != http.MethodPost || e.Request.URL.Path != passwordResetPath {
+		return e.Next()
+	}
+
+	var body struct {
+		Email string `json:"email" form:"email"`
+	}
+	_ = e.BindBody(&body) // the body is re-readable; PocketBase validates it
+
+	now := time.Now()
+	okIP := h.resetByIP.allow(e.RealIP(), now)
+	okEmail := body.Email == "" || h.resetByEmail.allow(strings.ToLower(strings.TrimSpace(body.Email)), now)
+	if !okIP || !okEmail {
+		return e.TooManyRequestsError("Too many password reset requests. Try again later.", nil)
+	}
+
+	return e.Next()
+}
+
+// rememberResetBaseURL stores the base URL of the reset request on the record,
+// since the email itself is rendered in the background without the request.
+// An untrusted one (see trustedBaseURL) is not kept: the links would carry the
+// token to any host a client puts in the request.
+func rememberResetBaseURL(e *core.RecordRequestPasswordResetRequestEvent) error {
+	base, ok := trustedBaseURL(e.RequestEvent)
+	if !ok {
+		e.App.Logger().Warn("the Application URL is not set, password reset links point at the default one",
+			"appURL", e.App.Settings().Meta.AppURL, "host", e.Request.Host)
+		return e.Next()
+	}
+	e.Record.Set(resetBaseURLKey, base)
+	return e.Next()
+}
+
+// rewriteResetLinks points the reset email links at the request base URL when
+// it differs from the configured Application URL.
+func rewriteResetLinks(e *core.MailerRecordEvent) error {
+	base := e.Record.GetString(resetBaseURLKey)
+	appURL := strings.TrimRight(e.App.Settings().Meta.AppURL, "/")
+	if base != "" && appURL != "" && base != appURL {
+		e.Message.HTML = strings.ReplaceAll(e.Message.HTML, appURL, base)
+		e.Message.Text = strings.ReplaceAll(e.Message.Text, appURL, base)
+	}
+	return e.Next()
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// The request host of these tests is httptest's example.com, as a forged one
+// would be.
+func TestResetLinksBaseURL(t *testing.T) {
+	f := newFixture(t, nil)
+	withSettings := func(appURL string, proxy bool) func(t testing.TB) *tests.TestApp {
+		return func(t testing.TB) *tests.TestApp {
+			app := f.factory(t)
+			settings := app.Settings()
+			if appURL != "" {
+				settings.Meta.AppURL = appURL
+			}
+			if proxy {
+				settings.TrustedProxy.Headers = []string{"X-Forwarded-For"}
+			}
+			return app
+		}
+	}
+
+	scenarios := []struct {
+		name    string
+		factory func(t testing.TB) *tests.TestApp
+		headers map[string]string
+		want    string
+	}{
+		{"default Application URL ignores the request host", withSettings("", false), nil, "http://localhost:8090/"},
+		{"default Application URL ignores forwarded headers", withSettings("", false), map[string]string{"X-Forwarded-Host": "evil.example"}, "http://localhost:8090/"},
+		{"trusted proxy", withSettings("", true), map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "cal.example.org"}, "https://cal.example.org/"},
+		{"configured Application URL", withSettings("https://schedule.example.org", false), nil, "https://schedule.example.org/"},
+	}
+	for _, s := range scenarios {
+		(&tests.ApiScenario{
+			Name:           s.name,
+			Method:         http.MethodPost,
+			URL:            passwordResetPath,
+			Body:           strings.NewReader(`{"email":"owner@example.com"}`),
+			Headers:        s.headers,
+			Delay:          100 * time.Millisecond,
+			TestAppFactory: s.factory,
+			ExpectedStatus: http.StatusNoContent,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if app.TestMailer.TotalSend() != 1 {
+					t.Fatalf("%d emails sent, want 1", app.TestMailer.TotalSend())
+				}
+				html := app.TestMailer.LastMessage().HTML
+				if !strings.Contains(html, `href="`+s.want) {
+					t.Fatalf("reset link not under %s:\n%s", s.want, html)
+				}
+				if strings.Contains(html, "example.com") || strings.Contains(html, "evil.example") {
+					t.Fatalf("reset link follows the request host:\n%s", html)
+				}
+			},
+		}).Test(t)
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// pauseSeries handles POST /api/schedule/events/{id}/pause.
+//
+// Body: {"from": "...", "to": "..."}. Adds the pause window [from, to) to a
+// series: its instances starting in it are skipped until the pause is
+// removed again, the rrule is left alone. Date-only values are midnight in the
+// event zone (or ?timezone). A window overlapping an existing pause is
+// rejected. App users can only pause their own events.
+func (h *handlers) pauseSeries(e *core.RequestEvent) error {
+	var body struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	return h.updatePauses(e, func(ev *calendar.Event, loc *time.Location) ([]calendar.Pause, error) {
+		from, err := eventTime(ev, body.From, loc)
+		if err != nil {
+			return nil, errors.New("invalid from")
+		}
+		to, err := eventTime(ev, body.To, loc)
+		if err != nil {
+			return nil, errors.New("invalid to")
+		}
+		return append(ev.Pauses, calendar.Pause{From: from, To: to}), nil
+	})
+}
+
+// resumeSeries handles POST /api/schedule/events/{id}/resume.
+//
+// Body: {"at": "..."}, optional. Removes the pause containing at, or every
+// pause of the series without one. App users can only resume their own
+// events.
+func (h *handlers) resumeSeries(e *core.RequestEvent) error {
+	var body struct {
+		At string `json:"at"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	return h.updatePauses(e, func(ev *calendar.Event, loc *time.Location) ([]calendar.Pause, error) {
+		if body.At == "" {
+			return []calendar.Pause{}, nil
+		}
+		at, err := eventTime(ev, body.At, loc)
+		if err != nil {
+			return nil, errors.New("invalid at")
+		}
+		kept := slices.DeleteFunc(slices.Clone(ev.Pauses), func(p calendar.Pause) bool {
+			return !at.Before(p.From) && at.Before(p.To)
+		})
+		if len(kept) == len(ev.Pauses) {
+			return nil, errors.New("no pause of the series contains at")
+		}
+		return kept, nil
+	})
+}
+
+// eventTime parses a time of ev such as a pause bound: date-only values are
+// midnight in loc, and floating series store wall clock times as UTC.
+func eventTime(ev *calendar.Event, s string, loc *time.Location) (time.Time, error) {
+	t, err := calendar.ParseTime(s, loc)
+	if err != nil {
+		return t, err
+	}
+	if ev.Floating {
+		return calendar.WallClock(t.In(loc), time.UTC), nil
+	}
+	return t.UTC(), nil
+}
+
+// updatePauses replaces the pauses of the series {id} with the ones change
+// derives from the current ones. The event is re-read inside the transaction,
+// so concurrent changes don't drop each other's pauses. It responds with the
+// stored pauses.
+func (h *handlers) updatePauses(e *core.RequestEvent, change func(ev *calendar.Event, loc *time.Location) ([]calendar.Pause, error)) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	if !calendar.EventFromRecord(rec).IsRecurring() {
+		return e.BadRequestError("Only recurring events can be paused.", nil)
+	}
+
+	fallback, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var pauses []calendar.Pause
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		rec, err = txApp.FindRecordById(calendar.EventsCollection, rec.Id)
+		if err != nil {
+			return err
+		}
+		ev := calendar.EventFromRecord(rec)
+		if pauses, err = change(ev, ev.Zone(fallback)); err != nil {
+			return err
+		}
+		if err := calendar.ValidatePauses(pauses); err != nil {
+			return err
+		}
+		rec.Set("pauses", calendar.StoredPauses(pauses))
+		calendar.SetChangedBy(rec, e.Auth)
+		return txApp.Save(rec)
+	})
+	if err != nil {
+		return e.BadRequestError("Invalid pause: "+err.Error()+".", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":  rec.Id,
+		"pauses": calendar.StoredPauses(pauses),
+	})
+}
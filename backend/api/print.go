@@ -0,0 +1,141 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// printTemplate is the printable agenda: plain markup with inline CSS so it
+// prints without the SPA stylesheets.
+var printTemplate = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  body { font: 11pt/1.4 system-ui, sans-serif; color: #000; margin: 2em; }
+  h1 { font-size: 16pt; margin: 0 0 .2em; }
+  .zone { color: #555; margin: 0 0 1.5em; }
+  h2 { font-size: 12pt; border-bottom: 1px solid #000; margin: 1.2em 0 .4em; padding-bottom: .1em; }
+  table { width: 100%; border-collapse: collapse; }
+  td { padding: .15em .5em .15em 0; vertical-align: top; }
+  td.time { width: 9em; white-space: nowrap; font-variant-numeric: tabular-nums; }
+  td.location { color: #555; width: 30%; }
+  section { break-inside: avoid; }
+  .empty { color: #555; }
+  @page { margin: 1.5cm; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="zone">{{.Timezone}}</p>
+{{range .Days}}<section>
+<h2>{{.Date}}</h2>
+<table>
+{{range .Items}}<tr><td class="time">{{.Time}}</td><td>{{.Title}}</td><td class="location">{{.Location}}</td></tr>
+{{end}}</table>
+</section>
+{{else}}<p class="empty">No events.</p>
+{{end}}</body>
+</html>
+`))
+
+type printItem struct {
+	Time     string
+	Title    string
+	Location string
+}
+
+type printDay struct {
+	Date  string
+	Items []printItem
+}
+
+// printAgenda handles GET /api/schedule/print?from=&to=&timezone=.
+//
+// Renders the occurrences of [from, to) as a print-friendly HTML page, grouped
+// by day in timezone. Events spanning several days are listed on each of
+// them; days without events are left out.
+func (h *handlers) printAgenda(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	from, to, err := h.dateRange(e, "from", "to", loc)
+	if err != nil {
+		return err
+	}
+
+	items, err := h.expand(e, from, to, loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	var days []printDay
+	for day := calendar.StartOfDay(from, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		d := printDay{Date: day.Format("Monday, 2 January 2006")}
+		for _, o := range items {
+			start, end := o.Start.In(loc), o.End.In(loc)
+			if !onDay(start, end, day, next) {
+				continue
+			}
+			d.Items = append(d.Items, printItem{Time: printTime(o, start, end, day, next), Title: o.Title, Location: o.Location})
+		}
+		if len(d.Items) > 0 {
+			days = append(days, d)
+		}
+	}
+
+	lastDay := from
+	if to.After(from) {
+		lastDay = to.Add(-time.Nanosecond)
+	}
+	title := "Schedule, " + from.In(loc).Format("2 Jan 2006")
+	if !calendar.StartOfDay(lastDay, loc).Equal(calendar.StartOfDay(from, loc)) {
+		title += " – " + lastDay.In(loc).Format("2 Jan 2006")
+	}
+
+	e.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	e.Response.WriteHeader(http.StatusOK)
+	return printTemplate.Execute(e.Response, map[string]any{
+		"Title":    title,
+		"Timezone": loc.String(),
+		"Days":     days,
+	})
+}
+
+// onDay reports whether [start, end) touches the day [day, next); zero-length
+// occurrences belong to the day they start on.
+func onDay(start, end, day, next time.Time) bool {
+	if !end.After(start) {
+		return !start.Before(day) && start.Before(next)
+	}
+	return start.Before(next) && end.After(day)
+}
+
+// printTime is the time column of an occurrence on the day [day, next):
+// "09:00–10:30", "All day", or open ends for events continuing from or into
+// other days.
+func printTime(o calendar.Occurrence, start, end, day, next time.Time) string {
+	if o.AllDay {
+		return "All day"
+	}
+	from, until := start.Format("15:04"), end.Format("15:04")
+	if start.Before(day) {
+		from = "…"
+	}
+	if end.After(next) {
+		until = "…"
+	}
+	if !end.After(start) {
+		return from
+	}
+	return from + "–" + until
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/reminders"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// pushKey handles GET /api/schedule/push/key, returning the VAPID public key
+// the browser needs to subscribe.
+func (h *handlers) pushKey(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, map[string]any{
+		"enabled":   h.cfg.PushEnabled(),
+		"publicKey": h.cfg.VAPIDPublicKey,
+	})
+}
+
+// pushSubscribe handles POST /api/schedule/push/subscriptions.
+//
+// The body is the browser PushSubscription JSON ({endpoint, keys: {p256dh,
+// auth}}). Registering a known endpoint again updates its keys and owner.
+func (h *handlers) pushSubscribe(e *core.RequestEvent) error {
+	var body struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256dh string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid subscription.", err)
+	}
+	if body.Endpoint == "" || body.Keys.P256dh == "" || body.Keys.Auth == "" {
+		return e.BadRequestError("endpoint, keys.p256dh and keys.auth are required.", nil)
+	}
+
+	status := http.StatusOK
+	rec, err := e.App.FindFirstRecordByData(reminders.PushSubscriptionsCollection, "endpoint", body.Endpoint)
+	if err != nil {
+		collection, err := e.App.FindCollectionByNameOrId(reminders.PushSubscriptionsCollection)
+		if err != nil {
+			return e.InternalServerError("Failed to load push subscriptions.", err)
+		}
+		rec = core.NewRecord(collection)
+		rec.Set("endpoint", body.Endpoint)
+		status = http.StatusCreated
+	}
+
+	rec.Set("user", e.Auth.Id)
+	rec.Set("p256dh", body.Keys.P256dh)
+	rec.Set("auth", body.Keys.Auth)
+	if err := e.App.Save(rec); err != nil {
+		return e.BadRequestError("Failed to save the subscription.", err)
+	}
+
+	return e.JSON(status, rec)
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/core"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// defaultQRSize, minQRSize and maxQRSize bound ?size of /qr.png, in pixels.
+const (
+	defaultQRSize = 256
+	minQRSize     = 64
+	maxQRSize     = 1024
+)
+
+// eventQR handles GET /api/schedule/events/{id}/qr.png?size=.
+//
+// A PNG QR code of the event's short link (see eventLink), size pixels
+// square (default 256), for posters and flyers. Gated like /link.
+func (h *handlers) eventQR(e *core.RequestEvent) error {
+	size := defaultQRSize
+	if v := e.Request.URL.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < minQRSize || n > maxQRSize {
+			return e.BadRequestError("size must be between "+strconv.Itoa(minQRSize)+" and "+strconv.Itoa(maxQRSize)+".", err)
+		}
+		size = n
+	}
+
+	ev, err := findLinkableEvent(e)
+	if err != nil {
+		return err
+	}
+	link, err := shortLink(e.App, ev.Id)
+	if err != nil {
+		return e.InternalServerError("Failed to create the short link.", err)
+	}
+
+	png, err := qrcode.Encode(shortURL(baseURL(e), link.GetString("code")), qrcode.Medium, size)
+	if err != nil {
+		return e.InternalServerError("Failed to encode the QR code.", err)
+	}
+	e.Response.Header().Set("Cache-Control", "private, max-age=86400")
+	return e.Blob(http.StatusOK, "image/png", png)
+}
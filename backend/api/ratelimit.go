@@ -0,0 +1,49 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a small in-memory sliding window limiter keyed by arbitrary
+// strings (emails, IPs). State is per process and lost on restart.
+type rateLimiter struct {
+	max    int
+	window time.Duration
+
+	mu   sync.Mutex
+	hits map[string][]time.Time
+}
+
+func newRateLimiter(max int, window time.Duration) *rateLimiter {
+	return &rateLimiter{max: max, window: window, hits: map[string][]time.Time{}}
+}
+
+// allow records a hit for key and reports whether it is within the limit.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// keep the map bounded when many distinct keys come by
+	if len(l.hits) > 10000 {
+		for k, times := range l.hits {
+			if len(times) == 0 || now.Sub(times[len(times)-1]) > l.window {
+				delete(l.hits, k)
+			}
+		}
+	}
+
+	times := l.hits[key]
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > l.window {
+		i++
+	}
+	times = times[i:]
+
+	if len(times) >= l.max {
+		l.hits[key] = times
+		return false
+	}
+	l.hits[key] = append(times, now)
+	return true
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// reanchorSeries handles POST /api/schedule/events/{id}/reanchor.
+//
+// Body: {"start": "...", "clearExdates": bool}. Moves a series to a new first
+// start, in the event timezone (else ?timezone), keeping its duration and
+// rule (see calendar.Reanchor). Exdates and the recurrence ids of detached
+// occurrences move along, unless clearExdates drops the plain exdates.
+// Returns the saved event. App users can only change their own events.
+func (h *handlers) reanchorSeries(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var body struct {
+		Start        string `json:"start"`
+		ClearExdates bool   `json:"clearExdates"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	ev := calendar.EventFromRecord(rec)
+	start, err := eventTime(ev, body.Start, ev.Zone(loc))
+	if err != nil {
+		return e.BadRequestError("Invalid start.", err)
+	}
+	detached, err := calendar.FindDetached(e.App, ev.ID)
+	if err != nil {
+		return e.InternalServerError("Failed to load detached occurrences.", err)
+	}
+	moved, err := calendar.Reanchor(ev, detached, start, body.ClearExdates, loc)
+	if err != nil {
+		return e.BadRequestError("Invalid start: "+err.Error()+".", err)
+	}
+
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		exdates := make([]string, len(moved.Exdates))
+		for i, x := range moved.Exdates {
+			exdates[i] = calendar.ISO(x)
+		}
+		rec.Set("start", moved.Start)
+		rec.Set("end", moved.End)
+		rec.Set("exdates", exdates)
+		calendar.SetChangedBy(rec, e.Auth)
+		if err := txApp.Save(rec); err != nil {
+			return err
+		}
+		for id, recurrenceID := range moved.RecurrenceIDs {
+			d, err := txApp.FindRecordById(calendar.EventsCollection, id)
+			if err != nil {
+				return err
+			}
+			d.Set("recurrenceId", recurrenceID)
+			calendar.SetChangedBy(d, e.Auth)
+			if err := txApp.Save(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to move the series.", err)
+	}
+
+	return e.JSON(http.StatusOK, rec)
+}
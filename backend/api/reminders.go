@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+	"schedule/reminders"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// dismissReminder handles POST /api/schedule/events/{id}/dismiss-reminder.
+//
+// Body: {"start": "<occurrence start>"}, optional for single events.
+// Acknowledges the reminder of that occurrence so no escalation follow-ups
+// are sent (the start is the one in the reminder push payload). App users
+// can only dismiss reminders of their own events.
+func (h *handlers) dismissReminder(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	ev := calendar.EventFromRecord(rec)
+
+	var body struct {
+		Start string `json:"start"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	start := ev.Start
+	if body.Start != "" || ev.IsRecurring() {
+		if start, err = calendar.ParseTime(body.Start, time.UTC); err != nil {
+			return e.BadRequestError("Invalid start.", err)
+		}
+	}
+	found := false
+	for _, o := range ev.Occurrences(start, start.Add(time.Second), reminders.OwnerZone(e.App, h.cfg, ev.Owner)) {
+		found = found || o.Start.Equal(start)
+	}
+	if !found {
+		return e.BadRequestError("No occurrence of the event starts at start.", nil)
+	}
+
+	if err := reminders.Dismiss(e.App, ev.ID, start); err != nil {
+		return e.InternalServerError("Failed to dismiss the reminder.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":     ev.ID,
+		"start":     start.UTC(),
+		"dismissed": true,
+	})
+}
+
+// upcomingReminders handles GET /api/schedule/reminders/upcoming?from=&to=.
+//
+// Lists the reminders that will fire in the window (see reminders.Upcoming),
+// so users can check their setup before the first one goes out. App users
+// see their own events; superusers everyone's, or ?owner's. Dismissed
+// reminders stop their follow-ups; snoozing and reminding only the next
+// occurrence of a series are not supported.
+func (h *handlers) upcomingReminders(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	from, to, err := h.dateRange(e, "from", "to", loc)
+	if err != nil {
+		return err
+	}
+
+	owner := e.Request.URL.Query().Get("owner")
+	if !e.HasSuperuserAuth() {
+		owner = e.Auth.Id
+	}
+
+	items, err := reminders.Upcoming(e.App, h.cfg, owner, from, to)
+	if err != nil {
+		return e.InternalServerError("Failed to list reminders.", err)
+	}
+	if items == nil {
+		items = []reminders.Scheduled{}
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":  from,
+		"to":    to,
+		"items": items,
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestDismissReminderInOwnerZone(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		f.owner.Set("timezone", "Europe/Berlin")
+		if err := app.Save(f.owner); err != nil {
+			t.Fatal(err)
+		}
+		// 09:00 wall clock, and 09:00 in Berlin from before summer time
+		f.event(app, "gym", map[string]any{
+			"title": "Gym", "start": at(9, 0), "end": at(10, 0), "floating": true, "rrule": "FREQ=WEEKLY", "owner": f.owner.Id,
+		})
+		f.event(app, "standup", map[string]any{
+			"title": "Standup", "start": "2026-03-27 08:00:00.000Z", "end": "2026-03-27 08:15:00.000Z", "rrule": "FREQ=DAILY", "owner": f.owner.Id,
+		})
+	})
+	dismiss := func(name string) string {
+		return "/api/schedule/events/" + f.records[name] + "/dismiss-reminder"
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "floating occurrence at its Berlin instant",
+			URL:             dismiss("gym"),
+			Body:            strings.NewReader(`{"start":"2026-03-17T08:00:00.000Z"}`),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"dismissed":true`, `"start":"2026-03-17T08:00:00Z"`},
+		},
+		{
+			Name:            "floating occurrence at its UTC reading",
+			URL:             dismiss("gym"),
+			Body:            strings.NewReader(`{"start":"2026-03-17T09:00:00.000Z"}`),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"message":"No occurrence of the event starts at start."`},
+		},
+		{
+			Name:            "series occurrence after the switch to summer time",
+			URL:             dismiss("standup"),
+			Body:            strings.NewReader(`{"start":"2026-03-30T07:00:00.000Z"}`),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"dismissed":true`, `"start":"2026-03-30T07:00:00Z"`},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodPost
+		s.Headers = f.auth(f.owner)
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+	"schedule/recur"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// rruleCount handles POST /api/schedule/rrule/count.
+//
+// Body: {"start", "rrule", "timezone", "from", "to", "exdates"}. Returns the
+// number of instances of the rule starting in [from, to), without the
+// occurrence objects, for badges and summaries. A window longer than the
+// horizon is cut to it ("truncated": true).
+func (h *handlers) rruleCount(e *core.RequestEvent) error {
+	var body struct {
+		Start    string   `json:"start"`
+		RRule    string   `json:"rrule"`
+		Timezone string   `json:"timezone"`
+		From     string   `json:"from"`
+		To       string   `json:"to"`
+		Exdates  []string `json:"exdates"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+
+	errs := validation.Errors{}
+	loc := time.UTC
+	if body.Timezone != "" {
+		l, err := time.LoadLocation(body.Timezone)
+		if err != nil {
+			errs["timezone"] = validation.NewError("validation_invalid_timezone", "Unknown time zone.")
+		} else {
+			loc = l
+		}
+	}
+	if _, err := recur.Parse(body.RRule); err != nil || body.RRule == "" {
+		errs["rrule"] = validation.NewError("validation_invalid_rrule", "Invalid recurrence rule.")
+	}
+	parse := func(field, v string) time.Time {
+		if v == "" {
+			errs[field] = validation.NewError("validation_required", "Missing "+field+".")
+			return time.Time{}
+		}
+		t, err := parseWallClock(v, loc)
+		if err != nil {
+			errs[field] = validation.NewError("validation_invalid_date", "Invalid date.")
+		}
+		return t
+	}
+	start, from, to := parse("start", body.Start), parse("from", body.From), parse("to", body.To)
+	exdates := make([]time.Time, 0, len(body.Exdates))
+	for i, v := range body.Exdates {
+		exdates = append(exdates, parse(fmt.Sprintf("exdates.%d", i), v).UTC())
+	}
+	if len(errs) == 0 && to.Before(from) {
+		errs["to"] = validation.NewError("validation_range_order", "Must not be before from.")
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid recurrence.", errs)
+	}
+
+	truncated := false
+	if to.Sub(from) > h.cfg.Horizon {
+		to, truncated = from.Add(h.cfg.Horizon), true
+	}
+
+	ev := &calendar.Event{
+		Start:    start,
+		End:      start,
+		RRule:    body.RRule,
+		Exdates:  exdates,
+		Timezone: body.Timezone,
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":      from,
+		"to":        to,
+		"timezone":  loc.String(),
+		"count":     ev.CountOccurrences(from, to, loc),
+		"truncated": truncated,
+	})
+}
+
+// rruleValidate handles GET /api/schedule/rrule/validate?rrule=.
+//
+// Checks a rule the way saving an event does (see calendar.ValidateRRule), for
+// inline validation in the recurrence builder: {valid, error} for a rule a
+// save would refuse, {valid, humanReadable} with its English description
+// otherwise.
+func (h *handlers) rruleValidate(e *core.RequestEvent) error {
+	rule, err := calendar.ValidateRRule(e.Request.URL.Query().Get("rrule"))
+	if err != nil {
+		return e.JSON(http.StatusOK, map[string]any{
+			"valid": false,
+			"error": err.Error(),
+		})
+	}
+	return e.JSON(http.StatusOK, map[string]any{
+		"valid":         true,
+		"humanReadable": rule.Describe(),
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRRuleValidate(t *testing.T) {
+	f := newFixture(t, nil)
+
+	validate := func(rrule string) string {
+		return "/api/schedule/rrule/validate?rrule=" + url.QueryEscape(rrule)
+	}
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "guest",
+			Method:          http.MethodGet,
+			URL:             validate("FREQ=DAILY"),
+			TestAppFactory:  f.factory,
+			ExpectedStatus:  http.StatusUnauthorized,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	cases := []struct {
+		name, rrule string
+		want        []string
+	}{
+		{
+			name:  "every weekday",
+			rrule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+			want:  []string{`{"humanReadable":"Every weekday","valid":true}`},
+		},
+		{
+			name:  "last day of the month",
+			rrule: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12",
+			want:  []string{`{"humanReadable":"Every month on the last day, 12 times","valid":true}`},
+		},
+		{
+			name:  "with an RRULE prefix",
+			rrule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+			want:  []string{`{"humanReadable":"Every 2 weeks on Tuesday","valid":true}`},
+		},
+		{
+			name:  "empty",
+			rrule: "",
+			want:  []string{`"valid":false`, `"error":"Invalid recurrence rule:`},
+		},
+		{
+			name:  "unknown frequency",
+			rrule: "FREQ=FORTNIGHTLY",
+			want:  []string{`"valid":false`, `"error":"Invalid recurrence rule:`},
+		},
+		{
+			name:  "out of range month day",
+			rrule: "FREQ=MONTHLY;BYMONTHDAY=32",
+			want:  []string{`"valid":false`, `"error":"Invalid recurrence rule: invalid BYMONTHDAY \"32\"."`},
+		},
+		{
+			// refused by the expansion guard, as a save would be
+			name:  "open-ended minutely",
+			rrule: "FREQ=MINUTELY",
+			want:  []string{`"valid":false`, `"error":"Invalid recurrence rule:`},
+		},
+		{
+			name:  "COUNT and UNTIL",
+			rrule: "FREQ=DAILY;COUNT=3;UNTIL=20261231T000000Z",
+			want:  []string{`{"error":"A recurrence rule can't have both COUNT and UNTIL.","valid":false}`},
+		},
+	}
+	for _, c := range cases {
+		scenarios = append(scenarios, tests.ApiScenario{
+			Name:            c.name,
+			Method:          http.MethodGet,
+			URL:             validate(c.rrule),
+			Headers:         f.auth(f.owner),
+			TestAppFactory:  f.factory,
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: c.want,
+		})
+	}
+	for _, s := range scenarios {
+		s.Test(t)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// rsvp handles POST /api/schedule/events/{id}/rsvp.
+//
+// Body: {"status": "accepted" | "declined" | "tentative"}. Answers the
+// invitation of the authenticated user (matched by email). Clients sending
+// Accept: text/calendar get an iMIP METHOD:REPLY .ics instead of the JSON
+// attendee, ready to mail back to the organizer.
+func (h *handlers) rsvp(e *core.RequestEvent) error {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	status, ok := calendar.ParsePartStat(body.Status)
+	if !ok {
+		return e.BadRequestError("status must be accepted, declined or tentative.", nil)
+	}
+
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	invite, err := calendar.FindAttendeeRecord(e.App, rec.Id, e.Auth.Email())
+	if err != nil {
+		return e.NotFoundError("You are not invited to this event.", err)
+	}
+	invite.Set("status", status)
+	if invite.GetString("user") == "" {
+		invite.Set("user", e.Auth.Id)
+	}
+	if err := e.App.Save(invite); err != nil {
+		return e.InternalServerError("Failed to save the answer.", err)
+	}
+	attendee := calendar.AttendeeFromRecord(invite)
+
+	if !strings.Contains(e.Request.Header.Get("Accept"), "text/calendar") {
+		return e.JSON(http.StatusOK, attendee)
+	}
+
+	ev := calendar.EventFromRecord(rec)
+	uid := calendar.UIDOf(ev)
+	if ev.IsDetached() {
+		if parent, err := e.App.FindRecordById(calendar.EventsCollection, ev.SourceID); err == nil {
+			uid = calendar.UIDOf(calendar.EventFromRecord(parent))
+		}
+	}
+
+	var ownerEmail string
+	if ev.Owner != "" {
+		if owner, err := e.App.FindRecordById(calendar.UsersCollection, ev.Owner); err == nil {
+			ownerEmail = owner.Email()
+		}
+	}
+	organizer := calendar.OrganizerOf(ev, ownerEmail)
+
+	e.Response.Header().Set("Content-Type", "text/calendar; method=REPLY; charset=utf-8")
+	e.Response.WriteHeader(http.StatusOK)
+	return calendar.ReplyICS(e.Response, ev, uid, organizer, attendee)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// seriesOccurrence is one /events/{id}/occurrences item. Detached ones are
+// the overrides of the instance starting at RecurrenceID.
+type seriesOccurrence struct {
+	calendar.Occurrence
+	Detached     bool       `json:"detached"`
+	RecurrenceID *time.Time `json:"recurrenceId,omitempty"`
+}
+
+// seriesOccurrences handles GET /api/schedule/events/{id}/occurrences?from=&to=&timezone=.
+//
+// Expands only the event {id} in [from, to): the instances of a series
+// (exdated and paused ones skipped) merged with its detached occurrences in
+// place of the instances they replace, sorted by start (RDATE isn't
+// supported, so there are no extra dates to merge). For a detached
+// occurrence the whole series is returned. remainingOccurrences counts the
+// occurrences of the series from now on, whatever the window; it is null for
+// open-ended series and single events. App users can only expand their own
+// events.
+func (h *handlers) seriesOccurrences(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	if sourceID := rec.GetString("sourceId"); sourceID != "" {
+		if rec, err = e.App.FindRecordById(calendar.EventsCollection, sourceID); err != nil {
+			return e.NotFoundError("Series not found.", err)
+		}
+	}
+	ev := calendar.EventFromRecord(rec)
+
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	from, to, err := h.dateRange(e, "from", "to", loc)
+	if err != nil {
+		return err
+	}
+
+	events := []*calendar.Event{ev}
+	var remaining *int
+	if ev.IsRecurring() {
+		detached, err := calendar.FindDetached(e.App, ev.ID)
+		if err != nil {
+			return e.InternalServerError("Failed to load the detached occurrences.", err)
+		}
+		events = append(events, detached...)
+		if n, ok := calendar.Remaining(ev, detached, time.Now(), loc); ok {
+			remaining = &n
+		}
+	}
+
+	byID := make(map[string]*calendar.Event, len(events))
+	for _, d := range events {
+		byID[d.ID] = d
+	}
+	items := []seriesOccurrence{}
+	for _, o := range h.expansions.Expand(events, from, to, loc) {
+		item := seriesOccurrence{Occurrence: o}
+		if d := byID[o.ID]; d != nil && d.IsDetached() {
+			recurrenceID := d.RecurrenceID.UTC()
+			item.Detached, item.RecurrenceID = true, &recurrenceID
+		}
+		items = append(items, item)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":                ev.ID,
+		"from":                 from,
+		"to":                   to,
+		"remainingOccurrences": remaining,
+		"items":                items,
+	})
+}
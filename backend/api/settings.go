@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// settings handles GET /api/schedule/settings.
+//
+// Superusers only. Reports the effective SCHEDULE_* configuration, without the
+// VAPID private key.
+func (h *handlers) settings(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, h.settingsMap())
+}
+
+// settingsMap describes the effective configuration, as served by /settings.
+func (h *handlers) settingsMap() map[string]any {
+	return map[string]any{
+		"weekStart":   int(h.cfg.WeekStart),
+		"timezone":    h.cfg.Timezone.String(),
+		"horizonDays": int(h.cfg.Horizon.Hours() / 24),
+		"pushEnabled": h.cfg.PushEnabled(),
+		"mail": map[string]any{
+			"rate":        h.cfg.MailRate,
+			"concurrency": h.cfg.MailConcurrency,
+			"maxAttempts": h.cfg.MailMaxAttempts,
+		},
+		"history": map[string]any{
+			"retentionDays": int(h.cfg.HistoryRetention.Hours() / 24),
+			"keep":          h.cfg.HistoryKeep,
+		},
+		"reminderGraceMinutes":  int(h.cfg.ReminderGrace / time.Minute),
+		"reminderWebhookSigned": h.cfg.ReminderWebhookSecret != "",
+		"defaults": map[string]any{
+			"durationMinutes": int(h.cfg.DefaultDuration / time.Minute),
+			"category":        h.cfg.DefaultCategory,
+			"color":           h.cfg.DefaultColor,
+		},
+		"cascadeDependencies": h.cfg.CascadeDependencies,
+		"workHours": map[string]any{
+			"start": formatClock(h.cfg.WorkStart),
+			"end":   formatClock(h.cfg.WorkEnd),
+			"days":  h.cfg.WorkDays,
+		},
+		"eventDuration": map[string]any{
+			"minMinutes": int(h.cfg.MinEventDuration / time.Minute),
+			"maxHours":   int(h.cfg.MaxEventDuration / time.Hour),
+		},
+		"maxExdates":       h.cfg.MaxExdates,
+		"eventsCollection": h.cfg.EventsCollection,
+		"basePath":         h.cfg.BasePath,
+	}
+}
+
+// formatClock spells an offset from midnight as HH:MM.
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// shareEvent handles POST /api/schedule/events/{id}/shares.
+//
+// Body: {"user": "<user id>", "permission": "read"|"write"}. Shares one event
+// with another user without their seeing the rest of the calendar: read lets
+// them view it through the events records API, write also update it. The
+// permission defaults to read, sharing again replaces it. Only superusers and
+// the owner can share an event.
+func (h *handlers) shareEvent(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	var body struct {
+		User       string `json:"user"`
+		Permission string `json:"permission"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	permission := strings.ToLower(strings.TrimSpace(body.Permission))
+	if permission == "" {
+		permission = calendar.SharePermissionRead
+	}
+	if !slices.Contains(calendar.SharePermissions, permission) {
+		return e.BadRequestError("Invalid body.", validation.Errors{"permission": validation.NewError("validation_invalid_permission",
+			"Must be one of "+strings.Join(calendar.SharePermissions, ", ")+".")})
+	}
+	if body.User == "" {
+		return e.BadRequestError("Invalid body.", validation.Errors{"user": validation.NewError("validation_required", "Cannot be blank.")})
+	}
+	user, err := e.App.FindRecordById(calendar.UsersCollection, body.User)
+	if err != nil {
+		return e.BadRequestError("Invalid body.", validation.Errors{"user": validation.NewError("validation_unknown_user", "No such user.")})
+	}
+	if rec.GetString("owner") == user.Id {
+		return e.BadRequestError("The event already belongs to the user.", nil)
+	}
+
+	share, err := calendar.ShareEvent(e.App, rec, user, permission)
+	if err != nil {
+		return e.BadRequestError("Failed to share the event.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"id":         share.Id,
+		"event":      rec.Id,
+		"user":       user.Id,
+		"permission": permission,
+	})
+}
+
+// unshareEvent handles DELETE /api/schedule/events/{id}/shares/{user}.
+//
+// Revokes the share of the event with the user. Superusers and the owner can
+// revoke any share, users can give up the ones they were granted.
+func (h *handlers) unshareEvent(e *core.RequestEvent) error {
+	userID := e.Request.PathValue("user")
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id && userID != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+
+	revoked, err := calendar.UnshareEvent(e.App, rec.Id, userID)
+	if err != nil {
+		return e.InternalServerError("Failed to revoke the share.", err)
+	}
+	if !revoked {
+		return e.NotFoundError("The event isn't shared with this user.", nil)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestEventShares(t *testing.T) {
+	var reader, writer, stranger *core.Record
+	f := newFixture(t, func(app core.App, f *fixture) {
+		reader = f.other
+		writer = f.user(app, "writer@example.com")
+		stranger = f.user(app, "stranger@example.com")
+		ev := f.event(app, "planning", map[string]any{"title": "Planning", "start": at(10, 0), "end": at(11, 0), "owner": f.owner.Id})
+		if _, err := calendar.ShareEvent(app, ev, reader, calendar.SharePermissionRead); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := calendar.ShareEvent(app, ev, writer, calendar.SharePermissionWrite); err != nil {
+			t.Fatal(err)
+		}
+	})
+	occurrences := "/api/schedule/occurrences?start=2026-03-10&end=2026-03-11&timezone=UTC"
+	record := "/api/collections/" + calendar.EventsCollection + "/records/" + f.records["planning"]
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "read share lists the occurrence",
+			Method:          http.MethodGet,
+			URL:             occurrences,
+			Headers:         f.auth(reader),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"title":"Planning"`},
+		},
+		{
+			Name:               "no share, no occurrence",
+			Method:             http.MethodGet,
+			URL:                occurrences,
+			Headers:            f.auth(stranger),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"items":[]`},
+			NotExpectedContent: []string{`"title":"Planning"`},
+		},
+		{
+			Name:            "no share, no event view",
+			Method:          http.MethodGet,
+			URL:             "/api/schedule/events/" + f.records["planning"],
+			Headers:         f.auth(stranger),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "read share can't update",
+			Method:          http.MethodPatch,
+			URL:             record,
+			Body:            strings.NewReader(`{"title":"Renamed"}`),
+			Headers:         f.auth(reader),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "write share updates",
+			Method:          http.MethodPatch,
+			URL:             record,
+			Body:            strings.NewReader(`{"title":"Renamed"}`),
+			Headers:         f.auth(writer),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"title":"Renamed"`},
+			ExpectedEvents:  map[string]int{"OnRecordUpdate": 1},
+		},
+		{
+			Name:            "write share can't move calendars",
+			Method:          http.MethodPatch,
+			URL:             record,
+			Body:            strings.NewReader(`{"calendar":""}`),
+			Headers:         f.auth(writer),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
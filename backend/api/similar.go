@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// defaultSimilarWindow and maxSimilarWindow bound ?windowHours of
+	// /events/similar, in hours on either side of around.
+	defaultSimilarWindow = 24
+	maxSimilarWindow     = 24 * 31
+
+	// defaultMinSimilarity is the ?minScore below which titles don't count as
+	// similar.
+	defaultMinSimilarity = 0.6
+)
+
+// similarEvent is one /events/similar match: the event (the series for
+// recurring ones) and its occurrence closest to around.
+type similarEvent struct {
+	Event string              `json:"event"`
+	Score float64             `json:"score"`
+	Item  calendar.Occurrence `json:"occurrence"`
+}
+
+// similarEvents handles GET /api/schedule/events/similar?title=&around=&windowHours=&minScore=.
+//
+// Lists the visible events occurring within windowHours (default 24) of
+// around whose title scores at least minScore (default 0.6) on
+// calendar.TitleSimilarity, best first, e.g. to warn about a likely duplicate
+// before creating an event.
+func (h *handlers) similarEvents(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	q := e.Request.URL.Query()
+	errs := validation.Errors{}
+	title := strings.TrimSpace(q.Get("title"))
+	if title == "" {
+		errs["title"] = validation.NewError("validation_required", "Cannot be blank.")
+	}
+	around, err := calendar.ParseTime(q.Get("around"), loc)
+	if err != nil {
+		errs["around"] = validation.NewError("validation_invalid_date", "Must be an RFC 3339 or YYYY-MM-DD date.")
+	}
+	hours := defaultSimilarWindow
+	if v := q.Get("windowHours"); v != "" {
+		if hours, err = strconv.Atoi(v); err != nil || hours < 1 || hours > maxSimilarWindow {
+			errs["windowHours"] = validation.NewError("validation_out_of_range", "Must be between 1 and "+strconv.Itoa(maxSimilarWindow)+".")
+		}
+	}
+	minScore := defaultMinSimilarity
+	if v := q.Get("minScore"); v != "" {
+		if minScore, err = strconv.ParseFloat(v, 64); err != nil || minScore < 0 || minScore > 1 {
+			errs["minScore"] = validation.NewError("validation_out_of_range", "Must be between 0 and 1.")
+		}
+	}
+	if len(errs) > 0 {
+		return e.BadRequestError("Invalid parameters.", errs)
+	}
+
+	window := time.Duration(hours) * time.Hour
+	items, err := h.expand(e, around.Add(-window), around.Add(window), loc)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	best := map[string]*similarEvent{}
+	for _, o := range items {
+		score := calendar.TitleSimilarity(title, o.Title)
+		if score < minScore {
+			continue
+		}
+		id := o.ID
+		if o.RRule != "" {
+			id = o.SourceID // instances of a series are one event
+		}
+		if m, ok := best[id]; !ok || o.Start.Sub(around).Abs() < m.Item.Start.Sub(around).Abs() {
+			best[id] = &similarEvent{Event: id, Score: score, Item: o}
+		}
+	}
+
+	matches := make([]similarEvent, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, *m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if da, db := a.Item.Start.Sub(around).Abs(), b.Item.Start.Sub(around).Abs(); da != db {
+			return da < db
+		}
+		return a.Event < b.Event
+	})
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"title":       title,
+		"around":      around.UTC(),
+		"windowHours": hours,
+		"items":       matches,
+	})
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// splitEditBody is the JSON body of a "this and future" series edit. at is the
+// start of the occurrence the new series begins with; the other fields are
+// optional changes.
+type splitEditBody struct {
+	At       string  `json:"at"`
+	Title    *string `json:"title"`
+	Start    *string `json:"start"`
+	End      *string `json:"end"`
+	AllDay   *bool   `json:"allDay"`
+	RRule    *string `json:"rrule"`
+	Location *string `json:"location"`
+	Notes    *string `json:"notes"`
+	Category *string `json:"category"`
+	Color    *string `json:"color"`
+}
+
+// splitEdit parses the request body into a calendar.SplitEdit.
+func splitEdit(e *core.RequestEvent) (calendar.SplitEdit, error) {
+	var body splitEditBody
+	if err := e.BindBody(&body); err != nil {
+		return calendar.SplitEdit{}, err
+	}
+
+	edit := calendar.SplitEdit{
+		Title:    body.Title,
+		AllDay:   body.AllDay,
+		RRule:    body.RRule,
+		Location: body.Location,
+		Notes:    body.Notes,
+		Category: body.Category,
+		Color:    body.Color,
+	}
+
+	var err error
+	if edit.At, err = calendar.ParseTime(body.At, time.UTC); err != nil {
+		return edit, err
+	}
+	for _, f := range []struct {
+		src *string
+		dst **time.Time
+	}{{body.Start, &edit.Start}, {body.End, &edit.End}} {
+		if f.src == nil {
+			continue
+		}
+		t, err := calendar.ParseTime(*f.src, time.UTC)
+		if err != nil {
+			return edit, err
+		}
+		*f.dst = &t
+	}
+	return edit, nil
+}
+
+// editPreview handles POST /api/schedule/events/{id}/edit-preview.
+//
+// Returns what a "this and future" edit would do to the series: the
+// truncated rule, the removed and added occurrences, the new series fields and
+// the fate of existing detached occurrences. Series without a zone are
+// expanded in ?timezone. Nothing is saved. App users can only preview edits
+// of their own events.
+func (h *handlers) editPreview(e *core.RequestEvent) error {
+	_, plan, err := h.planSplit(e)
+	if err != nil {
+		return err
+	}
+	return e.JSON(http.StatusOK, plan)
+}
+
+// splitSeries handles POST /api/schedule/events/{id}/split.
+//
+// Applies the "this and future" edit /edit-preview describes, in one
+// transaction: the original series is truncated before at (deleted when at
+// is its first occurrence), the new series is created with the fields of the
+// original plus the changes, and the detached occurrences from at on are
+// relinked to it or deleted. Returns {id, event} of the new series. App users
+// can only split their own events.
+func (h *handlers) splitSeries(e *core.RequestEvent) error {
+	src, plan, err := h.planSplit(e)
+	if err != nil {
+		return err
+	}
+	at := plan.At
+	delta := plan.NewSeries.Start.Sub(at)
+
+	var next *core.Record
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		next = core.NewRecord(src.Collection())
+		for name, value := range src.FieldsData() {
+			if !slices.Contains(cloneSkipFields, name) && name != "sequence" {
+				next.Set(name, value)
+			}
+		}
+		p := plan.NewSeries
+		exdates := make([]string, len(p.Exdates))
+		for i, x := range p.Exdates {
+			exdates[i] = calendar.ISO(x)
+		}
+		next.Load(map[string]any{
+			"title": p.Title, "start": p.Start, "end": p.End, "allDay": p.AllDay, "rrule": p.RRule,
+			"exdates": exdates, "location": p.Location, "notes": p.Notes, "category": p.Category, "color": p.Color,
+		})
+		calendar.SetChangedBy(next, e.Auth)
+		if err := txApp.Save(next); err != nil {
+			return err
+		}
+
+		for _, d := range plan.Detached {
+			rec, err := txApp.FindRecordById(calendar.EventsCollection, d.ID)
+			if err != nil {
+				return err
+			}
+			if d.Action != "relink" {
+				if err := txApp.Delete(rec); err != nil {
+					return err
+				}
+				continue
+			}
+			rec.Set("sourceId", next.Id)
+			rec.Set("recurrenceId", d.RecurrenceID.Add(delta))
+			calendar.SetChangedBy(rec, e.Auth)
+			if err := txApp.Save(rec); err != nil {
+				return err
+			}
+		}
+
+		if plan.OldDeleted {
+			return txApp.Delete(src)
+		}
+		kept := []string{}
+		for _, x := range calendar.EventFromRecord(src).Exdates {
+			if x.Before(at) {
+				kept = append(kept, calendar.ISO(x))
+			}
+		}
+		src.Set("rrule", plan.OldRRule)
+		src.Set("exdates", kept)
+		calendar.SetChangedBy(src, e.Auth)
+		return txApp.Save(src)
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to split the series.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"id":    next.Id,
+		"event": next,
+	})
+}
+
+// planSplit loads the {id} series, owned by the caller, and plans the edit of
+// the request body, in ?timezone for series without a zone. Errors are error
+// responses.
+func (h *handlers) planSplit(e *core.RequestEvent) (*core.Record, *calendar.SplitPlan, error) {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return nil, nil, e.NotFoundError("Event not found.", err)
+	}
+
+	edit, err := splitEdit(e)
+	if err != nil {
+		return nil, nil, e.BadRequestError("Invalid edit.", err)
+	}
+
+	loc, err := h.location(e)
+	if err != nil {
+		return nil, nil, e.BadRequestError("Invalid timezone.", err)
+	}
+
+	detached, err := calendar.FindDetached(e.App, rec.Id)
+	if err != nil {
+		return nil, nil, e.InternalServerError("Failed to load detached occurrences.", err)
+	}
+
+	plan, err := calendar.PlanSplit(calendar.EventFromRecord(rec), detached, edit, h.cfg.Horizon, loc)
+	if err != nil {
+		return nil, nil, e.BadRequestError("Invalid edit: "+err.Error()+".", err)
+	}
+	return rec, plan, nil
+}
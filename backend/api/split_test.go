@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSplitSeries(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		series := f.event(app, "weekly", map[string]any{
+			"title": "Weekly", "start": at(9, 0), "end": at(10, 0), "owner": f.owner.Id,
+			"rrule": "FREQ=WEEKLY;COUNT=6",
+		})
+		// the fourth instance, moved an hour later
+		f.event(app, "moved", map[string]any{
+			"title": "Weekly (moved)", "start": atDay(21, 10, 0),
+			"end": atDay(21, 11, 0), "owner": f.owner.Id,
+			"sourceId": series.Id, "recurrenceId": atDay(21, 9, 0),
+		})
+	})
+	third := atDay(14, 9, 0)
+	body := `{"at":"` + third + `","title":"Weekly v2"}`
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "preview of another user's series",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/edit-preview",
+			Body:            strings.NewReader(body),
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "split of another user's series",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/split",
+			Body:            strings.NewReader(body),
+			Headers:         f.auth(f.other),
+			ExpectedStatus:  http.StatusNotFound,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "preview saves nothing",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/edit-preview",
+			Body:            strings.NewReader(body),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"addedCount":4`, `"removedCount":4`, `"action":"relink"`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if n, _ := app.CountRecords(calendar.EventsCollection); n != 2 {
+					t.Fatalf("expected the 2 fixture events, got %d", n)
+				}
+			},
+		},
+		{
+			Name:            "split",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/split",
+			Body:            strings.NewReader(body),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"title":"Weekly v2"`, `"rrule":"FREQ=WEEKLY;COUNT=4"`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				old, err := app.FindRecordById(calendar.EventsCollection, f.records["weekly"])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := calendar.EventFromRecord(old).Occurrences(fixtureDay, fixtureDay.AddDate(1, 0, 0), nil); len(got) != 2 {
+					t.Fatalf("expected the original series to keep 2 occurrences, got %d", len(got))
+				}
+				next, err := app.FindFirstRecordByFilter(calendar.EventsCollection, "title = 'Weekly v2'")
+				if err != nil {
+					t.Fatal(err)
+				}
+				moved, err := app.FindRecordById(calendar.EventsCollection, f.records["moved"])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if moved.GetString("sourceId") != next.Id {
+					t.Fatalf("expected the detached occurrence to follow the new series, got sourceId %q", moved.GetString("sourceId"))
+				}
+				if n, _ := app.CountRecords(calendar.EventsCollection, dbx.HashExp{"owner": f.owner.Id}); n != 3 {
+					t.Fatalf("expected 3 events, got %d", n)
+				}
+			},
+		},
+		{
+			// 09:00 UTC is 10:00 in Berlin, which stays the wall clock past the
+			// DST change: the fourth instance is at 08:00 UTC
+			Name:            "preview in the request zone",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/edit-preview?timezone=Europe/Berlin",
+			Body:            strings.NewReader(`{"at":"` + atDay(21, 8, 0) + `"}`),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"removedCount":3`, `"newSeries":{"title":"Weekly","start":"2026-03-31T08:00:00Z"`, `"rrule":"FREQ=WEEKLY;COUNT=3"`},
+		},
+		{
+			Name:            "invalid timezone",
+			Method:          http.MethodPost,
+			URL:             "/api/schedule/events/" + f.records["weekly"] + "/edit-preview?timezone=Mars/Olympus",
+			Body:            strings.NewReader(body),
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"message":"Invalid timezone."`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
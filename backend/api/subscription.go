@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"schedule/calendar"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const (
+	// icsTokenScope is the "scope" claim of ICS subscription tokens.
+	icsTokenScope = "ics"
+
+	// icsTokenDuration is effectively "until revoked": calendar apps keep a
+	// subscription URL for years, revocation goes through icsTokenVersion.
+	icsTokenDuration = 10 * 365 * 24 * time.Hour
+)
+
+// icsSigningKey derives the subscription signing key from the users
+// collection auth secret, so it never equals the key of regular auth tokens.
+func icsSigningKey(app core.App) (string, error) {
+	users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		return "", err
+	}
+	return users.AuthToken.Secret + "." + icsTokenScope, nil
+}
+
+// newICSToken signs a subscription token for the user's current token version.
+func newICSToken(app core.App, user *core.Record) (string, error) {
+	key, err := icsSigningKey(app)
+	if err != nil {
+		return "", err
+	}
+	return security.NewJWT(jwt.MapClaims{
+		"id":    user.Id,
+		"scope": icsTokenScope,
+		"ver":   user.GetInt("icsTokenVersion"),
+	}, key, icsTokenDuration)
+}
+
+// userFromICSToken validates a subscription token and returns its user. Tokens
+// issued before the last rotation are rejected.
+func userFromICSToken(app core.App, token string) (*core.Record, error) {
+	key, err := icsSigningKey(app)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := security.ParseJWT(token, key)
+	if err != nil {
+		return nil, err
+	}
+	if scope, _ := claims["scope"].(string); scope != icsTokenScope {
+		return nil, errors.New("invalid token scope")
+	}
+
+	id, _ := claims["id"].(string)
+	user, err := app.FindRecordById(calendar.UsersCollection, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ver, _ := claims["ver"].(float64)
+	if int(ver) != user.GetInt("icsTokenVersion") {
+		return nil, errors.New("token revoked")
+	}
+
+	return user, nil
+}
+
+// subscriptionURL builds the public feed URL for a token.
+func subscriptionURL(e *core.RequestEvent, token string) string {
+	return baseURL(e) + "/api/schedule/ics?token=" + url.QueryEscape(token)
+}
+
+// icsFeed handles GET /api/schedule/ics?token=.
+//
+// Public route: the signed token identifies the user whose events are returned,
+// optionally limited with ?calendar.
+func (h *handlers) icsFeed(e *core.RequestEvent) error {
+	user, err := userFromICSToken(e.App, e.Request.URL.Query().Get("token"))
+	if err != nil {
+		return e.UnauthorizedError("Invalid or revoked subscription token.", nil)
+	}
+
+	events, err := calendar.FindOwnedEvents(e.App, user.Id)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	return h.writeICS(e, user.Id, calendar.InCalendars(events, h.calendarIDs(e)), "Schedule")
+}
+
+// icsToken handles POST /api/schedule/ics/token, returning the current
+// subscription URL of the authenticated user.
+func (h *handlers) icsToken(e *core.RequestEvent) error {
+	token, err := newICSToken(e.App, e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to sign the subscription token.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"token": token,
+		"url":   subscriptionURL(e, token),
+	})
+}
+
+// icsRotate handles POST /api/schedule/ics/rotate. Bumping the token version
+// revokes every previously issued subscription URL; a fresh one is returned.
+func (h *handlers) icsRotate(e *core.RequestEvent) error {
+	user, err := e.App.FindRecordById(calendar.UsersCollection, e.Auth.Id)
+	if err != nil {
+		return e.NotFoundError("User not found.", err)
+	}
+
+	user.Set("icsTokenVersion", user.GetInt("icsTokenVersion")+1)
+	if err := e.App.Save(user); err != nil {
+		return e.InternalServerError("Failed to rotate the subscription token.", err)
+	}
+
+	token, err := newICSToken(e.App, user)
+	if err != nil {
+		return e.InternalServerError("Failed to sign the subscription token.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"token": token,
+		"url":   subscriptionURL(e, token),
+	})
+}
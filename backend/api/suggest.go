@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxSuggestions caps each ranked list of /suggest.
+const maxSuggestions = 5
+
+// suggest handles POST /api/schedule/suggest.
+//
+// Body: {"title": "..."}. Returns ranked category and tag suggestions learned
+// from the titles of the user's own events (every event for superusers). The
+// model is rebuilt per request, so it follows the history without any state.
+func (h *handlers) suggest(e *core.RequestEvent) error {
+	var body struct {
+		Title string `json:"title"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	if strings.TrimSpace(body.Title) == "" {
+		return e.BadRequestError("title is required.", nil)
+	}
+
+	var history []*calendar.Event
+	if e.HasSuperuserAuth() {
+		records, err := e.App.FindAllRecords(calendar.EventsCollection)
+		if err != nil {
+			return e.InternalServerError("Failed to load events.", err)
+		}
+		for _, r := range records {
+			history = append(history, calendar.EventFromRecord(r))
+		}
+	} else {
+		var err error
+		if history, err = calendar.FindOwnedEvents(e.App, e.Auth.Id); err != nil {
+			return e.InternalServerError("Failed to load events.", err)
+		}
+	}
+
+	categories, tags := calendar.BuildKeywordModel(history).Suggest(body.Title)
+	return e.JSON(http.StatusOK, map[string]any{
+		"keywords":   calendar.Keywords(body.Title),
+		"categories": categories[:min(len(categories), maxSuggestions)],
+		"tags":       tags[:min(len(tags), maxSuggestions)],
+	})
+}
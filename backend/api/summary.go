@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// eventSummary is one /events/summary item.
+type eventSummary struct {
+	ID              string               `json:"id"`
+	Title           string               `json:"title"`
+	AllDay          bool                 `json:"allDay"`
+	Category        string               `json:"category,omitempty"`
+	Color           string               `json:"color,omitempty"`
+	RRule           string               `json:"rrule,omitempty"`
+	FirstOccurrence *calendar.Occurrence `json:"firstOccurrence"`
+}
+
+// eventsSummary handles GET /api/schedule/events/summary?timezone=&page=&perPage=.
+//
+// Lists the user's events (every event for superusers) for overviews, series
+// once and without their detached occurrences, by start. Each carries its
+// first occurrence, exdated and paused instances skipped, without expanding
+// the series (null when no instance is left). Paginated as /agenda.
+func (h *handlers) eventsSummary(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	page, perPage := pagination(e.Request.URL.Query())
+
+	filter := dbx.And(dbx.HashExp{"sourceId": ""})
+	if !e.HasSuperuserAuth() {
+		filter = dbx.And(filter, dbx.HashExp{"owner": e.Auth.Id})
+	}
+	total, err := e.App.CountRecords(calendar.EventsCollection, filter)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+	var records []*core.Record
+	err = e.App.RecordQuery(calendar.EventsCollection).
+		AndWhere(filter).
+		OrderBy("start ASC", "id ASC").
+		Offset(int64((page - 1) * perPage)).
+		Limit(int64(perPage)).
+		All(&records)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	items := make([]eventSummary, len(records))
+	for i, r := range records {
+		ev := calendar.EventFromRecord(r)
+		items[i] = eventSummary{
+			ID:       ev.ID,
+			Title:    ev.Title,
+			AllDay:   ev.AllDay,
+			Category: ev.Category,
+			Color:    ev.Color,
+			RRule:    ev.RRule,
+		}
+		if first, ok := ev.FirstOccurrence(loc); ok {
+			items[i].FirstOccurrence = &first
+		}
+	}
+	return e.JSON(http.StatusOK, map[string]any{
+		"page":       page,
+		"perPage":    perPage,
+		"totalItems": total,
+		"items":      items,
+	})
+}
+
+// firstOccurrence handles GET /api/schedule/events/{id}/first-occurrence?timezone=.
+//
+// Returns {event, occurrence} with the first occurrence of the event, the
+// earliest instance no exdate or pause skips for a series, or null when none
+// is left. App users can only query their own events.
+func (h *handlers) firstOccurrence(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+
+	var occurrence *calendar.Occurrence
+	if first, ok := calendar.EventFromRecord(rec).FirstOccurrence(loc); ok {
+		occurrence = &first
+	}
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":      rec.Id,
+		"occurrence": occurrence,
+	})
+}
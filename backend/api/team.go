@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxTeamUsers caps the users of one /team-freebusy request.
+const maxTeamUsers = 50
+
+// teamBusy is one blocked interval of a team member. Event is only set when
+// the requester may see the event's details.
+type teamBusy struct {
+	calendar.BusyInterval
+	Event *teamEvent `json:"event,omitempty"`
+}
+
+type teamEvent struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type teamMember struct {
+	User string     `json:"user"`
+	Busy []teamBusy `json:"busy"`
+}
+
+// teamFreebusy handles POST /api/schedule/team-freebusy?start=&end=&timezone=.
+//
+// Body: {"users": [<user id>...]}. Returns the blocked intervals of each
+// user's events in the window, travel buffers included, plus the merged
+// busy and free spans of the whole team. The details (id, title) of an
+// interval are only given when the requester owns the event, is invited to
+// it or is a superuser, and never for events with visibility private unless
+// the requester owns them; every other event blocks time opaquely.
+func (h *handlers) teamFreebusy(e *core.RequestEvent) error {
+	loc, err := h.location(e)
+	if err != nil {
+		return e.BadRequestError("Invalid timezone.", err)
+	}
+	from, to, err := h.dateRange(e, "start", "end", loc)
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		Users []string `json:"users"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	seen := map[string]bool{}
+	body.Users = slices.DeleteFunc(body.Users, func(id string) bool {
+		dup := seen[id]
+		seen[id] = true
+		return dup
+	})
+	if len(body.Users) == 0 || len(body.Users) > maxTeamUsers {
+		return e.BadRequestError("Invalid body.", validation.Errors{
+			"users": validation.NewError("validation_invalid_users", "Must list 1 to 50 user ids."),
+		})
+	}
+	found, err := e.App.FindRecordsByIds(calendar.UsersCollection, body.Users)
+	if err != nil {
+		return e.InternalServerError("Failed to load users.", err)
+	}
+	if len(found) != len(body.Users) {
+		return e.BadRequestError("Invalid body.", validation.Errors{
+			"users": validation.NewError("validation_unknown_user", "Every user must exist."),
+		})
+	}
+
+	// invitations reveal the events they are for
+	invited := map[string]bool{}
+	if !e.HasSuperuserAuth() && e.Auth.Email() != "" {
+		records, err := calendar.FindInvitations(e.App, e.Auth.Email(), "")
+		if err != nil {
+			return e.InternalServerError("Failed to load invitations.", err)
+		}
+		for _, r := range records {
+			invited[r.GetString("event")] = true
+		}
+	}
+	visible := func(ev *calendar.Event) bool {
+		if !e.HasSuperuserAuth() && ev.Owner == e.Auth.Id {
+			return true
+		}
+		if ev.Visibility == calendar.VisibilityPrivate {
+			return false
+		}
+		return e.HasSuperuserAuth() || invited[ev.ID] || invited[ev.SourceID]
+	}
+
+	wideFrom, wideTo := from.Add(-calendar.MaxTravel), to.Add(calendar.MaxTravel)
+	// every member's events block time, the visible filter above decides
+	// which ones are detailed
+	events, err := calendar.FindEvents(e.App, nil, wideFrom, wideTo)
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	members := make([]teamMember, len(body.Users))
+	index := map[string]int{}
+	for i, id := range body.Users {
+		members[i] = teamMember{User: id, Busy: []teamBusy{}}
+		index[id] = i
+	}
+	var all []calendar.BusyInterval
+	for _, ev := range events {
+		i, ok := index[ev.Owner]
+		if !ok {
+			continue
+		}
+		var detail *teamEvent
+		if visible(ev) {
+			detail = &teamEvent{ID: ev.ID, Title: ev.Title}
+		}
+		for _, b := range calendar.FreeBusy(h.expansions.Expand([]*calendar.Event{ev}, wideFrom, wideTo, loc), from, to) {
+			if detail == nil {
+				b.ID = ""
+			}
+			members[i].Busy = append(members[i].Busy, teamBusy{BusyInterval: b, Event: detail})
+			all = append(all, b)
+		}
+	}
+	for _, m := range members {
+		slices.SortStableFunc(m.Busy, func(a, b teamBusy) int { return a.Start.Compare(b.Start) })
+	}
+
+	busy := calendar.MergeBusy(all)
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"users":    members,
+		"busy":     busy,
+		"free":     calendar.FreeSpans(busy, from, to),
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// transferEvent handles POST /api/schedule/events/{id}/transfer.
+//
+// Body: {"to": "<user id>"}. Hands the event, with its detached occurrences,
+// to another user (see calendar.TransferEvents). Only superusers and the
+// current owner can transfer an event; detached occurrences move with their
+// series only.
+func (h *handlers) transferEvent(e *core.RequestEvent) error {
+	rec, err := e.App.FindRecordById(calendar.EventsCollection, e.Request.PathValue("id"))
+	if err != nil || (!e.HasSuperuserAuth() && rec.GetString("owner") != e.Auth.Id) {
+		return e.NotFoundError("Event not found.", err)
+	}
+	if rec.GetString("sourceId") != "" {
+		return e.BadRequestError("Detached occurrences move with their series, transfer the series instead.", nil)
+	}
+
+	var body struct {
+		To string `json:"to"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	to, err := transferTarget(e, body.To)
+	if err != nil {
+		return err
+	}
+	if rec.GetString("owner") == to.Id {
+		return e.BadRequestError("The event already belongs to the target user.", nil)
+	}
+
+	moved, err := calendar.TransferEvents(e.App, []*core.Record{rec}, to, e.Auth)
+	if err != nil {
+		return e.BadRequestError("Failed to transfer the event.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"event":       rec.Id,
+		"to":          to.Id,
+		"transferred": moved,
+	})
+}
+
+// transferEvents handles POST /api/schedule/events/transfer.
+//
+// Body: {"from": "<user id>", "to": "<user id>"}. Hands every event of from
+// to to in one transaction, e.g. when offboarding someone. App users can
+// only give away their own events.
+func (h *handlers) transferEvents(e *core.RequestEvent) error {
+	var body struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := e.BindBody(&body); err != nil {
+		return e.BadRequestError("Invalid body.", err)
+	}
+	if body.From == "" {
+		return e.BadRequestError("Invalid body.", validation.Errors{"from": validation.NewError("validation_required", "Cannot be blank.")})
+	}
+	if !e.HasSuperuserAuth() && body.From != e.Auth.Id {
+		return e.ForbiddenError("You can only transfer your own events.", nil)
+	}
+	to, err := transferTarget(e, body.To)
+	if err != nil {
+		return err
+	}
+	if body.From == to.Id {
+		return e.BadRequestError("from and to must be different users.", nil)
+	}
+
+	// series only, their detached occurrences follow
+	events, err := e.App.FindAllRecords(calendar.EventsCollection, dbx.HashExp{"owner": body.From, "sourceId": ""})
+	if err != nil {
+		return e.InternalServerError("Failed to load events.", err)
+	}
+
+	moved, err := calendar.TransferEvents(e.App, events, to, e.Auth)
+	if err != nil {
+		return e.BadRequestError("Failed to transfer the events.", err)
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":        body.From,
+		"to":          to.Id,
+		"transferred": moved,
+	})
+}
+
+// transferTarget loads the user a transfer goes to, a 400 when there is none.
+func transferTarget(e *core.RequestEvent, id string) (*core.Record, error) {
+	if id == "" {
+		return nil, e.BadRequestError("Invalid body.", validation.Errors{"to": validation.NewError("validation_required", "Cannot be blank.")})
+	}
+	to, err := e.App.FindRecordById(calendar.UsersCollection, id)
+	if err != nil {
+		return nil, e.BadRequestError("Invalid body.", validation.Errors{"to": validation.NewError("validation_unknown_user", "No such user.")})
+	}
+	return to, nil
+}
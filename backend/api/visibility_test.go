@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestPrivateEventsRedacted(t *testing.T) {
+	f := newFixture(t, func(app core.App, f *fixture) {
+		ev := f.event(app, "doctor", map[string]any{
+			"title": "Doctor", "start": at(8, 0), "end": at(9, 0), "owner": f.owner.Id,
+			"location": "Clinic", "notes": "Bring the results", "visibility": calendar.VisibilityPrivate,
+		})
+		if _, err := calendar.ShareEvent(app, ev, f.other, calendar.SharePermissionRead); err != nil {
+			t.Fatal(err)
+		}
+	})
+	occurrences := "/api/schedule/occurrences?start=2026-03-10&end=2026-03-11&timezone=UTC"
+	freebusy := "/api/schedule/freebusy?start=2026-03-10&end=2026-03-11&timezone=UTC"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "owner sees the details",
+			Method:          http.MethodGet,
+			URL:             occurrences,
+			Headers:         f.auth(f.owner),
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"title":"Doctor"`, `"location":"Clinic"`, `"notes":"Bring the results"`},
+		},
+		{
+			Name:               "shared user sees the time only",
+			Method:             http.MethodGet,
+			URL:                occurrences,
+			Headers:            f.auth(f.other),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Busy"`, `"start":"2026-03-10T08:00:00Z"`},
+			NotExpectedContent: []string{"Doctor", "Clinic", "Bring the results"},
+		},
+		{
+			Name:               "shared user's freebusy has no id",
+			Method:             http.MethodGet,
+			URL:                freebusy,
+			Headers:            f.auth(f.other),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"id":""`},
+			NotExpectedContent: []string{f.records["doctor"]},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = f.factory
+		s.Test(t)
+	}
+}
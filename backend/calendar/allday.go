@@ -0,0 +1,61 @@
+package calendar
+
+import "time"
+
+// TimedDefaultHour and TimedDefaultDuration place an all-day event converted
+// to a timed one: a one hour block at 09:00.
+const (
+	TimedDefaultHour     = 9
+	TimedDefaultDuration = time.Hour
+)
+
+// AllDayToggle is an event converted between timed and all-day.
+type AllDayToggle struct {
+	AllDay  bool
+	Start   time.Time
+	End     time.Time
+	Exdates []time.Time
+}
+
+// ToggleAllDay converts ev to all-day or back, working on dates in the event
+// zone (loc as fallback).
+//
+// Timed to all-day snaps start to midnight of its day and end to midnight of
+// the same day when it ends exactly there, otherwise of the next one (always
+// at least one day). All-day to timed becomes a TimedDefaultDuration block at
+// TimedDefaultHour on the start date. Exdates move along with the
+// occurrences they exclude, so a recurrence keeps its exceptions.
+func ToggleAllDay(ev *Event, loc *time.Location) AllDayToggle {
+	zone := ev.Zone(loc)
+	if ev.Floating {
+		zone = time.UTC // floating times are stored as UTC wall clock times
+	}
+	out := AllDayToggle{AllDay: !ev.AllDay}
+
+	if out.AllDay {
+		out.Start = StartOfDay(ev.Start, zone)
+		out.End = StartOfDay(ev.End, zone)
+		if out.End.Before(ev.End.In(zone)) {
+			out.End = out.End.AddDate(0, 0, 1)
+		}
+		if !out.End.After(out.Start) {
+			out.End = out.Start.AddDate(0, 0, 1)
+		}
+		for _, x := range ev.Exdates {
+			out.Exdates = append(out.Exdates, StartOfDay(x, zone))
+		}
+		return out
+	}
+
+	out.Start = atDefaultHour(ev.Start, zone)
+	out.End = out.Start.Add(TimedDefaultDuration)
+	for _, x := range ev.Exdates {
+		out.Exdates = append(out.Exdates, atDefaultHour(x, zone))
+	}
+	return out
+}
+
+func atDefaultHour(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, TimedDefaultHour, 0, 0, 0, loc)
+}
@@ -0,0 +1,64 @@
+package calendar_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestImportAllDayEnds(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	cases := []struct {
+		fixture, mode string
+		want          map[string]int // days by title
+	}{
+		{"allday-google.ics", calendar.AllDayEndExclusive, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-google.ics", calendar.AllDayEndAuto, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-google.ics", calendar.AllDayEndInclusive, map[string]int{"Good Friday": 2, "Team retreat": 4}},
+		{"allday-apple.ics", calendar.AllDayEndExclusive, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-apple.ics", calendar.AllDayEndAuto, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-inclusive.ics", calendar.AllDayEndExclusive, map[string]int{"Good Friday": 0, "Team retreat": 2}},
+		{"allday-inclusive.ics", calendar.AllDayEndAuto, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-inclusive.ics", calendar.AllDayEndInclusive, map[string]int{"Good Friday": 1, "Team retreat": 3}},
+		{"allday-inclusive.ics", "", map[string]int{"Good Friday": 0, "Team retreat": 2}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.fixture+"/"+tt.mode, func(t *testing.T) {
+			owner := newUser(t, app, fmt.Sprintf("owner%d@example.com", i))
+			f, err := os.Open("testdata/" + tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			res, err := calendar.ImportICS(app, f, calendar.ImportOptions{Owner: owner.Id, AllDayEnd: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Created != len(tt.want) || len(res.Errors) > 0 {
+				t.Fatalf("import = %+v", res)
+			}
+			events, err := calendar.FindOwnedEvents(app, owner.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ev := range events {
+				if !ev.AllDay {
+					t.Errorf("%s is not all-day", ev.Title)
+				}
+				if days := int(math.Round(ev.End.Sub(ev.Start).Hours() / 24)); days != tt.want[ev.Title] {
+					t.Errorf("%s lasts %d days, want %d", ev.Title, days, tt.want[ev.Title])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// AttendeesCollection stores the people invited to an event.
+const AttendeesCollection = "attendees"
+
+// Participation statuses (iCalendar PARTSTAT) of an attendee.
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+	PartStatDeclined    = "DECLINED"
+	PartStatTentative   = "TENTATIVE"
+)
+
+// ParsePartStat normalizes an RSVP answer ("accepted", "DECLINED", ...) to a
+// PARTSTAT value, reporting false for anything else.
+func ParsePartStat(s string) (string, bool) {
+	switch v := strings.ToUpper(strings.TrimSpace(s)); v {
+	case PartStatAccepted, PartStatDeclined, PartStatTentative:
+		return v, true
+	}
+	return "", false
+}
+
+// Attendee is the server-side view of an attendees record.
+type Attendee struct {
+	ID      string `json:"id"`
+	EventID string `json:"event"`
+	Email   string `json:"email"`
+	Name    string `json:"name,omitempty"`
+	Status  string `json:"status"`
+}
+
+// AttendeeFromRecord maps an attendees record to an Attendee.
+func AttendeeFromRecord(r *core.Record) Attendee {
+	a := Attendee{
+		ID:      r.Id,
+		EventID: r.GetString("event"),
+		Email:   r.GetString("email"),
+		Name:    r.GetString("name"),
+		Status:  r.GetString("status"),
+	}
+	if a.Status == "" {
+		a.Status = PartStatNeedsAction
+	}
+	return a
+}
+
+// FindAttendeeRecord returns the invitation of email to an event.
+func FindAttendeeRecord(app core.App, eventID, email string) (*core.Record, error) {
+	return app.FindFirstRecordByFilter(
+		AttendeesCollection,
+		"event = {:event} && email:lower = {:email}",
+		dbx.Params{"event": eventID, "email": strings.ToLower(email)},
+	)
+}
+
+// FindInvitations returns the invitations of email, optionally limited to the
+// events of one owner, with their events expanded under "event".
+func FindInvitations(app core.App, email, ownerID string) ([]*core.Record, error) {
+	filter := "email:lower = {:email}"
+	if ownerID != "" {
+		filter += " && event.owner = {:owner}"
+	}
+	records, err := app.FindRecordsByFilter(AttendeesCollection, filter, "", 0, 0, dbx.Params{
+		"email": strings.ToLower(strings.TrimSpace(email)),
+		"owner": ownerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errs := app.ExpandRecords(records, []string{"event"}, nil); len(errs) > 0 {
+		for _, err := range errs {
+			return nil, err
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,109 @@
+package calendar
+
+import (
+	"time"
+
+	"schedule/recur"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Audit issue types, the keys of AuditReport.Issues.
+const (
+	IssueEndBeforeStart      = "endBeforeStart"
+	IssueInvalidRRule        = "invalidRRule"
+	IssueInvalidExdates      = "invalidExdates"
+	IssueUnmatchedExdates    = "unmatchedExdates"
+	IssueOrphanedDetached    = "orphanedDetached"
+	IssuePastReminders       = "pastReminders"
+	IssueDuplicateImportHash = "duplicateImportHash"
+)
+
+// AuditReport lists the ids of the events affected by each issue type.
+type AuditReport struct {
+	Scanned int                 `json:"scanned"`
+	Issues  map[string][]string `json:"issues"`
+}
+
+// Audit scans every event for data inconsistencies. Reminders are checked
+// against now: a single upcoming event whose reminder time already passed will
+// never fire it. loc is the zone events without a timezone are expanded in.
+func Audit(app core.App, now time.Time, loc *time.Location) (*AuditReport, error) {
+	records, err := app.FindAllRecords(EventsCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AuditReport{
+		Scanned: len(records),
+		Issues: map[string][]string{
+			IssueEndBeforeStart:      {},
+			IssueInvalidRRule:        {},
+			IssueInvalidExdates:      {},
+			IssueUnmatchedExdates:    {},
+			IssueOrphanedDetached:    {},
+			IssuePastReminders:       {},
+			IssueDuplicateImportHash: {},
+		},
+	}
+	add := func(issue, id string) {
+		report.Issues[issue] = append(report.Issues[issue], id)
+	}
+
+	byID := make(map[string]*core.Record, len(records))
+	for _, r := range records {
+		byID[r.Id] = r
+	}
+	hashes := map[string][]string{}
+
+	for _, r := range records {
+		ev := EventFromRecord(r)
+
+		if ev.End.Before(ev.Start) {
+			add(IssueEndBeforeStart, ev.ID)
+		}
+
+		if ev.RRule != "" {
+			if _, err := recur.Parse(ev.RRule); err != nil {
+				add(IssueInvalidRRule, ev.ID)
+			} else if len(UnmatchedExdates(ev, loc)) > 0 {
+				// e.g. stored in the wrong offset: they exclude nothing
+				add(IssueUnmatchedExdates, ev.ID)
+			}
+		}
+
+		// EventFromRecord drops what doesn't parse, so check the raw values
+		var exdates []string
+		if err := r.UnmarshalJSONField("exdates", &exdates); err != nil || len(exdates) != len(ev.Exdates) {
+			add(IssueInvalidExdates, ev.ID)
+		}
+
+		if ev.IsDetached() {
+			parent, ok := byID[ev.SourceID]
+			if !ok || parent.GetString("rrule") == "" {
+				add(IssueOrphanedDetached, ev.ID)
+			}
+		}
+
+		if !ev.IsRecurring() && ev.Start.After(now) {
+			for _, m := range ev.ReminderMinutes {
+				if ev.Start.Add(-time.Duration(m) * time.Minute).Before(now) {
+					add(IssuePastReminders, ev.ID)
+					break
+				}
+			}
+		}
+
+		if h := r.GetString("importHash"); h != "" {
+			hashes[h] = append(hashes[h], ev.ID)
+		}
+	}
+
+	for _, ids := range hashes {
+		if len(ids) > 1 {
+			report.Issues[IssueDuplicateImportHash] = append(report.Issues[IssueDuplicateImportHash], ids...)
+		}
+	}
+
+	return report, nil
+}
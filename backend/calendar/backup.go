@@ -0,0 +1,343 @@
+package calendar
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BackupVersion is the version of the Backup document format. Restore only
+// reads documents of this version.
+const BackupVersion = 1
+
+// ErrBackupVersion rejects a backup document of another version.
+var ErrBackupVersion = errors.New("unsupported backup version")
+
+// errRestoreRollback rolls back a dry run or a restore with failures.
+var errRestoreRollback = errors.New("restore rolled back")
+
+// Backup is a portable, human-readable copy of the application data: the
+// categories, calendars, subscriptions, events, attendees and shares as
+// their record fields, plus the settings in effect when it was taken (for
+// reference, they come from the environment and aren't restored).
+//
+// User accounts hold credentials and are not copied: Users only lists the id
+// and email of each, so Restore can match them to the accounts of the target
+// instance.
+type Backup struct {
+	Version           int              `json:"version"`
+	ExportedAt        time.Time        `json:"exportedAt"`
+	Settings          map[string]any   `json:"settings,omitempty"`
+	Users             []BackupUser     `json:"users"`
+	Categories        []map[string]any `json:"categories"`
+	Calendars         []map[string]any `json:"calendars"`
+	ExternalCalendars []map[string]any `json:"externalCalendars"`
+	Events            []map[string]any `json:"events"`
+	Attendees         []map[string]any `json:"attendees"`
+	Shares            []map[string]any `json:"shares"`
+}
+
+// BackupUser identifies a user account of a backup.
+type BackupUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// MissingUsersError rejects a restore referencing users that have no account
+// on the target instance, by email (by id for users the backup doesn't
+// list).
+type MissingUsersError struct {
+	Users []string
+}
+
+func (e *MissingUsersError) Error() string {
+	return "no account for " + strings.Join(e.Users, ", ")
+}
+
+// RestoreResult summarizes a Restore. Nothing is kept when Failed isn't
+// empty or for a dry run.
+type RestoreResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Failed  []RestoreFailure `json:"failed"`
+}
+
+// RestoreFailure is a record of the backup that couldn't be saved.
+type RestoreFailure struct {
+	Collection string `json:"collection"`
+	ID         string `json:"id"`
+	Error      string `json:"error"`
+}
+
+// backupSection is a collection of a Backup, in restore order: the records a
+// section refers to come in earlier ones.
+type backupSection struct {
+	name  string
+	items *[]map[string]any
+}
+
+func (b *Backup) sections() []backupSection {
+	return []backupSection{
+		{CategoriesCollection, &b.Categories},
+		{CalendarsCollection, &b.Calendars},
+		{ExternalCalendarsCollection, &b.ExternalCalendars},
+		{EventsCollection, &b.Events},
+		{AttendeesCollection, &b.Attendees},
+		{EventSharesCollection, &b.Shares},
+	}
+}
+
+// NewBackup reads the application data into a Backup.
+func NewBackup(app core.App, settings map[string]any) (*Backup, error) {
+	b := &Backup{Version: BackupVersion, ExportedAt: time.Now().UTC(), Settings: settings}
+	users, err := app.FindAllRecords(UsersCollection)
+	if err != nil {
+		return nil, err
+	}
+	b.Users = make([]BackupUser, len(users))
+	for i, u := range users {
+		b.Users[i] = BackupUser{ID: u.Id, Email: u.Email()}
+	}
+
+	for _, s := range b.sections() {
+		records, err := app.FindAllRecords(s.name)
+		if err != nil {
+			return nil, err
+		}
+		*s.items = make([]map[string]any, len(records))
+		for i, r := range records {
+			(*s.items)[i] = r.FieldsData()
+		}
+	}
+	return b, nil
+}
+
+// Restore saves the records of b, creating those whose id is unknown and
+// overwriting the others (or the category of the same name), in one
+// transaction, a section after the one it refers to. The events relations to
+// other events (sourceId, dependsOn) are set in a second pass, so events can
+// come in any order. Users are matched by email to the accounts of app, which
+// must all exist (a MissingUsersError otherwise, before anything is saved),
+// and the user relations remapped.
+//
+// Every save runs the usual validation and hooks, except that mirrored
+// events may go back into their external calendar and subscriptions keep
+// theirs (see MirrorKey); events are marked as changed by actor. Any failure
+// rolls the whole restore back, as does dryRun, so a dry run reports what a
+// restore would do.
+func Restore(app core.App, b *Backup, dryRun bool, actor *core.Record) (*RestoreResult, error) {
+	if b.Version != BackupVersion {
+		return nil, ErrBackupVersion
+	}
+
+	users, err := app.FindCollectionByNameOrId(UsersCollection)
+	if err != nil {
+		return nil, err
+	}
+	userIDs, err := matchUsers(app, b)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RestoreResult{DryRun: dryRun, Failed: []RestoreFailure{}}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		fail := func(collection, id string, err error) {
+			res.Failed = append(res.Failed, RestoreFailure{Collection: collection, ID: id, Error: err.Error()})
+		}
+
+		deferred := map[string]map[string]any{} // event id -> relations to events
+		var deferredOrder []string
+		for _, s := range b.sections() {
+			collection, err := txApp.FindCollectionByNameOrId(s.name)
+			if err != nil {
+				return err
+			}
+			for _, item := range *s.items {
+				id, _ := item["id"].(string)
+				rec, err := txApp.FindRecordById(s.name, id)
+				if err != nil && s.name == CategoriesCollection {
+					// the built-in categories are seeded with other ids
+					rec, err = txApp.FindFirstRecordByData(s.name, "name", item["name"])
+				}
+				isNew := err != nil
+				if isNew {
+					rec = core.NewRecord(collection)
+					rec.Id = id
+				}
+				for name, value := range item {
+					if name == "id" || name == "created" || name == "updated" {
+						continue
+					}
+					rel, _ := collection.Fields.GetByName(name).(*core.RelationField)
+					switch {
+					case rel != nil && rel.CollectionId == users.Id:
+						rec.Set(name, remapIDs(value, userIDs))
+					case rel != nil && s.name == EventsCollection && rel.CollectionId == collection.Id:
+						if deferred[id] == nil {
+							deferred[id] = map[string]any{}
+							deferredOrder = append(deferredOrder, id)
+						}
+						deferred[id][name] = value
+					default:
+						rec.Set(name, value)
+					}
+				}
+				if s.name == EventsCollection || s.name == ExternalCalendarsCollection {
+					rec.Set(MirrorKey, true)
+				}
+				if s.name == EventsCollection {
+					SetChangedBy(rec, actor)
+				}
+				if err := txApp.Save(rec); err != nil {
+					fail(s.name, id, err)
+					continue
+				}
+				if isNew {
+					res.Created++
+				} else {
+					res.Updated++
+				}
+			}
+		}
+
+		for _, id := range deferredOrder {
+			rec, err := txApp.FindRecordById(EventsCollection, id)
+			if err != nil {
+				continue // failed above
+			}
+			changed := false
+			for name, value := range deferred[id] {
+				before := rec.Get(name)
+				rec.Set(name, value)
+				changed = changed || !reflect.DeepEqual(before, rec.Get(name))
+			}
+			if !changed {
+				continue
+			}
+			rec.Set(MirrorKey, true)
+			SetChangedBy(rec, actor)
+			if err := txApp.Save(rec); err != nil {
+				fail(EventsCollection, id, err)
+			}
+		}
+
+		if dryRun || len(res.Failed) > 0 {
+			return errRestoreRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRestoreRollback) {
+		return nil, err
+	}
+	return res, nil
+}
+
+// matchUsers maps the user ids of the backup to the accounts of app with the
+// same email, or else the same id. Users the records refer to without a
+// match make a MissingUsersError.
+func matchUsers(app core.App, b *Backup) (map[string]string, error) {
+	ids := map[string]string{}
+	for _, u := range b.Users {
+		if u.Email == "" {
+			continue
+		}
+		if rec, err := app.FindAuthRecordByEmail(UsersCollection, u.Email); err == nil {
+			ids[u.ID] = rec.Id
+		}
+	}
+
+	users, err := app.FindCollectionByNameOrId(UsersCollection)
+	if err != nil {
+		return nil, err
+	}
+	emails := map[string]string{}
+	for _, u := range b.Users {
+		emails[u.ID] = u.Email
+	}
+	missing := map[string]bool{}
+	for _, s := range b.sections() {
+		collection, err := app.FindCollectionByNameOrId(s.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range collection.Fields {
+			rel, ok := f.(*core.RelationField)
+			if !ok || rel.CollectionId != users.Id {
+				continue
+			}
+			for _, item := range *s.items {
+				for _, id := range relationIDs(item[rel.Name]) {
+					if _, ok := ids[id]; ok {
+						continue
+					}
+					if _, err := app.FindRecordById(UsersCollection, id); err == nil {
+						ids[id] = id
+						continue
+					}
+					if emails[id] != "" {
+						missing[emails[id]] = true
+					} else {
+						missing[id] = true
+					}
+				}
+			}
+		}
+	}
+	if len(missing) > 0 {
+		e := &MissingUsersError{}
+		for u := range missing {
+			e.Users = append(e.Users, u)
+		}
+		sort.Strings(e.Users)
+		return nil, e
+	}
+	return ids, nil
+}
+
+// relationIDs returns the ids of a relation field value of a backup: a
+// string, or a list of them for multiple relations.
+func relationIDs(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []any:
+		var out []string
+		for _, id := range v {
+			if s, ok := id.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// remapIDs maps the ids of a relation field value through ids, keeping its
+// shape.
+func remapIDs(value any, ids map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		if id, ok := ids[v]; ok {
+			return id
+		}
+		return v
+	case []any, []string:
+		out := relationIDs(v)
+		for i, id := range out {
+			if to, ok := ids[id]; ok {
+				out[i] = to
+			}
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,138 @@
+package calendar
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCachedOccurrences is the largest expansion of one event an
+// ExpansionCache keeps; bigger ones are streamed lazily, as without a cache.
+const maxCachedOccurrences = 2000
+
+// expansionKey identifies the expansion of one version of an event: a save
+// bumps updated, so entries of older versions are never hit again and age
+// out of the cache.
+type expansionKey struct {
+	event    string
+	updated  int64
+	from, to int64
+	zone     string
+}
+
+type expansionEntry struct {
+	key   expansionKey
+	items []Occurrence
+}
+
+// ExpansionCache is an LRU cache of per-event expansions, shared by the
+// requests expanding the same events over the same window. The zero value
+// is not usable; a nil cache expands without caching.
+type ExpansionCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[expansionKey]*list.Element
+	order   *list.List // front is the most recently used
+
+	hits, misses atomic.Int64
+}
+
+// NewExpansionCache returns a cache holding the expansions of up to size
+// (event, window) pairs.
+func NewExpansionCache(size int) *ExpansionCache {
+	return &ExpansionCache{
+		size:    size,
+		entries: map[expansionKey]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Stats returns the lookups served from the cache and those that expanded.
+func (c *ExpansionCache) Stats() (hits, misses int64) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Stream is Stream, replaying the cached expansion of every event expanded
+// over the same window before.
+func (c *ExpansionCache) Stream(events []*Event, from, to time.Time, loc *time.Location, fn func(Occurrence) error) error {
+	cursors := make([]*cursor, 0, len(events))
+	for _, ev := range events {
+		cursors = append(cursors, c.cursor(ev, from, to, loc))
+	}
+	return streamCursors(cursors, fn)
+}
+
+// Expand is Expand, through the cache.
+func (c *ExpansionCache) Expand(events []*Event, from, to time.Time, loc *time.Location) []Occurrence {
+	var out []Occurrence
+	_ = c.Stream(events, from, to, loc, func(o Occurrence) error {
+		out = append(out, o)
+		return nil
+	})
+	return out
+}
+
+// cursor returns a cursor over the occurrences of ev in [from, to), from
+// the cache when possible. Events without an id or version (unsaved ones)
+// are never cached.
+func (c *ExpansionCache) cursor(ev *Event, from, to time.Time, loc *time.Location) *cursor {
+	if c == nil || ev.ID == "" || ev.Updated.IsZero() {
+		return newCursor(ev, from, to, loc)
+	}
+
+	key := expansionKey{
+		event:   ev.ID,
+		updated: ev.Updated.UnixNano(),
+		from:    from.UnixNano(),
+		to:      to.UnixNano(),
+		zone:    loc.String(),
+	}
+	if items, ok := c.get(key); ok {
+		c.hits.Add(1)
+		return &cursor{replay: items, replaying: true}
+	}
+	c.misses.Add(1)
+
+	var items []Occurrence
+	for cur := newCursor(ev, from, to, loc); cur.next(); {
+		if len(items) == maxCachedOccurrences {
+			return newCursor(ev, from, to, loc)
+		}
+		items = append(items, cur.cur)
+	}
+	c.put(key, items)
+	return &cursor{replay: items, replaying: true}
+}
+
+func (c *ExpansionCache) get(key expansionKey) ([]Occurrence, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*expansionEntry).items, true
+}
+
+func (c *ExpansionCache) put(key expansionKey, items []Occurrence) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&expansionEntry{key: key, items: items})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*expansionEntry).key)
+	}
+}
@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// CalendarsCollection stores the calendars users group their events in.
+	CalendarsCollection = "calendars"
+
+	// DefaultCalendarName is the name of the calendar created for a user on
+	// their first event.
+	DefaultCalendarName = "My calendar"
+)
+
+// DefaultCalendar returns the default calendar of a user, creating it when the
+// user has none yet.
+func DefaultCalendar(app core.App, ownerID string) (*core.Record, error) {
+	found, err := app.FindRecordsByFilter(
+		CalendarsCollection,
+		"owner = {:owner} && isDefault = true",
+		"created",
+		1,
+		0,
+		dbx.Params{"owner": ownerID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) > 0 {
+		return found[0], nil
+	}
+
+	collection, err := app.FindCollectionByNameOrId(CalendarsCollection)
+	if err != nil {
+		return nil, err
+	}
+	rec := core.NewRecord(collection)
+	rec.Set("name", DefaultCalendarName)
+	rec.Set("owner", ownerID)
+	rec.Set("isDefault", true)
+	if err := app.Save(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// InCalendars keeps the events of the given calendars. No ids keeps all.
+func InCalendars(events []*Event, ids []string) []*Event {
+	if len(ids) == 0 {
+		return events
+	}
+	out := events[:0:0]
+	for _, ev := range events {
+		if slices.Contains(ids, ev.Calendar) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ErrMergeIntoSelf is returned by MergeCategory for identical categories.
+var ErrMergeIntoSelf = errors.New("a category cannot be merged into itself")
+
+// MergeCategory moves every event of the category source to target and
+// deletes source, all in one transaction, returning how many events moved.
+// Events are saved one by one so their hooks and history run as for any
+// edit, attributed to actor (nil for the server); events of external
+// calendars are recategorized too (see MirrorKey). The target name must be an
+// allowed events.category value, or the first save fails and nothing changes.
+func MergeCategory(app core.App, source, target, actor *core.Record) (int, error) {
+	if source.Id == target.Id {
+		return 0, ErrMergeIntoSelf
+	}
+
+	moved := 0
+	err := app.RunInTransaction(func(txApp core.App) error {
+		records, err := txApp.FindAllRecords(EventsCollection, dbx.HashExp{"category": source.GetString("name")})
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			rec.Set("category", target.GetString("name"))
+			rec.Set(MirrorKey, true)
+			SetChangedBy(rec, actor)
+			if err := txApp.Save(rec); err != nil {
+				return err
+			}
+			moved++
+		}
+		return txApp.Delete(source)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
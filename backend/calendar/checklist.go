@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxChecklistItems caps the checklist of one event.
+	MaxChecklistItems = 100
+
+	// MaxChecklistText caps the text of one checklist item, in characters.
+	MaxChecklistText = 500
+)
+
+// ChecklistItem is one subtask of an event.
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// ParseChecklist reads the checklist field value: a list of {text, done}
+// objects with a non-blank text (trimmed) and an optional done flag. Other
+// keys are rejected so typos don't pass silently.
+func ParseChecklist(raw []map[string]any) ([]ChecklistItem, error) {
+	items := make([]ChecklistItem, len(raw))
+	for i, obj := range raw {
+		for key := range obj {
+			if key != "text" && key != "done" {
+				return nil, fmt.Errorf("item %d has an unknown key %q", i, key)
+			}
+		}
+		text, _ := obj["text"].(string)
+		done, ok := obj["done"].(bool)
+		if _, set := obj["done"]; set && !ok {
+			return nil, fmt.Errorf("item %d: done must be a boolean", i)
+		}
+		if items[i].Text = strings.TrimSpace(text); items[i].Text == "" {
+			return nil, fmt.Errorf("item %d needs a text", i)
+		}
+		items[i].Done = done
+	}
+	return items, ValidateChecklist(items)
+}
+
+// ValidateChecklist checks the size of a checklist and the texts of its
+// items.
+func ValidateChecklist(items []ChecklistItem) error {
+	if len(items) > MaxChecklistItems {
+		return fmt.Errorf("at most %d items are allowed", MaxChecklistItems)
+	}
+	for _, it := range items {
+		if strings.TrimSpace(it.Text) == "" {
+			return errors.New("an item needs a text")
+		}
+		if utf8.RuneCountInString(it.Text) > MaxChecklistText {
+			return fmt.Errorf("item texts are limited to %d characters", MaxChecklistText)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Conflict is a pair of overlapping occurrences.
+type Conflict struct {
+	Occurrence Occurrence `json:"occurrence"`
+	With       Occurrence `json:"with"`
+}
+
+// FindResourceConflict returns the first occurrence of another event booking
+// the same resource that overlaps an occurrence of ev in [from, to), or nil.
+// Travel buffers count on both sides. loc is the zone of the events without
+// a timezone of their own, and of floating ones.
+func FindResourceConflict(app core.App, ev *Event, from, to time.Time, loc *time.Location) (*Conflict, error) {
+	if ev.Resource == "" {
+		return nil, nil
+	}
+
+	// others may reach into the window with their own buffers
+	wideFrom := from.Add(-ev.TravelBefore - MaxTravel)
+	wideTo := to.Add(ev.TravelAfter + MaxTravel)
+
+	records, err := app.FindRecordsByFilter(
+		EventsCollection,
+		"resource = {:resource} && id != {:id} && (rrule != '' || (start < {:to} && end > {:from}))",
+		"start",
+		0,
+		0,
+		dbx.Params{"resource": ev.Resource, "id": ev.ID, "from": dateParam(wideFrom), "to": dateParam(wideTo)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	others := Expand(eventsFromRecords(records), wideFrom, wideTo, loc)
+	SortByBusy(others)
+	return FirstConflict(ev.Occurrences(from, to, loc), others, ev), nil
+}
+
+// SortByBusy orders occurrences by the start of their busy interval (travel
+// buffer included), the order FirstConflict expects.
+func SortByBusy(items []Occurrence) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return busyStart(items[i]).Before(busyStart(items[j]))
+	})
+}
+
+// FirstConflict finds the first overlap between two occurrence lists sorted
+// by busy start (see SortByBusy) with a single two-pointer sweep. When ev is a
+// detached occurrence, the series instance it replaces is not treated as a
+// conflict.
+func FirstConflict(mine, others []Occurrence, ev *Event) *Conflict {
+	i, j := 0, 0
+	for i < len(mine) && j < len(others) {
+		a, b := mine[i], others[j]
+
+		switch {
+		case ev != nil && ev.IsDetached() && b.SourceID == ev.SourceID && b.Start.Equal(ev.RecurrenceID):
+			j++
+		case Intersects(a, b):
+			return &Conflict{Occurrence: a, With: b}
+		case !busyStart(b).After(busyStart(a)):
+			// b ends before a starts, so it can't reach any later a either
+			j++
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// Intersects reports whether the busy intervals of two occurrences overlap.
+func Intersects(a, b Occurrence) bool {
+	as, ae := a.Busy()
+	bs, be := b.Busy()
+	return overlaps(as, ae, bs, be) || overlaps(bs, be, as, ae)
+}
+
+func busyStart(o Occurrence) time.Time {
+	start, _ := o.Busy()
+	return start
+}
+
+// Overlapping reports, for each of items, whether it overlaps another one of
+// them. All-day occurrences are ignored on both sides, they would otherwise
+// overlap every timed one of their days.
+//
+// Rather than comparing all pairs, it sorts by start once and sweeps in
+// O(n log n): an occurrence starting before the latest end seen so far
+// overlaps the occurrence holding that end, so both are marked. An occurrence
+// only overlapped by later ones is always the holder when the first of them
+// comes up, so it is marked too.
+func Overlapping(items []Occurrence) []bool {
+	order := make([]int, 0, len(items))
+	for i, o := range items {
+		if !o.AllDay && o.End.After(o.Start) {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return items[order[a]].Start.Before(items[order[b]].Start)
+	})
+
+	out := make([]bool, len(items))
+	holder := -1
+	for _, i := range order {
+		o := items[i]
+		if holder >= 0 && o.Start.Before(items[holder].End) {
+			out[i], out[holder] = true, true
+		}
+		if holder < 0 || o.End.After(items[holder].End) {
+			holder = i
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOverlapping(t *testing.T) {
+	timed := func(id string, fromHour, toHour int) Occurrence {
+		return Occurrence{ID: id, Start: utc(2026, 3, 10, fromHour, 0), End: utc(2026, 3, 10, toHour, 0)}
+	}
+	allDay := Occurrence{ID: "day", Start: utc(2026, 3, 10, 0, 0), End: utc(2026, 3, 11, 0, 0), AllDay: true}
+
+	cases := []struct {
+		name  string
+		items []Occurrence
+		want  []bool
+	}{
+		{"none", nil, []bool{}},
+		{"single", []Occurrence{timed("a", 9, 10)}, []bool{false}},
+		{"back to back", []Occurrence{timed("a", 9, 10), timed("b", 10, 11)}, []bool{false, false}},
+		{"overlap", []Occurrence{timed("a", 9, 11), timed("b", 10, 12)}, []bool{true, true}},
+		{"unsorted", []Occurrence{timed("b", 10, 12), timed("c", 13, 14), timed("a", 9, 11)}, []bool{true, false, true}},
+		// a long one overlaps two that don't overlap each other
+		{"nested", []Occurrence{timed("a", 9, 17), timed("b", 10, 11), timed("c", 14, 15)}, []bool{true, true, true}},
+		{"after a nested one", []Occurrence{timed("a", 9, 12), timed("b", 10, 11), timed("c", 11, 13)}, []bool{true, true, true}},
+		{"all-day ignored", []Occurrence{allDay, timed("a", 9, 10)}, []bool{false, false}},
+		{"empty ignored", []Occurrence{timed("a", 9, 9), timed("b", 8, 10)}, []bool{false, false}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Overlapping(c.items); !slices.Equal(got, c.want) {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+			if got := overlappingPairwise(c.items); !slices.Equal(got, c.want) {
+				t.Fatalf("pairwise reference got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+// overlappingPairwise is the quadratic version of Overlapping, the reference
+// the sweep is checked and measured against.
+func overlappingPairwise(items []Occurrence) []bool {
+	out := make([]bool, len(items))
+	for i, a := range items {
+		for j, b := range items[:i] {
+			if a.AllDay || b.AllDay || !a.End.After(a.Start) || !b.End.After(b.Start) {
+				continue
+			}
+			if a.Start.Before(b.End) && b.Start.Before(a.End) {
+				out[i], out[j] = true, true
+			}
+		}
+	}
+	return out
+}
+
+// benchItems is n meetings of 45 minutes starting every half hour, so each
+// overlaps its neighbours, in a shuffled order.
+func benchItems(n int) []Occurrence {
+	items := make([]Occurrence, n)
+	for i := range items {
+		start := utc(2026, 3, 1, 8, 0).Add(time.Duration(i) * 30 * time.Minute)
+		items[i] = Occurrence{ID: fmt.Sprint(i), Start: start, End: start.Add(45 * time.Minute)}
+	}
+	for i := range items {
+		j := (i * 7919) % n
+		items[i], items[j] = items[j], items[i]
+	}
+	return items
+}
+
+func BenchmarkOverlapping(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		items := benchItems(n)
+		b.Run(fmt.Sprintf("sweep/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Overlapping(items)
+			}
+		})
+		b.Run(fmt.Sprintf("pairwise/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				overlappingPairwise(items)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// maxDependencyDepth bounds how long a chain of dependsOn links can get.
+const maxDependencyDepth = 100
+
+// Dependency errors returned by CheckDependency.
+var (
+	ErrDependencyCycle = errors.New("the dependency would form a cycle")
+	ErrDependencyOwner = errors.New("the dependency belongs to another user")
+	ErrStartsTooEarly  = errors.New("the event starts before its dependency ends")
+	ErrDependencyDepth = errors.New("the dependency chain is too long")
+)
+
+// CheckDependency validates the dependsOn link of rec: the dependency must
+// exist, have the same owner, not (indirectly) depend on rec and end no later
+// than rec starts. Stored start/end are compared, for series too.
+func CheckDependency(app core.App, rec *core.Record) error {
+	id := rec.GetString("dependsOn")
+	if id == "" {
+		return nil
+	}
+	dep, err := app.FindRecordById(EventsCollection, id)
+	if err != nil {
+		return err
+	}
+	if dep.GetString("owner") != rec.GetString("owner") {
+		return ErrDependencyOwner
+	}
+
+	for cur, depth := dep, 0; ; depth++ {
+		if cur.Id == rec.Id {
+			return ErrDependencyCycle
+		}
+		if depth == maxDependencyDepth {
+			return ErrDependencyDepth
+		}
+		next := cur.GetString("dependsOn")
+		if next == "" {
+			break
+		}
+		if cur, err = app.FindRecordById(EventsCollection, next); err != nil {
+			return err
+		}
+	}
+
+	if rec.GetDateTime("start").Time().Before(dep.GetDateTime("end").Time()) {
+		return ErrStartsTooEarly
+	}
+	return nil
+}
+
+// FindDependents returns the events depending on the event id.
+func FindDependents(app core.App, id string) ([]*core.Record, error) {
+	return app.FindAllRecords(EventsCollection, dbx.HashExp{"dependsOn": id})
+}
+
+// ShiftDependents moves the dependents of the event id that start before end
+// to end, keeping their duration, and so on down the chain. Dependents are
+// saved before the events they depend on are (and deeper ones first), so each
+// save already finds its own dependents out of the way; callers save event id
+// itself afterwards. actor is logged as the author of the changes. It returns
+// the ids of the moved events.
+func ShiftDependents(app core.App, id string, end time.Time, actor *core.Record) ([]string, error) {
+	dependents, err := FindDependents(app, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []string
+	for _, d := range dependents {
+		start := d.GetDateTime("start").Time()
+		if !start.Before(end) {
+			continue
+		}
+		newEnd := end.Add(d.GetDateTime("end").Time().Sub(start))
+		below, err := ShiftDependents(app, d.Id, newEnd, actor)
+		if err != nil {
+			return nil, err
+		}
+		moved = append(moved, below...)
+
+		d.Set("start", end.UTC())
+		d.Set("end", newEnd.UTC())
+		SetChangedBy(d, actor)
+		if err := app.Save(d); err != nil {
+			return nil, err
+		}
+		moved = append(moved, d.Id)
+	}
+	return moved, nil
+}
@@ -0,0 +1,254 @@
+// Package calendar holds the domain logic shared by the schedule routes and
+// hooks: the event model, recurrence expansion and day/week boundaries.
+package calendar
+
+import (
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// EventsCollection is the name of the PocketBase collection storing events:
+// "events" unless configured otherwise (config.EventsCollection). main sets
+// it before the app boots, so migrations, hooks and routes all see the same
+// name.
+var EventsCollection = "events"
+
+const (
+	// UsersCollection is the auth collection of the app users owning events.
+	UsersCollection = "users"
+
+	// CategoriesCollection stores the per-category settings (color, default reminders).
+	CategoriesCollection = "categories"
+
+	// ReminderDisplay and ReminderEmail are the reminderType values, mapped to
+	// the VALARM ACTION on export and import. Empty means display.
+	ReminderDisplay = "display"
+	ReminderEmail   = "email"
+
+	// VisibilityPrivate marks events whose details only their owner sees;
+	// others (team freebusy) just see the time blocked. Empty means public.
+	VisibilityPrivate = "private"
+
+	// RedactedTitle replaces the title of a private event for other viewers.
+	RedactedTitle = "Busy"
+
+	// MaxZoneOffset is the largest UTC offset in use. A floating event can
+	// occur this far from the instant its stored (wall-as-UTC) time names.
+	MaxZoneOffset = 14 * time.Hour
+
+	// MaxTravel is the largest travel buffer an event can have on either side
+	// (the travel*Minutes fields are capped at one day).
+	MaxTravel = 24 * time.Hour
+)
+
+// Event is the server-side view of an events record.
+type Event struct {
+	ID              string
+	Title           string
+	Start           time.Time
+	End             time.Time
+	AllDay          bool
+	Category        string
+	Color           string
+	Tags            []string
+	Location        string
+	Notes           string
+	ReminderMinutes []int
+	ReminderType    string
+	RRule           string
+	Exdates         []time.Time
+	Pauses          []Pause
+	Timezone        string
+	Floating        bool
+	UID             string
+	SourceID        string
+	RecurrenceID    time.Time
+	Resource        string
+	Owner           string
+	Calendar        string
+	Organizer       string // organizer on whose behalf the owner acts, if any
+	Visibility      string
+	Updated         time.Time
+
+	// TravelBefore/TravelAfter extend the time the event blocks for
+	// availability checks; Start and End are unaffected.
+	TravelBefore time.Duration
+	TravelAfter  time.Duration
+
+	// EscalateEvery is the interval of the follow-ups of an undismissed
+	// reminder, at most EscalateMax of them; zero disables escalation.
+	EscalateEvery time.Duration
+	EscalateMax   int
+
+	// ReminderChannels are the channels the reminders go out through, empty
+	// for the defaults (see Channels).
+	ReminderChannels []string
+
+	// Sequence is the iCalendar SEQUENCE, bumped by significant changes (see
+	// SequenceFields).
+	Sequence int
+}
+
+// EventFromRecord maps an events record to an Event. Malformed JSON fields and
+// unparsable exdates are ignored rather than failing the whole expansion; the
+// validation hooks are responsible for rejecting them on save.
+func EventFromRecord(r *core.Record) *Event {
+	ev := &Event{
+		ID:       r.Id,
+		Title:    r.GetString("title"),
+		Start:    r.GetDateTime("start").Time(),
+		End:      r.GetDateTime("end").Time(),
+		AllDay:   r.GetBool("allDay"),
+		Category: r.GetString("category"),
+		Color:    r.GetString("color"),
+		Location: r.GetString("location"),
+		Notes:    r.GetString("notes"),
+		RRule:    r.GetString("rrule"),
+		Timezone: r.GetString("timezone"),
+		Floating: r.GetBool("floating"),
+		UID:      r.GetString("uid"),
+		SourceID: r.GetString("sourceId"),
+		Resource: r.GetString("resource"),
+		Owner:    r.GetString("owner"),
+		Calendar: r.GetString("calendar"),
+
+		Organizer:  r.GetString("organizer"),
+		Visibility: r.GetString("visibility"),
+		Updated:    r.GetDateTime("updated").Time(),
+
+		ReminderType: r.GetString("reminderType"),
+
+		RecurrenceID: r.GetDateTime("recurrenceId").Time(),
+		TravelBefore: time.Duration(r.GetInt("travelBeforeMinutes")) * time.Minute,
+		TravelAfter:  time.Duration(r.GetInt("travelAfterMinutes")) * time.Minute,
+
+		EscalateEvery: time.Duration(r.GetInt("escalateEveryMinutes")) * time.Minute,
+		EscalateMax:   r.GetInt("escalateMax"),
+
+		ReminderChannels: r.GetStringSlice("reminderChannels"),
+		Sequence:         r.GetInt("sequence"),
+	}
+
+	_ = r.UnmarshalJSONField("tags", &ev.Tags)
+	_ = r.UnmarshalJSONField("reminderMinutes", &ev.ReminderMinutes)
+
+	var exdates []string
+	_ = r.UnmarshalJSONField("exdates", &exdates)
+	for _, s := range exdates {
+		if t, err := ParseTime(s, time.UTC); err == nil {
+			ev.Exdates = append(ev.Exdates, t)
+		}
+	}
+
+	var pauses []map[string]string
+	_ = r.UnmarshalJSONField("pauses", &pauses)
+	for _, p := range pauses {
+		from, err1 := ParseTime(p["from"], time.UTC)
+		to, err2 := ParseTime(p["to"], time.UTC)
+		if err1 == nil && err2 == nil {
+			ev.Pauses = append(ev.Pauses, Pause{From: from, To: to})
+		}
+	}
+
+	return ev
+}
+
+// Escalates reports whether undismissed reminders of the event get follow-ups.
+func (ev *Event) Escalates() bool {
+	return ev.EscalateEvery > 0 && ev.EscalateMax > 0
+}
+
+// Duration returns the length of a single occurrence.
+func (ev *Event) Duration() time.Duration {
+	if d := ev.End.Sub(ev.Start); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// IsDetached reports whether the event is a detached occurrence overriding
+// one instance of a series.
+func (ev *Event) IsDetached() bool {
+	return ev.SourceID != ""
+}
+
+// IsRecurring reports whether the event carries a recurrence rule.
+func (ev *Event) IsRecurring() bool {
+	return ev.RRule != ""
+}
+
+// Zone returns the zone recurrences of the event are expanded in: the
+// event's own timezone when set and known, otherwise fallback (usually the
+// viewer's timezone). Floating events always use fallback.
+func (ev *Event) Zone(fallback *time.Location) *time.Location {
+	if ev.Timezone != "" && !ev.Floating {
+		if loc, err := time.LoadLocation(ev.Timezone); err == nil {
+			return loc
+		}
+	}
+	if fallback == nil {
+		return time.UTC
+	}
+	return fallback
+}
+
+// UserZone returns the timezone of an app user, fallback when user is nil
+// or has no (known) timezone.
+func UserZone(user *core.Record, fallback *time.Location) *time.Location {
+	if user == nil {
+		return fallback
+	}
+	if name := user.GetString("timezone"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return fallback
+}
+
+// ISO formats t like JavaScript's Date.toISOString, the format exdates are
+// stored in.
+func ISO(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// WallClock moves the wall clock time of t (in t's own location) into loc:
+// 08:00 UTC becomes 08:00 in loc. It is how the stored (UTC) times of floating
+// events are placed in the viewer's zone, and back.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// exdateSet holds the exdates of an event as the instance starts they
+// exclude in one zone, so an expansion looks each instance up instead of
+// scanning every exdate. All-day exdates are kept as the start of their day
+// in the zone, so one stored at UTC midnight or in another offset still
+// excludes its day. Floating exdates, like the event times, are wall clock
+// times. There is no rdate counterpart: RDATE isn't supported, so exdates
+// are the only date list to look up (and to cap, see Config.MaxExdates).
+type exdateSet map[int64]struct{}
+
+// newExdateSet builds the exdateSet of ev for instances in zone.
+func newExdateSet(ev *Event, zone *time.Location) exdateSet {
+	if len(ev.Exdates) == 0 {
+		return nil
+	}
+	set := make(exdateSet, len(ev.Exdates))
+	for _, x := range ev.Exdates {
+		if ev.Floating {
+			x = WallClock(x.UTC(), zone)
+		}
+		if ev.AllDay {
+			x = StartOfDay(x, zone)
+		}
+		set[x.UnixNano()] = struct{}{}
+	}
+	return set
+}
+
+// has reports whether the instance starting at t is excluded.
+func (s exdateSet) has(t time.Time) bool {
+	_, ok := s[t.UnixNano()]
+	return ok
+}
@@ -0,0 +1,124 @@
+package calendar
+
+import (
+	"time"
+
+	"schedule/recur"
+)
+
+// ExdateTolerance is how far an exdate may be off from the occurrence it is
+// meant to exclude and still be snapped to it. 14 hours covers an exdate sent
+// in the wrong UTC offset as well as lost seconds or milliseconds.
+const ExdateTolerance = 14 * time.Hour
+
+// SnapExdate returns the occurrence start of ev closest to t, as the exact
+// value an exdate must hold to exclude it (the wall clock time as UTC for
+// floating events). Already excluded occurrences still count. loc is the
+// fallback zone, as for Occurrences.
+func SnapExdate(ev *Event, t time.Time, loc *time.Location) (time.Time, bool) {
+	bare := *ev
+	bare.Exdates, bare.Pauses = nil, nil
+
+	var best time.Time
+	var bestDiff time.Duration
+	found := false
+	for _, o := range bare.Occurrences(t.Add(-ExdateTolerance), t.Add(ExdateTolerance+time.Second), loc) {
+		diff := o.Start.Sub(t).Abs()
+		if diff > ExdateTolerance || (found && diff >= bestDiff) {
+			continue
+		}
+		best, bestDiff, found = o.Start, diff, true
+	}
+	if found && ev.Floating {
+		best = WallClock(best.In(ev.Zone(loc)), time.UTC)
+	}
+	return best, found
+}
+
+// UnmatchedExdates returns the exdates of ev that are not the start of any of
+// its occurrences and so exclude nothing.
+func UnmatchedExdates(ev *Event, loc *time.Location) []time.Time {
+	bare := *ev
+	bare.Exdates, bare.Pauses = nil, nil
+
+	var out []time.Time
+	for _, x := range ev.Exdates {
+		at := x
+		if ev.Floating {
+			at = WallClock(x.UTC(), ev.Zone(loc))
+		}
+		if !occursAt(&bare, at, loc) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// maxOutsideScan caps the instances ExdatesOutside walks to find the last one
+// of a COUNT rule. Past it the rule is taken as unbounded on the right.
+const maxOutsideScan = 100000
+
+// ExdatesOutside returns the exdates of ev lying outside its series: more
+// than ExdateTolerance before the first instance, or after the last one of a
+// bounded rule (its UNTIL, read in the series zone when floating). Exdates
+// and pauses don't move the bounds. Nil for events whose rrule is empty or
+// invalid.
+func ExdatesOutside(ev *Event, loc *time.Location) []time.Time {
+	if !ev.IsRecurring() || len(ev.Exdates) == 0 {
+		return nil
+	}
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil {
+		return nil
+	}
+
+	zone := ev.Zone(loc)
+	first := ev.dtstart(zone)
+	instants := make([]time.Time, len(ev.Exdates))
+	var latest time.Time
+	for i, x := range ev.Exdates {
+		instants[i] = x
+		if ev.Floating {
+			instants[i] = WallClock(x.UTC(), zone)
+		}
+		if instants[i].After(latest) {
+			latest = instants[i]
+		}
+	}
+
+	var last time.Time
+	switch {
+	case rule.Count > 0:
+		// only the instances up to the latest exdate matter
+		last = lastInstance(rule.Iter(first), latest.Add(ExdateTolerance))
+	case !rule.Until.IsZero():
+		last = rule.UntilIn(first.Location())
+	}
+
+	var out []time.Time
+	for i, x := range ev.Exdates {
+		at := instants[i]
+		if at.Before(first.Add(-ExdateTolerance)) || (!last.IsZero() && at.After(last.Add(ExdateTolerance))) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// lastInstance returns the last instance it yields, zero when it goes on past
+// limit (which leaves every exdate inside) or for more than maxOutsideScan
+// instances.
+func lastInstance(it *recur.Iterator, limit time.Time) time.Time {
+	var last time.Time
+	for range maxOutsideScan {
+		t, ok := it.Next()
+		if !ok {
+			return last
+		}
+		if t.After(limit) {
+			return time.Time{}
+		}
+		last = t
+	}
+	return time.Time{}
+}
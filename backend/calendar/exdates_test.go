@@ -0,0 +1,163 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func mustZone(t testing.TB, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func utc(y int, m time.Month, d, h, min int) time.Time {
+	return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+}
+
+func TestExdatesOutside(t *testing.T) {
+	tokyo := mustZone(t, "Asia/Tokyo")
+	tests := []struct {
+		name string
+		ev   Event
+		loc  *time.Location
+		want []time.Time
+	}{
+		{
+			name: "inside a COUNT rule",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=5", Exdates: []time.Time{utc(2026, 3, 12, 9, 0), utc(2026, 3, 14, 9, 0)}},
+			loc:  time.UTC,
+		},
+		{
+			name: "after the last instance of a COUNT rule",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=5", Exdates: []time.Time{utc(2026, 3, 12, 9, 0), utc(2026, 3, 20, 9, 0)}},
+			loc:  time.UTC,
+			want: []time.Time{utc(2026, 3, 20, 9, 0)},
+		},
+		{
+			name: "before the first instance",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=WEEKLY", Exdates: []time.Time{utc(2026, 3, 1, 9, 0)}},
+			loc:  time.UTC,
+			want: []time.Time{utc(2026, 3, 1, 9, 0)},
+		},
+		{
+			name: "within the tolerance of the bounds",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=2", Exdates: []time.Time{utc(2026, 3, 10, 0, 0), utc(2026, 3, 11, 20, 0)}},
+			loc:  time.UTC,
+		},
+		{
+			name: "after UTC UNTIL",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;UNTIL=20260315T090000Z", Exdates: []time.Time{utc(2026, 3, 17, 9, 0)}},
+			loc:  time.UTC,
+			want: []time.Time{utc(2026, 3, 17, 9, 0)},
+		},
+		{
+			// the UNTIL is 09:00 in Tokyo, 00:00 UTC, not 09:00 UTC
+			name: "after a floating UNTIL in the series zone",
+			ev: Event{
+				Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), Floating: true,
+				RRule: "FREQ=DAILY;UNTIL=20260315T090000", Exdates: []time.Time{utc(2026, 3, 14, 9, 0), utc(2026, 3, 16, 1, 0)},
+			},
+			loc:  tokyo,
+			want: []time.Time{utc(2026, 3, 16, 1, 0)},
+		},
+		{
+			name: "unbounded rule",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY", Exdates: []time.Time{utc(2036, 3, 10, 9, 0)}},
+			loc:  time.UTC,
+		},
+		{
+			name: "single event",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), Exdates: []time.Time{utc(2020, 1, 1, 0, 0)}},
+			loc:  time.UTC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExdatesOutside(&tt.ev, tt.loc)
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Fatalf("ExdatesOutside = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A huge COUNT only walks the instances up to the latest exdate.
+func TestExdatesOutsideHugeCount(t *testing.T) {
+	ev := &Event{
+		Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 9, 1),
+		RRule: "FREQ=MINUTELY;COUNT=2000000000", Exdates: []time.Time{utc(2026, 3, 10, 10, 0)},
+	}
+	done := make(chan []time.Time)
+	go func() { done <- ExdatesOutside(ev, time.UTC) }()
+	select {
+	case got := <-done:
+		if len(got) != 0 {
+			t.Fatalf("expected no exdate outside, got %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExdatesOutside walked the whole COUNT")
+	}
+}
+
+func TestSnapExdate(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	daily := Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=10"}
+	tests := []struct {
+		name string
+		ev   Event
+		loc  *time.Location
+		at   time.Time
+		want time.Time
+		ok   bool
+	}{
+		{"exact", daily, time.UTC, utc(2026, 3, 12, 9, 0), utc(2026, 3, 12, 9, 0), true},
+		{"stray seconds", daily, time.UTC, utc(2026, 3, 12, 9, 0).Add(1500 * time.Millisecond), utc(2026, 3, 12, 9, 0), true},
+		{"wrong offset", daily, time.UTC, utc(2026, 3, 12, 10, 0), utc(2026, 3, 12, 9, 0), true},
+		{"closest of two", daily, time.UTC, utc(2026, 3, 12, 20, 0), utc(2026, 3, 12, 9, 0), true},
+		{"already excluded", Event{Start: daily.Start, End: daily.End, RRule: daily.RRule, Exdates: []time.Time{utc(2026, 3, 12, 9, 0)}}, time.UTC, utc(2026, 3, 12, 9, 1), utc(2026, 3, 12, 9, 0), true},
+		{"after the series", daily, time.UTC, utc(2026, 4, 1, 9, 0), time.Time{}, false},
+		{
+			// 09:00 in Berlin is 08:00 UTC; the exdate keeps the wall clock time
+			name: "floating",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), Floating: true, RRule: "FREQ=DAILY;COUNT=10"},
+			loc:  berlin,
+			at:   utc(2026, 3, 12, 8, 0),
+			want: utc(2026, 3, 12, 9, 0),
+			ok:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SnapExdate(&tt.ev, tt.at, tt.loc)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Fatalf("SnapExdate = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestUnmatchedExdates(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	ev := &Event{
+		Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=10",
+		Exdates: []time.Time{utc(2026, 3, 11, 9, 0), utc(2026, 3, 12, 10, 0), utc(2026, 3, 13, 9, 0).Add(time.Second)},
+	}
+	want := []time.Time{utc(2026, 3, 12, 10, 0), utc(2026, 3, 13, 9, 0).Add(time.Second)}
+	if got := UnmatchedExdates(ev, time.UTC); !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Fatalf("UnmatchedExdates = %v, want %v", got, want)
+	}
+
+	floating := &Event{
+		Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), Floating: true, RRule: "FREQ=DAILY;COUNT=10",
+		Exdates: []time.Time{utc(2026, 3, 11, 9, 0), utc(2026, 3, 12, 8, 0)},
+	}
+	want = []time.Time{utc(2026, 3, 12, 8, 0)} // an instant, not the wall clock time
+	if got := UnmatchedExdates(floating, berlin); !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Fatalf("floating UnmatchedExdates = %v, want %v", got, want)
+	}
+}
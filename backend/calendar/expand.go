@@ -0,0 +1,181 @@
+package calendar
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Occurrence is a concrete instance of an event. It mirrors the frontend
+// EventOccurrence shape: recurring instances get an "<id>::<ISO start>" id and
+// link back to their series through SourceID, as do detached occurrences
+// (which keep their own record id).
+type Occurrence struct {
+	ID              string    `json:"id"`
+	SourceID        string    `json:"sourceId,omitempty"`
+	Title           string    `json:"title"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	AllDay          bool      `json:"allDay"`
+	Category        string    `json:"category,omitempty"`
+	Color           string    `json:"color,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	Location        string    `json:"location,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+	ReminderMinutes []int     `json:"reminderMinutes,omitempty"`
+	RRule           string    `json:"rrule,omitempty"`
+
+	TravelBeforeMinutes int `json:"travelBeforeMinutes,omitempty"`
+	TravelAfterMinutes  int `json:"travelAfterMinutes,omitempty"`
+}
+
+// Busy returns the interval the occurrence blocks, travel buffers included.
+func (o Occurrence) Busy() (time.Time, time.Time) {
+	return o.Start.Add(-time.Duration(o.TravelBeforeMinutes) * time.Minute),
+		o.End.Add(time.Duration(o.TravelAfterMinutes) * time.Minute)
+}
+
+// Redacted is the occurrence with the details of a private event (title,
+// tags, location, notes, reminders) left out, for viewers other than its
+// owner: they just see the time it takes.
+func (o Occurrence) Redacted() Occurrence {
+	o.Title = RedactedTitle
+	o.Tags, o.Location, o.Notes, o.ReminderMinutes = nil, "", "", nil
+	return o
+}
+
+// OccurrenceID builds the id of a recurring instance the same way the frontend does.
+func OccurrenceID(eventID string, start time.Time) string {
+	return eventID + "::" + ISO(start)
+}
+
+func (ev *Event) occurrence(start, end time.Time) Occurrence {
+	o := Occurrence{
+		ID:              ev.ID,
+		Title:           ev.Title,
+		Start:           start.UTC(),
+		End:             end.UTC(),
+		AllDay:          ev.AllDay,
+		Category:        ev.Category,
+		Color:           ev.Color,
+		Tags:            ev.Tags,
+		Location:        ev.Location,
+		Notes:           ev.Notes,
+		ReminderMinutes: ev.ReminderMinutes,
+		RRule:           ev.RRule,
+
+		TravelBeforeMinutes: int(ev.TravelBefore / time.Minute),
+		TravelAfterMinutes:  int(ev.TravelAfter / time.Minute),
+	}
+	switch {
+	case ev.IsDetached():
+		o.SourceID = ev.SourceID
+	case ev.IsRecurring():
+		o.ID = OccurrenceID(ev.ID, start)
+		o.SourceID = ev.ID
+	}
+	return o
+}
+
+// dtstart is the anchor recurrences of the event are expanded from in loc.
+// All-day series are anchored at local midnight of their first day: the
+// iterator keeps that wall-clock time, so every instance starts at midnight
+// whatever the DST offset of its day. Floating events start at their stored
+// wall clock time in loc.
+func (ev *Event) dtstart(loc *time.Location) time.Time {
+	start := ev.Start
+	if ev.Floating {
+		start = WallClock(start.UTC(), loc)
+	}
+	if ev.AllDay {
+		return StartOfDay(start, loc)
+	}
+	return start.In(loc)
+}
+
+// endAt returns the end of the recurring instance starting at start. All-day
+// instances span whole calendar days in start's zone (the stored duration
+// rounded to days, at least one), so an instance on a day with a DST change
+// still ends at midnight instead of an hour early or late.
+func (ev *Event) endAt(start time.Time) time.Time {
+	if !ev.AllDay {
+		return start.Add(ev.Duration())
+	}
+	days := max(int(math.Round(ev.Duration().Hours()/24)), 1)
+	y, m, d := start.Date()
+	return time.Date(y, m, d+days, 0, 0, 0, 0, start.Location())
+}
+
+// overlaps reports whether [start, end) intersects [from, to). Zero-length
+// events count when their start falls inside the window.
+func overlaps(start, end, from, to time.Time) bool {
+	if !end.After(start) {
+		return !start.Before(from) && start.Before(to)
+	}
+	return start.Before(to) && end.After(from)
+}
+
+// Occurrences expands the event into the instances overlapping [from, to).
+// loc is the fallback zone for events without a timezone of their own.
+// An invalid rrule yields no instances.
+func (ev *Event) Occurrences(from, to time.Time, loc *time.Location) []Occurrence {
+	var out []Occurrence
+	c := newCursor(ev, from, to, loc)
+	for c.next() {
+		out = append(out, c.cur)
+	}
+	return out
+}
+
+// Expand expands every event into the occurrences overlapping [from, to),
+// sorted by start time (then by id for stability).
+func Expand(events []*Event, from, to time.Time, loc *time.Location) []Occurrence {
+	var out []Occurrence
+	_ = Stream(events, from, to, loc, func(o Occurrence) error {
+		out = append(out, o)
+		return nil
+	})
+	return out
+}
+
+// SortOccurrences orders occurrences by start time, then by id.
+func SortOccurrences(items []Occurrence) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+}
+
+func less(a, b Occurrence) bool {
+	if !a.Start.Equal(b.Start) {
+		return a.Start.Before(b.Start)
+	}
+	return a.ID < b.ID
+}
+
+// CountOccurrences returns how many instances of ev start in [from, to),
+// without building them. loc is the fallback zone, as in Occurrences.
+func (ev *Event) CountOccurrences(from, to time.Time, loc *time.Location) int {
+	instant := *ev
+	instant.End = instant.Start // zero length: only starts inside the window count
+	n := 0
+	for c := newCursor(&instant, from, to, loc); c.next(); {
+		n++
+	}
+	return n
+}
+
+// endOfTime bounds walks that are only ever stopped by the rule itself.
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// FirstOccurrence returns the earliest occurrence of ev, skipping exdated and
+// paused instances, without expanding the rest of the series: the walk stops
+// at the first instance kept. False when there is none (every instance of a
+// bounded rule skipped, or an invalid rrule). loc is the fallback zone, as in
+// Occurrences.
+func (ev *Event) FirstOccurrence(loc *time.Location) (Occurrence, bool) {
+	c := newCursor(ev, time.Time{}, endOfTime, loc)
+	if !c.next() {
+		return Occurrence{}, false
+	}
+	return c.cur, true
+}
@@ -0,0 +1,239 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// starts lists the UTC starts of occurrences.
+func starts(items []Occurrence) []time.Time {
+	out := make([]time.Time, len(items))
+	for i, o := range items {
+		out[i] = o.Start
+	}
+	return out
+}
+
+func TestOccurrencesAcrossDST(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	newYork := mustZone(t, "America/New_York")
+	// a traveler's calendar over the Berlin spring forward: medication at
+	// 08:00 wherever they are, and a call at 09:00 in Berlin
+	medication := Event{ID: "m", Start: utc(2026, 3, 28, 8, 0), End: utc(2026, 3, 28, 8, 15), Floating: true, RRule: "FREQ=DAILY;COUNT=2"}
+	call := Event{ID: "c", Start: utc(2026, 3, 28, 8, 0), End: utc(2026, 3, 28, 9, 0), RRule: "FREQ=DAILY;COUNT=2", Timezone: "Europe/Berlin"}
+	tests := []struct {
+		name     string
+		ev       Event
+		loc      *time.Location
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			// Berlin springs forward on 29 March 2026: 09:00 CET, then 09:00 CEST
+			name: "timed series keeps its wall clock time",
+			ev:   Event{ID: "s", Start: utc(2026, 3, 27, 8, 0), End: utc(2026, 3, 27, 9, 0), RRule: "FREQ=DAILY;COUNT=4", Timezone: "Europe/Berlin"},
+			loc:  time.UTC,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 27, 8, 0), utc(2026, 3, 28, 8, 0), utc(2026, 3, 29, 7, 0), utc(2026, 3, 30, 7, 0)},
+		},
+		{
+			// without a timezone the series follows the viewer's zone, New York
+			// falling back on 1 November 2026
+			name: "series without a timezone in the viewer's zone",
+			ev:   Event{ID: "s", Start: utc(2026, 10, 31, 13, 0), End: utc(2026, 10, 31, 14, 0), RRule: "FREQ=DAILY;COUNT=2"},
+			loc:  newYork,
+			from: utc(2026, 10, 1, 0, 0), to: utc(2026, 12, 1, 0, 0),
+			want: []time.Time{utc(2026, 10, 31, 13, 0), utc(2026, 11, 1, 14, 0)},
+		},
+		{
+			name: "floating series at the same wall clock time",
+			ev:   Event{ID: "f", Start: utc(2026, 3, 28, 9, 0), End: utc(2026, 3, 28, 10, 0), Floating: true, RRule: "FREQ=DAILY;COUNT=2"},
+			loc:  berlin,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 28, 8, 0), utc(2026, 3, 29, 7, 0)},
+		},
+		{
+			name: "traveler in Berlin: floating series",
+			ev:   medication,
+			loc:  berlin,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 28, 7, 0), utc(2026, 3, 29, 6, 0)},
+		},
+		{
+			name: "traveler in New York: floating series keeps its wall clock",
+			ev:   medication,
+			loc:  newYork,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 28, 12, 0), utc(2026, 3, 29, 12, 0)},
+		},
+		{
+			name: "traveler in Berlin: fixed-zone series",
+			ev:   call,
+			loc:  berlin,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 28, 8, 0), utc(2026, 3, 29, 7, 0)},
+		},
+		{
+			name: "traveler in New York: fixed-zone series keeps its instants",
+			ev:   call,
+			loc:  newYork,
+			from: utc(2026, 3, 1, 0, 0), to: utc(2026, 4, 1, 0, 0),
+			want: []time.Time{utc(2026, 3, 28, 8, 0), utc(2026, 3, 29, 7, 0)},
+		},
+		{
+			name: "hourly series through the skipped hour",
+			ev:   Event{ID: "h", Start: utc(2026, 3, 29, 0, 0), End: utc(2026, 3, 29, 0, 30), RRule: "FREQ=HOURLY;COUNT=3", Timezone: "Europe/Berlin"},
+			loc:  time.UTC,
+			from: utc(2026, 3, 28, 0, 0), to: utc(2026, 3, 30, 0, 0),
+			want: []time.Time{utc(2026, 3, 29, 0, 0), utc(2026, 3, 29, 1, 0), utc(2026, 3, 29, 2, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := starts(tt.ev.Occurrences(tt.from, tt.to, tt.loc))
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("starts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllDayOccurrences(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	// a daily all-day series over the spring forward day, anchored at
+	// midnight in Berlin
+	ev := &Event{
+		ID: "a", Start: utc(2026, 3, 27, 23, 0), End: utc(2026, 3, 28, 23, 0),
+		AllDay: true, RRule: "FREQ=DAILY;COUNT=3", Timezone: "Europe/Berlin",
+	}
+	got := ev.Occurrences(utc(2026, 3, 1, 0, 0), utc(2026, 4, 1, 0, 0), time.UTC)
+	want := [][2]time.Time{
+		{utc(2026, 3, 27, 23, 0), utc(2026, 3, 28, 23, 0)},
+		{utc(2026, 3, 28, 23, 0), utc(2026, 3, 29, 22, 0)}, // 23 hours long
+		{utc(2026, 3, 29, 22, 0), utc(2026, 3, 30, 22, 0)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d occurrences, want %d", len(got), len(want))
+	}
+	for i, o := range got {
+		if !o.AllDay || !o.Start.Equal(want[i][0]) || !o.End.Equal(want[i][1]) {
+			t.Errorf("occurrence %d = %v–%v (all day %v), want %v–%v", i, o.Start, o.End, o.AllDay, want[i][0], want[i][1])
+		}
+		if local := o.Start.In(berlin); local.Hour() != 0 || local.Minute() != 0 {
+			t.Errorf("occurrence %d starts at %v in Berlin, not midnight", i, local)
+		}
+	}
+
+	// a multi-day all-day event keeps its length in days
+	long := &Event{ID: "l", Start: utc(2026, 3, 28, 0, 0), End: utc(2026, 3, 31, 0, 0), AllDay: true, RRule: "FREQ=WEEKLY;COUNT=1"}
+	if got := long.Occurrences(utc(2026, 3, 1, 0, 0), utc(2026, 4, 1, 0, 0), berlin); len(got) != 1 || !got[0].End.Equal(utc(2026, 3, 30, 22, 0)) {
+		t.Fatalf("multi-day occurrence = %v, want it to end on 31 March in Berlin", got)
+	}
+}
+
+func TestOccurrencesWindowEdges(t *testing.T) {
+	from, to := utc(2026, 3, 10, 9, 0), utc(2026, 3, 10, 17, 0)
+	tests := []struct {
+		name string
+		ev   Event
+		want int
+	}{
+		{"ends at the window start", Event{ID: "e", Start: utc(2026, 3, 10, 8, 0), End: from}, 0},
+		{"starts at the window start", Event{ID: "e", Start: from, End: utc(2026, 3, 10, 10, 0)}, 1},
+		{"starts at the window end", Event{ID: "e", Start: to, End: utc(2026, 3, 10, 18, 0)}, 0},
+		{"ends at the window end", Event{ID: "e", Start: utc(2026, 3, 10, 16, 0), End: to}, 1},
+		{"spans the window", Event{ID: "e", Start: utc(2026, 3, 9, 0, 0), End: utc(2026, 3, 11, 0, 0)}, 1},
+		{"zero length at the window start", Event{ID: "e", Start: from, End: from}, 1},
+		{"zero length at the window end", Event{ID: "e", Start: to, End: to}, 0},
+		{
+			"series instance started before the window",
+			Event{ID: "s", Start: utc(2026, 3, 1, 8, 0), End: utc(2026, 3, 1, 10, 0), RRule: "FREQ=DAILY"},
+			1,
+		},
+		{
+			"series instances ending at the start and starting at the end",
+			Event{ID: "s", Start: utc(2026, 3, 1, 17, 0), End: utc(2026, 3, 2, 9, 0), RRule: "FREQ=DAILY"},
+			0,
+		},
+		{
+			"hourly series inside the window",
+			Event{ID: "s", Start: utc(2026, 3, 1, 0, 0), End: utc(2026, 3, 1, 0, 30), RRule: "FREQ=HOURLY;UNTIL=20260401T000000Z"},
+			8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.ev.Occurrences(from, to, time.UTC)); got != tt.want {
+				t.Fatalf("%d occurrences, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandOrder(t *testing.T) {
+	events := []*Event{
+		{ID: "b", Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=3"},
+		{ID: "a", Start: utc(2026, 3, 11, 9, 0), End: utc(2026, 3, 11, 9, 30)},
+		{ID: "c", Start: utc(2026, 3, 10, 8, 0), End: utc(2026, 3, 10, 8, 30), RRule: "FREQ=DAILY;COUNT=2", Exdates: []time.Time{utc(2026, 3, 11, 8, 0)}},
+	}
+	var got []string
+	for _, o := range Expand(events, utc(2026, 3, 1, 0, 0), utc(2026, 4, 1, 0, 0), time.UTC) {
+		got = append(got, o.ID)
+	}
+	want := []string{
+		"c::2026-03-10T08:00:00.000Z",
+		"b::2026-03-10T09:00:00.000Z",
+		"a",
+		"b::2026-03-11T09:00:00.000Z",
+		"b::2026-03-12T09:00:00.000Z",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Expand = %v, want %v", got, want)
+	}
+}
+
+// BenchmarkOccurrencesManyExdates expands a year of a daily series that
+// excludes every other day.
+func BenchmarkOccurrencesManyExdates(b *testing.B) {
+	ev := &Event{ID: "daily", Start: utc(2026, 1, 1, 9, 0), End: utc(2026, 1, 1, 10, 0), RRule: "FREQ=DAILY"}
+	for d := 0; d < 365; d += 2 {
+		ev.Exdates = append(ev.Exdates, ev.Start.AddDate(0, 0, d))
+	}
+	from, to := utc(2026, 1, 1, 0, 0), utc(2027, 1, 1, 0, 0)
+	b.ReportAllocs()
+	for b.Loop() {
+		if n := len(ev.Occurrences(from, to, time.UTC)); n != 182 {
+			b.Fatalf("%d occurrences, want 182", n)
+		}
+	}
+}
+
+// A daily all-day series without a timezone has one occurrence per calendar
+// day of the viewer across the spring forward date, each from midnight to
+// midnight. An exdate anywhere in a day excludes that day.
+func TestAllDaySeriesAcrossSpringForward(t *testing.T) {
+	newYork := mustZone(t, "America/New_York")
+	ev := &Event{
+		ID: "a", Start: utc(2026, 3, 6, 5, 0), End: utc(2026, 3, 7, 5, 0), AllDay: true, RRule: "FREQ=DAILY",
+		Exdates: []time.Time{utc(2026, 3, 9, 12, 0)},
+	}
+	from := time.Date(2026, 3, 6, 0, 0, 0, 0, newYork)
+	to := time.Date(2026, 3, 12, 0, 0, 0, 0, newYork)
+
+	var days []string
+	for _, o := range ev.Occurrences(from, to, newYork) {
+		start, end := o.Start.In(newYork), o.End.In(newYork)
+		if start.Hour() != 0 || end.Hour() != 0 || end.Day()-start.Day() != 1 {
+			t.Errorf("occurrence %v – %v doesn't span one local day", start, end)
+		}
+		days = append(days, start.Format(time.DateOnly))
+	}
+	want := []string{"2026-03-06", "2026-03-07", "2026-03-08", "2026-03-10", "2026-03-11"}
+	if !slices.Equal(days, want) {
+		t.Fatalf("days = %v, want %v", days, want)
+	}
+	if n := ev.CountOccurrences(from, to, newYork); n != len(want) {
+		t.Fatalf("CountOccurrences = %d, want %d", n, len(want))
+	}
+}
@@ -0,0 +1,270 @@
+package calendar
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/ics"
+)
+
+// ProductID is the PRODID of exported calendars.
+const ProductID = "-//schedule//schedule backend//EN"
+
+// SequenceFields are the events fields whose changes bump the SEQUENCE:
+// when and where the event takes place, the changes calendar clients must
+// treat as a new revision (RFC 5546, section 2.1.4).
+var SequenceFields = []string{"start", "end", "allDay", "timezone", "floating", "rrule", "exdates", "pauses", "location"}
+
+// NewVCalendar returns an empty VCALENDAR with the standard headers and an
+// optional display name.
+func NewVCalendar(name string) *ics.Component {
+	cal := ics.NewComponent("VCALENDAR").
+		Add("VERSION", "2.0").
+		Add("PRODID", ProductID).
+		Add("CALSCALE", "GREGORIAN")
+	if name != "" {
+		cal.AddText("X-WR-CALNAME", name)
+	}
+	return cal
+}
+
+// ExportICS writes the events as a VCALENDAR named name. Recurring events are
+// exported as their RRULE master, not expanded. Detached occurrences become
+// overrides of their series: VEVENTs with the master UID and a RECURRENCE-ID,
+// whose instances the master doesn't also list as EXDATE. organizers (see
+// FindOrganizers) adds the ORGANIZER of the events listed in it.
+func ExportICS(w io.Writer, events []*Event, name string, organizers map[string]Organizer) error {
+	byID := make(map[string]*Event, len(events))
+	overridden := map[string][]time.Time{}
+	for _, ev := range events {
+		byID[ev.ID] = ev
+		if ev.IsDetached() {
+			overridden[ev.SourceID] = append(overridden[ev.SourceID], ev.RecurrenceID)
+		}
+	}
+
+	cal := NewVCalendar(name)
+	stamp := time.Now()
+
+	// every zone TZID times refer to needs its VTIMEZONE, from the earliest
+	// start written in it
+	zones := map[string]*time.Location{}
+	earliest := map[string]time.Time{}
+	var zoneNames []string
+	for _, ev := range events {
+		loc := exportZone(ev)
+		if loc == nil {
+			continue
+		}
+		if t, ok := earliest[loc.String()]; !ok || ev.Start.Before(t) {
+			earliest[loc.String()] = ev.Start
+		}
+		if zones[loc.String()] == nil {
+			zones[loc.String()] = loc
+			zoneNames = append(zoneNames, loc.String())
+		}
+	}
+	slices.Sort(zoneNames)
+	for _, tzid := range zoneNames {
+		cal.AddChild(VTimezone(zones[tzid], earliest[tzid]))
+	}
+
+	for _, ev := range events {
+		if ev.IsDetached() {
+			vev := overrideVEvent(ev, byID[ev.SourceID], stamp)
+			organizers[ev.ID].addTo(vev)
+			cal.AddChild(vev)
+			continue
+		}
+		if ids := overridden[ev.ID]; len(ids) > 0 {
+			master := *ev
+			master.Exdates = slices.DeleteFunc(slices.Clone(ev.Exdates), func(x time.Time) bool {
+				return slices.ContainsFunc(ids, x.Equal)
+			})
+			ev = &master
+		}
+		if len(ev.Pauses) > 0 {
+			// pauses have no ICS form, their instances are exdated
+			paused := *ev
+			paused.Exdates = append(slices.Clone(ev.Exdates), PausedStarts(ev)...)
+			ev = &paused
+		}
+		vev := VEvent(ev, stamp)
+		organizers[ev.ID].addTo(vev)
+		cal.AddChild(vev)
+	}
+	return ics.Encode(w, cal)
+}
+
+// ExportOccurrencesICS writes the occurrences of the events overlapping
+// [from, to) as a VCALENDAR named name, for apps without RRULE support: every
+// instance of a series is a VEVENT of its own, with a UID derived from the
+// series and the instance start, and no RRULE or EXDATE. Skipped instances
+// are left out and detached occurrences written like single events. loc is
+// the fallback zone, as for Occurrences.
+func ExportOccurrencesICS(w io.Writer, events []*Event, from, to time.Time, loc *time.Location, name string, organizers map[string]Organizer) error {
+	var instances []*Event
+	for _, ev := range events {
+		if !ev.IsRecurring() {
+			if len(ev.Occurrences(from, to, loc)) > 0 {
+				instances = append(instances, ev)
+			}
+			continue
+		}
+		zone := ev.Zone(loc)
+		for _, o := range ev.Occurrences(from, to, loc) {
+			inst := *ev
+			inst.Start, inst.End = o.Start, o.End
+			if ev.AllDay || ev.Floating {
+				// written from the wall clock time as UTC, like stored ones
+				inst.Start, inst.End = WallClock(o.Start.In(zone), time.UTC), WallClock(o.End.In(zone), time.UTC)
+			}
+			inst.UID = ev.ID + "-" + ics.FormatUTC(o.Start) + "@schedule"
+			inst.RRule, inst.Exdates, inst.Pauses = "", nil, nil
+			instances = append(instances, &inst)
+		}
+	}
+	slices.SortStableFunc(instances, func(a, b *Event) int { return a.Start.Compare(b.Start) })
+
+	cal := NewVCalendar(name)
+	stamp := time.Now()
+	for _, inst := range instances {
+		vev := VEvent(inst, stamp)
+		organizers[inst.ID].addTo(vev)
+		cal.AddChild(vev)
+	}
+	return ics.Encode(w, cal)
+}
+
+// overrideVEvent maps the detached occurrence ev of master to an override
+// VEVENT. Without the master (not part of the export) the UID is derived from
+// the series id and the RECURRENCE-ID follows ev's own kind of times.
+func overrideVEvent(ev, master *Event, stamp time.Time) *ics.Component {
+	if master == nil {
+		master = &Event{ID: ev.SourceID, AllDay: ev.AllDay, Floating: ev.Floating}
+	}
+	vev := vevent(ev, UIDOf(master), stamp)
+	if master.AllDay {
+		return vev.Add("RECURRENCE-ID", ics.FormatDate(ev.RecurrenceID.UTC()), "VALUE", "DATE")
+	}
+	return addTime(vev, "RECURRENCE-ID", master, ev.RecurrenceID)
+}
+
+// exportZone is the zone the times of ev are written in with a TZID: that of
+// timed series with a known timezone other than UTC, whose instances follow
+// its DST changes. Other events are written in UTC (or floating), nil.
+func exportZone(ev *Event) *time.Location {
+	if !ev.IsRecurring() || ev.AllDay || ev.Floating || ev.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(ev.Timezone)
+	if err != nil || loc.String() == "UTC" {
+		return nil
+	}
+	return loc
+}
+
+// addTime adds the DATE-TIME property name of ev at t: with the TZID of its
+// export zone, floating or in UTC.
+func addTime(vev *ics.Component, name string, ev *Event, t time.Time) *ics.Component {
+	if loc := exportZone(ev); loc != nil {
+		return vev.Add(name, ics.FormatFloating(t.In(loc)), "TZID", loc.String())
+	}
+	return vev.Add(name, formatTime(ev, t))
+}
+
+// UIDOf returns the iCalendar UID of an event: the imported UID when known,
+// otherwise one derived from the record id.
+func UIDOf(ev *Event) string {
+	if ev.UID != "" {
+		return ev.UID
+	}
+	return ev.ID + "@schedule"
+}
+
+// formatTime formats a DATE-TIME of ev, floating for floating events (whose
+// stored UTC time is the wall clock time).
+func formatTime(ev *Event, t time.Time) string {
+	if ev.Floating {
+		return ics.FormatFloating(t.UTC())
+	}
+	return ics.FormatUTC(t)
+}
+
+// categoryValues lists the CATEGORIES of an event: its category first, then
+// its tags (minus one repeating the category).
+func categoryValues(ev *Event) []string {
+	values := []string{ev.Category}
+	for _, tag := range ev.Tags {
+		if !strings.EqualFold(tag, ev.Category) {
+			values = append(values, tag)
+		}
+	}
+	return values
+}
+
+// VEvent maps an event to a VEVENT component. Times are written in UTC,
+// those of timed series with a timezone with its TZID (the calendar needs
+// the matching VTimezone), floating events as floating times and all-day
+// events as DATE values. DTSTAMP is the last modification of the event,
+// stamp when unknown, so clients comparing SEQUENCE and DTSTAMP only take
+// actual changes as updates.
+func VEvent(ev *Event, stamp time.Time) *ics.Component {
+	return vevent(ev, UIDOf(ev), stamp)
+}
+
+func vevent(ev *Event, uid string, stamp time.Time) *ics.Component {
+	if !ev.Updated.IsZero() {
+		stamp = ev.Updated
+	}
+	vev := ics.NewComponent("VEVENT").
+		Add("UID", ics.EscapeText(uid)).
+		Add("DTSTAMP", ics.FormatUTC(stamp)).
+		Add("SEQUENCE", strconv.Itoa(ev.Sequence))
+
+	if ev.AllDay {
+		end := ev.End
+		if !end.After(ev.Start) {
+			end = ev.Start.AddDate(0, 0, 1)
+		}
+		vev.Add("DTSTART", ics.FormatDate(ev.Start.UTC()), "VALUE", "DATE")
+		vev.Add("DTEND", ics.FormatDate(end.UTC()), "VALUE", "DATE")
+	} else {
+		addTime(vev, "DTSTART", ev, ev.Start)
+		addTime(vev, "DTEND", ev, ev.End)
+	}
+
+	vev.AddText("SUMMARY", ev.Title)
+	vev.AddText("LOCATION", ev.Location)
+	vev.AddText("DESCRIPTION", ev.Notes)
+	vev.AddTextList("CATEGORIES", categoryValues(ev)...)
+
+	if ev.RRule != "" {
+		vev.Add("RRULE", ev.RRule)
+		for _, x := range ev.Exdates {
+			if ev.AllDay {
+				vev.Add("EXDATE", ics.FormatDate(x.UTC()), "VALUE", "DATE")
+			} else {
+				addTime(vev, "EXDATE", ev, x)
+			}
+		}
+	}
+
+	for _, m := range ev.ReminderMinutes {
+		alarm := ics.NewComponent("VALARM")
+		if ev.ReminderType == ReminderEmail {
+			// EMAIL alarms carry the message subject as SUMMARY
+			alarm.Add("ACTION", "EMAIL").AddText("SUMMARY", ev.Title)
+		} else {
+			alarm.Add("ACTION", "DISPLAY")
+		}
+		vev.AddChild(alarm.
+			AddText("DESCRIPTION", ev.Title).
+			Add("TRIGGER", "-PT"+strconv.Itoa(m)+"M"))
+	}
+
+	return vev
+}
@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"io"
+	"time"
+
+	"schedule/ics"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// ExternalCalendarsCollection stores the external ICS feeds users
+	// subscribe to, each mirrored into a read-only calendar.
+	ExternalCalendarsCollection = "external_calendars"
+
+	// MirrorKey is a custom (non-persisted) record key marking the saves of
+	// the mirror, the only writer of the events of external calendars, and
+	// of the server-side maintenance rewriting such events in place
+	// (NormalizeEvents, MergeCategory, Restore). Restored subscriptions carry
+	// it too, to keep their calendar.
+	MirrorKey = "@mirror"
+
+	// MinExternalRefresh and DefaultExternalRefresh are in minutes: the
+	// shortest refreshMinutes of a subscription, and the one of those
+	// leaving it empty.
+	MinExternalRefresh     = 15
+	DefaultExternalRefresh = 360
+)
+
+// MirrorResult summarises a MirrorICS: the import of the feed, plus the
+// events removed because they disappeared from it.
+type MirrorResult struct {
+	ImportResult
+	Removed int `json:"removed"`
+}
+
+// ExternalRefresh returns how often the subscription sub is fetched.
+func ExternalRefresh(sub *core.Record) time.Duration {
+	minutes := sub.GetInt("refreshMinutes")
+	if minutes <= 0 {
+		minutes = DefaultExternalRefresh
+	}
+	return time.Duration(max(minutes, MinExternalRefresh)) * time.Minute
+}
+
+// IsExternalCalendar reports whether the calendar with the given id mirrors
+// an external feed.
+func IsExternalCalendar(app core.App, id string) bool {
+	if id == "" {
+		return false
+	}
+	cal, err := app.FindRecordById(CalendarsCollection, id)
+	return err == nil && cal.GetBool("external")
+}
+
+// MirrorICS makes the events of the external calendar cal match the
+// iCalendar stream r, in one transaction: the VEVENTs are imported as by
+// ImportICS (unchanged ones are skipped), for the calendar owner, and the
+// events of cal that are no longer in the stream are deleted. When some
+// VEVENTs fail to import, the events of their UIDs are kept as they were
+// rather than dropped.
+func MirrorICS(app core.App, cal *core.Record, r io.Reader) (*MirrorResult, error) {
+	root, err := ics.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectCalendar(root); err != nil {
+		return nil, err
+	}
+
+	opts := ImportOptions{
+		Owner:     cal.GetString("owner"),
+		Calendar:  cal.Id,
+		AllDayEnd: AllDayEndAuto,
+		Mirror:    true,
+	}
+	uids := map[string]bool{}
+	for _, vev := range root.Components("VEVENT") {
+		uids[vev.Text("UID")] = true
+	}
+
+	result := &MirrorResult{}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		if err := importVEvents(txApp, root, opts, &result.ImportResult); err != nil {
+			return err
+		}
+
+		// detached occurrences first: deleting a series deletes its own
+		records, err := txApp.FindRecordsByFilter(EventsCollection, "calendar = {:calendar}", "-sourceId", 0, 0,
+			dbx.Params{"calendar": cal.Id})
+		if err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if result.seen[rec.Id] || (len(result.Errors) > 0 && uids[rec.GetString("uid")]) {
+				continue
+			}
+			if err := txApp.Delete(rec); err != nil {
+				return err
+			}
+			result.Removed++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
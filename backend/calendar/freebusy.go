@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Kinds of freebusy intervals.
+const (
+	BusyEvent  = "busy"   // the event itself
+	BusyTravel = "travel" // a travel buffer before or after it
+)
+
+// BusyInterval is one blocked interval of a freebusy answer.
+type BusyInterval struct {
+	ID    string    `json:"id"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Kind  string    `json:"kind"`
+}
+
+// Span is a plain [Start, End) interval.
+type Span struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// MergeBusy joins overlapping and touching intervals, whatever their kind,
+// into sorted spans.
+func MergeBusy(intervals []BusyInterval) []Span {
+	sorted := slices.Clone(intervals)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	out := []Span{}
+	for _, b := range sorted {
+		if n := len(out); n > 0 && !b.Start.After(out[n-1].End) {
+			if b.End.After(out[n-1].End) {
+				out[n-1].End = b.End
+			}
+			continue
+		}
+		out = append(out, Span{Start: b.Start, End: b.End})
+	}
+	return out
+}
+
+// FreeSpans returns the gaps of [from, to) left by busy, sorted spans as
+// MergeBusy returns them.
+func FreeSpans(busy []Span, from, to time.Time) []Span {
+	out := []Span{}
+	at := from
+	for _, b := range busy {
+		if b.Start.After(at) {
+			end := b.Start
+			if end.After(to) {
+				end = to
+			}
+			out = append(out, Span{Start: at, End: end})
+		}
+		if b.End.After(at) {
+			at = b.End
+		}
+		if !at.Before(to) {
+			return out
+		}
+	}
+	if at.Before(to) {
+		out = append(out, Span{Start: at, End: to})
+	}
+	return out
+}
+
+// FreeBusy turns occurrences into the blocked intervals overlapping
+// [from, to), sorted by start. Travel buffers are reported as their own
+// intervals next to the event time.
+func FreeBusy(items []Occurrence, from, to time.Time) []BusyInterval {
+	out := []BusyInterval{}
+	add := func(o Occurrence, start, end time.Time, kind string) {
+		if end.After(start) && overlaps(start, end, from, to) {
+			out = append(out, BusyInterval{ID: o.ID, Start: start, End: end, Kind: kind})
+		}
+	}
+
+	for _, o := range items {
+		busyFrom, busyTo := o.Busy()
+		add(o, busyFrom, o.Start, BusyTravel)
+		if overlaps(o.Start, o.End, from, to) {
+			out = append(out, BusyInterval{ID: o.ID, Start: o.Start, End: o.End, Kind: BusyEvent})
+		}
+		add(o, o.End, busyTo, BusyTravel)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out
+}
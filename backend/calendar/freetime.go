@@ -0,0 +1,134 @@
+package calendar
+
+import (
+	"slices"
+	"time"
+)
+
+// WorkHours are the working hours of a week: the time of day they start and
+// end, as offsets from midnight, on each of Days.
+type WorkHours struct {
+	Start time.Duration
+	End   time.Duration
+	Days  []time.Weekday
+}
+
+// Window returns the working hours of the day containing t in loc, or false
+// on a day off. The bounds are wall clock times, so a DST change doesn't move
+// them.
+func (w WorkHours) Window(t time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+	day := StartOfDay(t, loc)
+	if !slices.Contains(w.Days, day.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+	at := func(offset time.Duration) time.Time {
+		y, m, d := day.Date()
+		return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, loc)
+	}
+	return at(w.Start), at(w.End), true
+}
+
+// DayFree is the free time of one day within the working hours.
+type DayFree struct {
+	Date        string `json:"date"`
+	WorkMinutes int    `json:"workMinutes"`
+	BusyMinutes int    `json:"busyMinutes"`
+	FreeMinutes int    `json:"freeMinutes"`
+}
+
+// FreeTime sums, for each of the days days starting with the one containing
+// now, the working time (the part after now for that first day) not blocked
+// by items. Timed occurrences block their busy interval, travel buffers
+// included; all-day ones block nothing. Days off report zero.
+func FreeTime(items []Occurrence, w WorkHours, now time.Time, days int, loc *time.Location) []DayFree {
+	spans := busySpans(items)
+
+	first := StartOfDay(now, loc)
+	out := make([]DayFree, days)
+	for i := range out {
+		y, m, d := first.Date()
+		day := time.Date(y, m, d+i, 0, 0, 0, 0, loc)
+		out[i].Date = day.Format(time.DateOnly)
+
+		from, to, ok := w.Window(day, loc)
+		if !ok {
+			continue
+		}
+		if from.Before(now) {
+			from = now
+		}
+		if !to.After(from) {
+			continue
+		}
+
+		var blocked time.Duration
+		for _, s := range spans {
+			start, end := s.Start, s.End
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				blocked += end.Sub(start)
+			}
+		}
+		work := to.Sub(from)
+		out[i].WorkMinutes = int(work / time.Minute)
+		out[i].BusyMinutes = int(blocked / time.Minute)
+		out[i].FreeMinutes = int((work - blocked) / time.Minute)
+	}
+	return out
+}
+
+// NextFree returns the earliest free block of at least length within the
+// working hours of the days days starting with the one containing now (from
+// now on), blocked like in FreeTime. The span runs from the start of the
+// block to its end: the next busy time or the end of the working hours.
+// Blocks don't run across days; false when there is none.
+func NextFree(items []Occurrence, w WorkHours, now time.Time, days int, length time.Duration, loc *time.Location) (Span, bool) {
+	spans := busySpans(items)
+
+	first := StartOfDay(now, loc)
+	for i := range days {
+		y, m, d := first.Date()
+		from, to, ok := w.Window(time.Date(y, m, d+i, 0, 0, 0, 0, loc), loc)
+		if !ok {
+			continue
+		}
+		if from.Before(now) {
+			from = now
+		}
+
+		free := from
+		for _, s := range spans {
+			if !s.End.After(free) {
+				continue
+			}
+			if !s.Start.Before(to) {
+				break
+			}
+			if s.Start.Sub(free) >= length {
+				return Span{Start: free, End: s.Start}, true
+			}
+			free = s.End
+		}
+		if to.Sub(free) >= length {
+			return Span{Start: free, End: to}, true
+		}
+	}
+	return Span{}, false
+}
+
+// busySpans merges the busy intervals of the timed items, travel buffers
+// included; all-day ones block nothing.
+func busySpans(items []Occurrence) []Span {
+	var busy []BusyInterval
+	for _, o := range items {
+		if start, end := o.Busy(); !o.AllDay && end.After(start) {
+			busy = append(busy, BusyInterval{ID: o.ID, Start: start, End: end, Kind: BusyEvent})
+		}
+	}
+	return MergeBusy(busy)
+}
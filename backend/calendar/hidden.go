@@ -0,0 +1,65 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// HiddenEventsCollection stores the events (or single occurrences) a user hid
+// from their own views.
+const HiddenEventsCollection = "hidden_events"
+
+// Hidden is one hidden_events entry. A zero OccurrenceStart hides the whole event.
+type Hidden struct {
+	EventID         string
+	OccurrenceStart time.Time
+}
+
+// FindHidden returns the entries hidden by the given user.
+func FindHidden(app core.App, userID string) ([]Hidden, error) {
+	records, err := app.FindAllRecords(HiddenEventsCollection, dbx.HashExp{"user": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Hidden, len(records))
+	for i, r := range records {
+		out[i] = Hidden{
+			EventID:         r.GetString("eventId"),
+			OccurrenceStart: r.GetDateTime("occurrenceStart").Time(),
+		}
+	}
+	return out, nil
+}
+
+// FilterHidden drops the occurrences matching a hidden entry. An entry for a
+// series hides every instance (detached ones included) unless it is pinned to
+// one occurrence start.
+func FilterHidden(items []Occurrence, hidden []Hidden) []Occurrence {
+	if len(hidden) == 0 {
+		return items
+	}
+
+	out := items[:0]
+	for _, o := range items {
+		if !IsHidden(o, hidden) {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// IsHidden reports whether an occurrence matches one of the hidden entries.
+func IsHidden(o Occurrence, hidden []Hidden) bool {
+	for _, h := range hidden {
+		if h.EventID != o.ID && h.EventID != o.SourceID {
+			continue
+		}
+		if h.OccurrenceStart.IsZero() || h.OccurrenceStart.Equal(o.Start) {
+			return true
+		}
+	}
+	return false
+}
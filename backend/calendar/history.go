@@ -0,0 +1,88 @@
+package calendar
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// EventChangesCollection stores the change log of events.
+	EventChangesCollection = "event_changes"
+
+	// ChangedByKey is a custom (non-persisted) record key naming the auth
+	// record an event is saved on behalf of, for the change log.
+	ChangedByKey = "@changedBy"
+)
+
+// ignoredChangeFields are not worth a change log entry on their own.
+var ignoredChangeFields = map[string]bool{"updated": true, "importHash": true, "sequence": true}
+
+// FieldChange is the before/after value of one changed field.
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// SetChangedBy records auth as the actor of the next save of rec.
+func SetChangedBy(rec, auth *core.Record) {
+	if auth != nil {
+		rec.Set(ChangedByKey, auth)
+	}
+}
+
+// ChangedBy returns the actor set with SetChangedBy, if any.
+func ChangedBy(rec *core.Record) *core.Record {
+	auth, _ := rec.Get(ChangedByKey).(*core.Record)
+	return auth
+}
+
+// Diff lists the fields of rec that differ from its original (last saved)
+// state. Values are compared in their JSON form, so equal JSON fields and
+// dates don't show up as changes, and neither does null becoming empty.
+func Diff(rec *core.Record) map[string]FieldChange {
+	original := rec.Original()
+	out := map[string]FieldChange{}
+	for _, f := range rec.Collection().Fields {
+		name := f.GetName()
+		if ignoredChangeFields[name] || f.GetHidden() {
+			continue
+		}
+		before, after := original.Get(name), rec.Get(name)
+		b, _ := json.Marshal(before)
+		a, _ := json.Marshal(after)
+		if string(a) != string(b) && !(blankJSON(a) && blankJSON(b)) {
+			out[name] = FieldChange{From: before, To: after}
+		}
+	}
+	return out
+}
+
+// blankJSON treats null and empty JSON values alike, e.g. exdates going from
+// null to [].
+func blankJSON(v []byte) bool {
+	switch string(v) {
+	case "null", `""`, "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// TrimHistory deletes the change log entries created before cutoff, except
+// the keep most recent entries of each event. It returns how many were
+// deleted.
+func TrimHistory(app core.App, cutoff time.Time, keep int) (int64, error) {
+	res, err := app.DB().Delete(EventChangesCollection, dbx.NewExp(
+		"created < {:cutoff} AND id NOT IN ("+
+			"SELECT recent.id FROM "+EventChangesCollection+" AS recent"+
+			" WHERE recent.event = "+EventChangesCollection+".event"+
+			" ORDER BY recent.created DESC, recent.id DESC LIMIT {:keep})",
+		dbx.Params{"cutoff": dateParam(cutoff), "keep": keep},
+	)).Execute()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
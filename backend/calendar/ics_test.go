@@ -0,0 +1,304 @@
+package calendar_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func newUser(t testing.TB, app core.App, email string) *core.Record {
+	t.Helper()
+	users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := core.NewRecord(users)
+	rec.SetEmail(email)
+	rec.SetPassword("password123")
+	if err := app.Save(rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+// snapshot keys the owner's events by UID and recurrence id, without the
+// fields a new import assigns afresh. Single events are exported in UTC, so
+// their timezone, which doesn't move them, is left out too.
+func snapshot(t testing.TB, app core.App, owner string) map[string]calendar.Event {
+	t.Helper()
+	events, err := calendar.FindOwnedEvents(app, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]calendar.Event{}
+	for _, ev := range events {
+		key := ev.UID
+		if !ev.RecurrenceID.IsZero() {
+			key += "|" + calendar.ISO(ev.RecurrenceID)
+		}
+		snap := *ev
+		snap.ID, snap.Owner, snap.Calendar, snap.Updated = "", "", "", time.Time{}
+		if snap.SourceID != "" {
+			snap.SourceID = "series"
+		}
+		if !snap.IsRecurring() {
+			snap.Timezone = ""
+		}
+		out[key] = snap
+	}
+	return out
+}
+
+func export(t testing.TB, app core.App, owner string) []byte {
+	t.Helper()
+	events, err := calendar.FindOwnedEvents(app, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := calendar.ExportICS(&buf, events, "Schedule", nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestICSRoundTrip(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	first := newUser(t, app, "first@example.com")
+	second := newUser(t, app, "second@example.com")
+
+	fixture, err := os.Open("testdata/roundtrip.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fixture.Close()
+	res, err := calendar.ImportICS(app, fixture, calendar.ImportOptions{Owner: first.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 5 || res.Overrides != 1 || len(res.Errors) > 0 {
+		t.Fatalf("fixture import = %+v, want 5 events and 1 override", res)
+	}
+	imported := snapshot(t, app, first.Id)
+
+	// the spot checks of the fixture reading, the rest is compared below
+	standup := imported["standup@example.com"]
+	if standup.Timezone != "Europe/Berlin" || !standup.Start.Equal(time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("standup = %v in %q, want 09:00 in Berlin", standup.Start, standup.Timezone)
+	}
+	if want := []time.Time{time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 8, 0, 0, 0, time.UTC)}; !reflect.DeepEqual(standup.Exdates, want) {
+		t.Errorf("standup exdates = %v, want the EXDATE and the overridden instance %v", standup.Exdates, want)
+	}
+	if standup.Location != "Room 4, second floor" || standup.Notes != "Daily sync\nBring blockers" || !reflect.DeepEqual(standup.ReminderMinutes, []int{10}) {
+		t.Errorf("standup details = %q, %q, %v", standup.Location, standup.Notes, standup.ReminderMinutes)
+	}
+	if gym := imported["gym@example.com"]; !gym.Floating || gym.Start.Hour() != 7 {
+		t.Errorf("gym = %v floating %v, want floating 07:00", gym.Start, gym.Floating)
+	}
+	if retreat := imported["retreat@example.com"]; !retreat.AllDay || retreat.End.Sub(retreat.Start) != 72*time.Hour {
+		t.Errorf("retreat = %v–%v, want three whole days", retreat.Start, retreat.End)
+	}
+
+	// the export reads back into the same events for another owner
+	res, err = calendar.ImportICS(app, bytes.NewReader(export(t, app, first.Id)), calendar.ImportOptions{Owner: second.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 5 || res.Overrides != 1 || len(res.Errors) > 0 {
+		t.Fatalf("export import = %+v, want 5 events and 1 override", res)
+	}
+	again := snapshot(t, app, second.Id)
+	for key, want := range imported {
+		got, ok := again[key]
+		if !ok {
+			t.Errorf("%s missing after the round trip", key)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s after the round trip:\n got %+v\nwant %+v", key, got, want)
+		}
+	}
+	if len(again) != len(imported) {
+		t.Errorf("%d events after the round trip, want %d", len(again), len(imported))
+	}
+
+	// and importing it over the original matches every UID
+	res, err = calendar.ImportICS(app, bytes.NewReader(export(t, app, first.Id)), calendar.ImportOptions{Owner: first.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 0 || len(res.Errors) > 0 {
+		t.Fatalf("re-import = %+v, want no new events", res)
+	}
+	if got := snapshot(t, app, first.Id); len(got) != len(imported) {
+		t.Fatalf("%d events after the re-import, want %d", len(got), len(imported))
+	}
+}
+
+// A series edited in the app, without an imported UID, exports its detached
+// occurrence as an override that our importer links back to the series.
+func TestExportDetachedAsOverride(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	owner := newUser(t, app, "owner@example.com")
+	other := newUser(t, app, "other@example.com")
+
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(fields map[string]any) *core.Record {
+		rec := core.NewRecord(events)
+		rec.Load(fields)
+		rec.Set("owner", owner.Id)
+		if err := app.Save(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	series := save(map[string]any{
+		"title": "Standup", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 09:15:00.000Z",
+		"rrule": "FREQ=DAILY;COUNT=5", "exdates": []string{"2026-03-11T09:00:00.000Z", "2026-03-12T09:00:00.000Z"},
+	})
+	save(map[string]any{
+		"title": "Standup (late)", "start": "2026-03-12 11:00:00.000Z", "end": "2026-03-12 11:15:00.000Z",
+		"sourceId": series.Id, "recurrenceId": "2026-03-12 09:00:00.000Z",
+	})
+
+	exported := string(export(t, app, owner.Id))
+	uid := "UID:" + series.Id + "@schedule"
+	if n := strings.Count(exported, uid); n != 2 {
+		t.Fatalf("%s appears %d times, want on the master and the override:\n%s", uid, n, exported)
+	}
+	for _, want := range []string{"RECURRENCE-ID:20260312T090000Z", "EXDATE:20260311T090000Z", "SUMMARY:Standup (late)"} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("export lacks %s:\n%s", want, exported)
+		}
+	}
+	for _, line := range strings.Split(exported, "\r\n") {
+		if strings.HasPrefix(line, "EXDATE") && strings.Contains(line, "20260312T090000Z") {
+			t.Errorf("the overridden instance is also exdated: %s", line)
+		}
+	}
+
+	res, err := calendar.ImportICS(app, strings.NewReader(exported), calendar.ImportOptions{Owner: other.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 1 || res.Overrides != 1 || len(res.Errors) > 0 {
+		t.Fatalf("import = %+v, want a series and its override", res)
+	}
+	imported, err := calendar.FindOwnedEvents(app, other.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var master, detached *calendar.Event
+	for _, ev := range imported {
+		if ev.IsDetached() {
+			detached = ev
+		} else {
+			master = ev
+		}
+	}
+	if master == nil || detached == nil || detached.SourceID != master.ID {
+		t.Fatalf("import = %+v, want the override linked to the series", imported)
+	}
+	if !detached.RecurrenceID.Equal(time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("recurrence id = %v", detached.RecurrenceID)
+	}
+	// the overridden instance is rendered once, by the detached occurrence
+	occurrences := calendar.Expand(imported, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	var titles []string
+	for _, o := range occurrences {
+		titles = append(titles, o.Title)
+	}
+	if want := []string{"Standup", "Standup (late)", "Standup", "Standup"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("occurrences = %v, want %v", titles, want)
+	}
+}
+
+// A Google Calendar export lists the moved instance of a series as a second
+// VEVENT with its UID and a RECURRENCE-ID, here before the master and past a
+// DST change.
+func TestImportGoogleOverride(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	owner := newUser(t, app, "owner@example.com")
+
+	fixture, err := os.Open("testdata/recurring-google.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fixture.Close()
+	res, err := calendar.ImportICS(app, fixture, calendar.ImportOptions{Owner: owner.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 1 || res.Overrides != 1 || len(res.Errors) > 0 {
+		t.Fatalf("import = %+v, want a series and its override", res)
+	}
+	imported, err := calendar.FindOwnedEvents(app, owner.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var master, detached *calendar.Event
+	for _, ev := range imported {
+		if ev.IsDetached() {
+			detached = ev
+		} else {
+			master = ev
+		}
+	}
+	if master == nil || detached == nil {
+		t.Fatalf("import = %+v, want a series and a detached occurrence", imported)
+	}
+
+	// 10:00 and 14:00 in Berlin, in summer time
+	instance := time.Date(2026, 3, 31, 8, 0, 0, 0, time.UTC)
+	if detached.SourceID != master.ID {
+		t.Errorf("sourceId = %q, want the series %q", detached.SourceID, master.ID)
+	}
+	if !detached.RecurrenceID.Equal(instance) {
+		t.Errorf("recurrenceId = %v, want %v", detached.RecurrenceID, instance)
+	}
+	if !detached.Start.Equal(time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)) || detached.Title != "Weekly sync (moved)" {
+		t.Errorf("detached occurrence %q at %v", detached.Title, detached.Start)
+	}
+	if detached.RRule != "" || len(detached.Exdates) != 0 {
+		t.Errorf("detached occurrence with rrule %q and exdates %v", detached.RRule, detached.Exdates)
+	}
+	if len(master.Exdates) != 1 || !master.Exdates[0].Equal(instance) {
+		t.Errorf("series exdates = %v, want the moved instance %v", master.Exdates, instance)
+	}
+	if master.UID != detached.UID || master.Timezone != "Europe/Berlin" || master.RRule != "FREQ=WEEKLY;COUNT=6;BYDAY=TU" {
+		t.Errorf("series %q in %q with rule %q", master.UID, master.Timezone, master.RRule)
+	}
+
+	occurrences := calendar.Expand(imported, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.UTC)
+	var titles []string
+	for _, o := range occurrences {
+		titles = append(titles, o.Title)
+	}
+	if want := []string{"Weekly sync", "Weekly sync", "Weekly sync", "Weekly sync (moved)", "Weekly sync", "Weekly sync"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("occurrences = %v, want %v", titles, want)
+	}
+}
@@ -0,0 +1,531 @@
+package calendar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/ics"
+	"schedule/recur"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ImportResult summarises an ICS import.
+type ImportResult struct {
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Overrides int      `json:"overrides"`
+	Skipped   int      `json:"skipped"`
+	Errors    []string `json:"errors,omitempty"`
+
+	seen map[string]bool // ids of the events in the stream
+}
+
+// ImportICS imports the VEVENTs of an iCalendar stream.
+//
+// Masters are imported first. VEVENTs carrying a RECURRENCE-ID override one
+// instance of the series with the same UID: they become detached occurrences
+// linked through sourceId, and the replaced instance is added to the series
+// exdates so it is not rendered twice. Events already imported with the same
+// UID are updated in place, and unchanged ones (same importHash) are skipped.
+//
+// Invalid VEVENTs are reported in the result instead of failing the import.
+func ImportICS(app core.App, r io.Reader, opts ImportOptions) (*ImportResult, error) {
+	root, err := ics.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectCalendar(root); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	err = app.RunInTransaction(func(txApp core.App) error {
+		return importVEvents(txApp, root, opts, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// expectCalendar rejects a stream whose root isn't a VCALENDAR.
+func expectCalendar(root *ics.Component) error {
+	if root.Name != "VCALENDAR" {
+		return errors.New("ics: expected a VCALENDAR")
+	}
+	return nil
+}
+
+// importVEvents imports the VEVENTs of root into result (see ImportICS),
+// recording the id of every event created, updated or left unchanged.
+func importVEvents(txApp core.App, root *ics.Component, opts ImportOptions, result *ImportResult) error {
+	fallback := time.UTC
+	if name := root.Text("X-WR-TIMEZONE"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			fallback = loc
+		}
+	}
+
+	var masters, overrides []*ics.Component
+	for _, vev := range root.Components("VEVENT") {
+		if vev.Prop("RECURRENCE-ID") != nil {
+			overrides = append(overrides, vev)
+		} else {
+			masters = append(masters, vev)
+		}
+	}
+
+	inclusive := opts.AllDayEnd == AllDayEndInclusive ||
+		(opts.AllDayEnd == AllDayEndAuto && inclusiveAllDayEnds(root.Components("VEVENT")))
+
+	result.seen = map[string]bool{}
+
+	collection, err := txApp.FindCollectionByNameOrId(EventsCollection)
+	if err != nil {
+		return err
+	}
+
+	series := map[string]*core.Record{}
+
+	for _, vev := range masters {
+		uid := vev.Text("UID")
+
+		rec := opts.findByUID(txApp, uid)
+		if rec != nil && rec.GetString("importHash") == importHash(vev) {
+			series[uid] = rec
+			result.seen[rec.Id] = true
+			result.Skipped++
+			continue
+		}
+
+		isNew := rec == nil
+		if isNew {
+			rec = core.NewRecord(collection)
+		}
+		if err := applyVEvent(rec, vev, fallback, inclusive); err != nil {
+			result.Errors = append(result.Errors, describe(vev, err))
+			continue
+		}
+		opts.apply(rec)
+		if err := txApp.Save(rec); err != nil {
+			result.Errors = append(result.Errors, describe(vev, err))
+			continue
+		}
+
+		if isNew {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.seen[rec.Id] = true
+		if uid != "" {
+			series[uid] = rec
+		}
+	}
+
+	for _, vev := range overrides {
+		uid := vev.Text("UID")
+
+		recurrenceID, _, err := vev.Prop("RECURRENCE-ID").Time(fallback)
+		if err != nil {
+			result.Errors = append(result.Errors, describe(vev, err))
+			continue
+		}
+
+		parent := series[uid]
+		if parent == nil {
+			parent = opts.findByUID(txApp, uid)
+		}
+
+		var rec *core.Record
+		if parent != nil {
+			rec = findOverride(txApp, parent.Id, recurrenceID)
+		}
+		if rec != nil && rec.GetString("importHash") == importHash(vev) {
+			// the series may have been re-imported with fresh exdates
+			if addExdate(parent, recurrenceID) {
+				opts.mark(parent)
+				if err := txApp.Save(parent); err != nil {
+					return err
+				}
+			}
+			result.seen[rec.Id] = true
+			result.Skipped++
+			continue
+		}
+		if rec == nil {
+			rec = core.NewRecord(collection)
+		}
+
+		if err := applyVEvent(rec, vev, fallback, inclusive); err != nil {
+			result.Errors = append(result.Errors, describe(vev, err))
+			continue
+		}
+		opts.apply(rec)
+
+		// an override without its series is kept as a standalone event
+		if parent == nil {
+			if err := txApp.Save(rec); err != nil {
+				result.Errors = append(result.Errors, describe(vev, err))
+				continue
+			}
+			result.seen[rec.Id] = true
+			result.Created++
+			continue
+		}
+
+		rec.Set("rrule", "")
+		rec.Set("exdates", []string{})
+		rec.Set("sourceId", parent.Id)
+		rec.Set("recurrenceId", recurrenceID)
+		if err := txApp.Save(rec); err != nil {
+			result.Errors = append(result.Errors, describe(vev, err))
+			continue
+		}
+
+		if addExdate(parent, recurrenceID) {
+			opts.mark(parent)
+			if err := txApp.Save(parent); err != nil {
+				return err
+			}
+		}
+		result.seen[rec.Id] = true
+		result.Overrides++
+	}
+
+	return nil
+}
+
+// AllDayEnd values: how the DATE DTEND of all-day events is read. RFC 5545
+// (and Google, Apple and Outlook exports) make it exclusive; some producers
+// write the last day instead, which makes single-day events end when they
+// start. Auto reads a calendar as inclusive when one of its all-day events
+// has DTEND on its DTSTART day.
+const (
+	AllDayEndExclusive = "exclusive"
+	AllDayEndInclusive = "inclusive"
+	AllDayEndAuto      = "auto"
+)
+
+// ImportOptions sets fields on every imported event and how they are read.
+// Empty values leave the field to the defaults (hooks) or, on re-imports, as
+// it was; an empty AllDayEnd is exclusive.
+//
+// Mirror imports into the mirror Calendar of an external feed (see
+// MirrorICS): UIDs are only matched in that calendar, as the same feed may be
+// mirrored by several users, and the saves are marked as the mirror's.
+type ImportOptions struct {
+	Owner     string
+	Calendar  string
+	AllDayEnd string
+	Mirror    bool
+}
+
+func (o ImportOptions) apply(rec *core.Record) {
+	if o.Owner != "" {
+		rec.Set("owner", o.Owner)
+	}
+	if o.Calendar != "" {
+		rec.Set("calendar", o.Calendar)
+	}
+	o.mark(rec)
+}
+
+// mark flags a save of a mirror import, see MirrorKey.
+func (o ImportOptions) mark(rec *core.Record) {
+	if o.Mirror {
+		rec.Set(MirrorKey, true)
+	}
+}
+
+// findByUID returns the series (non-detached) event with the given UID, if
+// any, among the events of the importing owner (the unowned ones when Owner
+// is empty) and of the target calendar when one is set. Mirror imports only
+// match their own calendar.
+func (o ImportOptions) findByUID(app core.App, uid string) *core.Record {
+	if uid == "" {
+		return nil
+	}
+	filter := "uid = {:uid} && sourceId = '' && owner = {:owner}"
+	params := dbx.Params{"uid": uid, "owner": o.Owner}
+	if o.Mirror || o.Calendar != "" {
+		filter, params["calendar"] = filter+" && calendar = {:calendar}", o.Calendar
+	}
+	rec, err := app.FindFirstRecordByFilter(EventsCollection, filter, params)
+	if err != nil {
+		return nil
+	}
+	return rec
+}
+
+// findOverride returns the detached occurrence replacing the given instance of a series.
+func findOverride(app core.App, parentID string, recurrenceID time.Time) *core.Record {
+	rec, err := app.FindFirstRecordByFilter(
+		EventsCollection,
+		"sourceId = {:parent} && recurrenceId = {:rid}",
+		dbx.Params{"parent": parentID, "rid": dateParam(recurrenceID)},
+	)
+	if err != nil {
+		return nil
+	}
+	return rec
+}
+
+// addExdate appends t to the record exdates unless it is already present.
+func addExdate(rec *core.Record, t time.Time) bool {
+	var exdates []string
+	_ = rec.UnmarshalJSONField("exdates", &exdates)
+	for _, x := range exdates {
+		if parsed, err := ParseTime(x, time.UTC); err == nil && parsed.Equal(t) {
+			return false
+		}
+	}
+	rec.Set("exdates", append(exdates, ISO(t)))
+	return true
+}
+
+// inclusiveAllDayEnds reports whether the all-day VEVENTs of a calendar use
+// inclusive ends: one ending on the day it starts gives the convention away,
+// as an exclusive end can't.
+func inclusiveAllDayEnds(vevents []*ics.Component) bool {
+	for _, vev := range vevents {
+		dtstart, dtend := vev.Prop("DTSTART"), vev.Prop("DTEND")
+		if dtstart == nil || dtend == nil || !isDate(dtstart) || !isDate(dtend) {
+			continue
+		}
+		if dtstart.Value == dtend.Value {
+			return true
+		}
+	}
+	return false
+}
+
+// isDate reports whether p holds a DATE rather than a DATE-TIME.
+func isDate(p *ics.Property) bool {
+	return strings.EqualFold(p.Param("VALUE"), "DATE") || !strings.Contains(p.Value, "T")
+}
+
+// applyVEvent copies the supported VEVENT properties onto an events record.
+// With inclusive, the DATE DTEND of an all-day event names its last day.
+func applyVEvent(rec *core.Record, vev *ics.Component, fallback *time.Location, inclusive bool) error {
+	dtstart := vev.Prop("DTSTART")
+	if dtstart == nil {
+		return errors.New("missing DTSTART")
+	}
+
+	// a DATE-TIME without Z or TZID is floating: keep its wall clock time as
+	// UTC instead of pinning it to the import timezone
+	floating := !strings.HasSuffix(dtstart.Value, "Z") && dtstart.Param("TZID") == "" &&
+		!strings.EqualFold(dtstart.Param("VALUE"), "DATE") && strings.Contains(dtstart.Value, "T")
+	if floating {
+		fallback = time.UTC
+	}
+
+	start, allDay, err := dtstart.Time(fallback)
+	if err != nil {
+		return err
+	}
+
+	end := start
+	if p := vev.Prop("DTEND"); p != nil {
+		if end, _, err = p.Time(fallback); err != nil {
+			return err
+		}
+		if allDay && inclusive && isDate(p) {
+			end = end.AddDate(0, 0, 1)
+		}
+	} else if v := vev.Text("DURATION"); v != "" {
+		d, err := ics.Duration(v)
+		if err != nil {
+			return err
+		}
+		end = start.Add(d)
+	} else if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if end.Before(start) {
+		return errors.New("DTEND before DTSTART")
+	}
+
+	rrule := ""
+	if p := vev.Prop("RRULE"); p != nil {
+		if _, err := recur.Parse(p.Value); err != nil {
+			return err
+		}
+		rrule = p.Value
+	}
+
+	exdates := []string{}
+	for _, p := range vev.Props("EXDATE") {
+		times, err := p.Times(fallback)
+		if err != nil {
+			return err
+		}
+		for _, t := range times {
+			exdates = append(exdates, ISO(t))
+		}
+	}
+
+	timezone := ""
+	if tzid := dtstart.Param("TZID"); tzid != "" && !allDay {
+		if _, err := time.LoadLocation(tzid); err == nil {
+			timezone = tzid
+		}
+	}
+
+	title := truncate(vev.Text("SUMMARY"), 255)
+	if title == "" {
+		title = "(untitled)"
+	}
+
+	rec.Set("title", title)
+	rec.Set("start", start)
+	rec.Set("end", end)
+	rec.Set("allDay", allDay)
+	rec.Set("floating", floating)
+	rec.Set("location", truncate(vev.Text("LOCATION"), 255))
+	rec.Set("notes", truncate(vev.Text("DESCRIPTION"), 1000))
+	rec.Set("rrule", rrule)
+	rec.Set("exdates", exdates)
+	rec.Set("timezone", timezone)
+	rec.Set("reminderMinutes", alarmMinutes(vev))
+	rec.Set("reminderType", alarmType(vev))
+	rec.Set("uid", truncate(vev.Text("UID"), 255))
+	rec.Set("organizer", organizerEmail(vev))
+	if values := vev.TextList("CATEGORIES"); len(values) > 0 {
+		category, tags := splitCategories(values, knownCategories(rec.Collection()))
+		rec.Set("category", category)
+		rec.Set("tags", tags)
+	}
+	if n, err := strconv.Atoi(vev.Text("SEQUENCE")); err == nil && n >= 0 {
+		rec.Set("sequence", n)
+	}
+	rec.Set("importHash", importHash(vev))
+
+	return nil
+}
+
+// alarmMinutes converts relative VALARM triggers into minutes before start.
+func alarmMinutes(vev *ics.Component) []int {
+	out := []int{}
+	for _, alarm := range vev.Components("VALARM") {
+		trigger := alarm.Prop("TRIGGER")
+		if trigger == nil || strings.EqualFold(trigger.Param("VALUE"), "DATE-TIME") {
+			continue
+		}
+		d, err := ics.Duration(trigger.Value)
+		if err != nil || d > 0 {
+			continue
+		}
+		out = append(out, int(-d/time.Minute))
+	}
+	sort.Ints(out)
+	return out
+}
+
+// knownCategories returns the values the events category field accepts.
+func knownCategories(collection *core.Collection) []string {
+	if f, ok := collection.Fields.GetByName("category").(*core.SelectField); ok {
+		return f.Values
+	}
+	return nil
+}
+
+// splitCategories maps CATEGORIES values onto category and tags: the first
+// value matching a known category (case-insensitively) becomes the category,
+// in its canonical spelling, and every other value a tag. Tags are
+// de-duplicated case-insensitively, keeping the first spelling.
+func splitCategories(values, known []string) (string, []string) {
+	category := ""
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if category == "" {
+			if i := slices.IndexFunc(known, func(k string) bool { return strings.EqualFold(k, v) }); i >= 0 {
+				category = known[i]
+				continue
+			}
+		}
+		if key := strings.ToLower(v); !seen[key] {
+			seen[key] = true
+			tags = append(tags, v)
+		}
+	}
+	return category, tags
+}
+
+// alarmType maps the ACTION of the first reminder-like VALARM to a
+// reminderType. Anything but EMAIL (DISPLAY, AUDIO, none) is a display
+// reminder.
+func alarmType(vev *ics.Component) string {
+	for _, alarm := range vev.Components("VALARM") {
+		if alarm.Prop("TRIGGER") == nil {
+			continue
+		}
+		if strings.EqualFold(alarm.Text("ACTION"), "EMAIL") {
+			return ReminderEmail
+		}
+		return ReminderDisplay
+	}
+	return ReminderDisplay
+}
+
+// importHash fingerprints a VEVENT, ignoring DTSTAMP which changes on every export.
+func importHash(vev *ics.Component) string {
+	h := sha256.New()
+	var write func(c *ics.Component)
+	write = func(c *ics.Component) {
+		fmt.Fprintf(h, "BEGIN:%s\n", c.Name)
+		for _, p := range c.Properties {
+			if p.Name == "DTSTAMP" {
+				continue
+			}
+			keys := make([]string, 0, len(p.Params))
+			for k := range p.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			fmt.Fprint(h, p.Name)
+			for _, k := range keys {
+				fmt.Fprintf(h, ";%s=%s", k, p.Params[k])
+			}
+			fmt.Fprintf(h, ":%s\n", p.Value)
+		}
+		for _, child := range c.Children {
+			write(child)
+		}
+		fmt.Fprintf(h, "END:%s\n", c.Name)
+	}
+	write(vev)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func describe(vev *ics.Component, err error) string {
+	if uid := vev.Text("UID"); uid != "" {
+		return uid + ": " + err.Error()
+	}
+	return vev.Text("SUMMARY") + ": " + err.Error()
+}
+
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
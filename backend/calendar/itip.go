@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"schedule/ics"
+)
+
+// ReplyICS writes an iMIP reply (RFC 6047, METHOD:REPLY) carrying the
+// attendee's PARTSTAT for ev. uid is the UID of the invitation; for a detached
+// occurrence it is the series UID and the reply is pinned with RECURRENCE-ID.
+// The ORGANIZER is omitted when unknown. SEQUENCE is that of the event, so
+// the organizer matches the reply to the revision it answers.
+func ReplyICS(w io.Writer, ev *Event, uid string, organizer Organizer, a Attendee) error {
+	cal := NewVCalendar("").Add("METHOD", "REPLY")
+
+	vev := ics.NewComponent("VEVENT").
+		Add("UID", ics.EscapeText(uid)).
+		Add("DTSTAMP", ics.FormatUTC(time.Now())).
+		Add("SEQUENCE", strconv.Itoa(ev.Sequence))
+
+	if ev.IsDetached() && !ev.RecurrenceID.IsZero() {
+		vev.Add("RECURRENCE-ID", ics.FormatUTC(ev.RecurrenceID))
+	}
+	if ev.AllDay {
+		vev.Add("DTSTART", ics.FormatDate(ev.Start.UTC()), "VALUE", "DATE")
+	} else {
+		vev.Add("DTSTART", ics.FormatUTC(ev.Start))
+		vev.Add("DTEND", ics.FormatUTC(ev.End))
+	}
+	vev.AddText("SUMMARY", ev.Title)
+
+	organizer.addTo(vev)
+
+	params := []string{"PARTSTAT", a.Status}
+	if a.Name != "" {
+		params = append(params, "CN", a.Name)
+	}
+	vev.Add("ATTENDEE", "mailto:"+a.Email, params...)
+
+	cal.AddChild(vev)
+	return ics.Encode(w, cal)
+}
@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// minLayoutSpan is how long a zero-length occurrence counts for in Layout, so
+// it takes a column next to the events it sits in instead of a line on top.
+const minLayoutSpan = time.Minute
+
+// Placement is the column of an occurrence in a timeline: Column is 0-based,
+// ColumnsInGroup is the number of columns of its group, the chain of
+// occurrences overlapping it directly or through one another, so every
+// occurrence of a group gets the same width.
+type Placement struct {
+	Column         int `json:"column"`
+	ColumnsInGroup int `json:"columnsInGroup"`
+}
+
+// Layout places the occurrences in [from, to), clipped to it, in columns so
+// that overlapping ones sit side by side, for a day or week timeline. It
+// colors the interval graph greedily: in start order (longest first on equal
+// starts) each occurrence takes the lowest column free at its start, which
+// uses as few columns as the most occurrences overlapping at one time. A group
+// is closed when an occurrence starts after every end seen so far. All-day
+// occurrences are not placed (Placement is zero for them) and neither are
+// those outside the window.
+func Layout(items []Occurrence, from, to time.Time) []Placement {
+	type span struct {
+		i          int
+		start, end time.Time
+	}
+	spans := make([]span, 0, len(items))
+	for i, o := range items {
+		if o.AllDay {
+			continue
+		}
+		start, end := o.Start, o.End
+		if end.Before(start.Add(minLayoutSpan)) {
+			end = start.Add(minLayoutSpan)
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !end.After(start) {
+			continue
+		}
+		spans = append(spans, span{i, start, end})
+	}
+	sort.SliceStable(spans, func(a, b int) bool {
+		if !spans[a].start.Equal(spans[b].start) {
+			return spans[a].start.Before(spans[b].start)
+		}
+		return spans[a].end.After(spans[b].end)
+	})
+
+	out := make([]Placement, len(items))
+	var (
+		columns  []time.Time // end of the last occurrence in each column of the group
+		group    []int       // items of the group
+		groupEnd time.Time
+	)
+	closeGroup := func() {
+		for _, i := range group {
+			out[i].ColumnsInGroup = len(columns)
+		}
+		columns, group = columns[:0], group[:0]
+	}
+	for _, s := range spans {
+		if len(group) > 0 && !s.start.Before(groupEnd) {
+			closeGroup()
+		}
+		col := len(columns)
+		for c, end := range columns {
+			if !s.start.Before(end) {
+				col = c
+				break
+			}
+		}
+		if col == len(columns) {
+			columns = append(columns, s.end)
+		} else {
+			columns[col] = s.end
+		}
+		out[s.i].Column = col
+		group = append(group, s.i)
+		if len(group) == 1 || s.end.After(groupEnd) {
+			groupEnd = s.end
+		}
+	}
+	closeGroup()
+	return out
+}
@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLayout(t *testing.T) {
+	from, to := utc(2026, 3, 10, 0, 0), utc(2026, 3, 11, 0, 0)
+	timed := func(fromHour, fromMin, toHour, toMin int) Occurrence {
+		return Occurrence{Start: utc(2026, 3, 10, fromHour, fromMin), End: utc(2026, 3, 10, toHour, toMin)}
+	}
+	p := func(column, columns int) Placement {
+		return Placement{Column: column, ColumnsInGroup: columns}
+	}
+
+	tests := []struct {
+		name  string
+		items []Occurrence
+		want  []Placement
+	}{
+		{
+			name:  "apart",
+			items: []Occurrence{timed(9, 0, 10, 0), timed(11, 0, 12, 0)},
+			want:  []Placement{p(0, 1), p(0, 1)},
+		},
+		{
+			name:  "back to back share a column",
+			items: []Occurrence{timed(9, 0, 10, 0), timed(10, 0, 11, 0)},
+			want:  []Placement{p(0, 1), p(0, 1)},
+		},
+		{
+			name:  "overlapping",
+			items: []Occurrence{timed(9, 0, 11, 0), timed(10, 0, 12, 0)},
+			want:  []Placement{p(0, 2), p(1, 2)},
+		},
+		{
+			// the two inner ones don't overlap each other, they share the
+			// second column
+			name:  "nested",
+			items: []Occurrence{timed(9, 0, 17, 0), timed(10, 0, 11, 0), timed(14, 0, 15, 0)},
+			want:  []Placement{p(0, 2), p(1, 2), p(1, 2)},
+		},
+		{
+			name:  "nested three deep",
+			items: []Occurrence{timed(9, 0, 17, 0), timed(10, 0, 16, 0), timed(11, 0, 12, 0)},
+			want:  []Placement{p(0, 3), p(1, 3), p(2, 3)},
+		},
+		{
+			// a and c never overlap, but one group through b: c reuses a's
+			// column and all three get the group's width
+			name:  "chained",
+			items: []Occurrence{timed(9, 0, 11, 0), timed(10, 0, 13, 0), timed(12, 0, 14, 0)},
+			want:  []Placement{p(0, 2), p(1, 2), p(0, 2)},
+		},
+		{
+			name: "chain then a separate group",
+			items: []Occurrence{
+				timed(9, 0, 11, 0), timed(10, 0, 13, 0), timed(12, 0, 14, 0), timed(11, 30, 12, 30),
+				timed(15, 0, 16, 0),
+			},
+			want: []Placement{p(0, 3), p(1, 3), p(2, 3), p(0, 3), p(0, 1)},
+		},
+		{
+			name:  "equal starts put the longest first",
+			items: []Occurrence{timed(9, 0, 10, 0), timed(9, 0, 12, 0)},
+			want:  []Placement{p(1, 2), p(0, 2)},
+		},
+		{
+			name:  "unsorted input",
+			items: []Occurrence{timed(12, 0, 14, 0), timed(9, 0, 11, 0), timed(10, 0, 13, 0)},
+			want:  []Placement{p(0, 2), p(0, 2), p(1, 2)},
+		},
+		{
+			name:  "zero-length counts as a minute",
+			items: []Occurrence{timed(9, 0, 10, 0), timed(9, 30, 9, 30), timed(9, 31, 9, 45)},
+			want:  []Placement{p(0, 2), p(1, 2), p(1, 2)},
+		},
+		{
+			name: "all-day and outside the window are not placed",
+			items: []Occurrence{
+				{Start: from, End: to, AllDay: true},
+				timed(9, 0, 10, 0),
+				{Start: utc(2026, 3, 11, 9, 0), End: utc(2026, 3, 11, 10, 0)},
+			},
+			want: []Placement{{}, p(0, 1), {}},
+		},
+		{
+			// only the part after midnight overlaps the morning one
+			name: "clipped to the window",
+			items: []Occurrence{
+				{Start: utc(2026, 3, 9, 22, 0), End: utc(2026, 3, 10, 1, 0)},
+				timed(0, 30, 2, 0),
+				{Start: utc(2026, 3, 9, 20, 0), End: utc(2026, 3, 9, 21, 0)},
+			},
+			want: []Placement{p(0, 2), p(1, 2), {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Layout(tt.items, from, to); !slices.Equal(got, tt.want) {
+				t.Fatalf("Layout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// No two overlapping occurrences share a column, and a group uses no more
+// columns than the most occurrences overlapping at once.
+func TestLayoutColumnsDontOverlap(t *testing.T) {
+	from, to := utc(2026, 3, 10, 0, 0), utc(2026, 3, 11, 0, 0)
+	var items []Occurrence
+	for i := range 40 {
+		start := from.Add(time.Duration(i*37%600) * time.Minute)
+		items = append(items, Occurrence{Start: start, End: start.Add(time.Duration(15+i*13%120) * time.Minute)})
+	}
+
+	got := Layout(items, from, to)
+	most := 0
+	for i, a := range items {
+		at := 0
+		for j, b := range items {
+			if !a.Start.Before(b.Start) && a.Start.Before(b.End) {
+				at++
+			}
+			if i != j && a.Start.Before(b.End) && b.Start.Before(a.End) && got[i].Column == got[j].Column {
+				t.Fatalf("items %d and %d overlap in column %d", i, j, got[i].Column)
+			}
+		}
+		most = max(most, at)
+		if got[i].Column >= got[i].ColumnsInGroup {
+			t.Fatalf("item %d in column %d of %d", i, got[i].Column, got[i].ColumnsInGroup)
+		}
+	}
+	for i := range got {
+		if got[i].ColumnsInGroup > most {
+			t.Fatalf("item %d in a group of %d columns, at most %d overlap", i, got[i].ColumnsInGroup, most)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+
+	"schedule/recur"
+)
+
+// MaxKeepNext caps the future occurrences LimitFuture can keep.
+const MaxKeepNext = 1000
+
+// FutureLimit is the outcome of LimitFuture.
+type FutureLimit struct {
+	RRule string `json:"rrule"`
+	// Last is the start of the last occurrence kept, zero when keepNext is 0.
+	Last time.Time `json:"last"`
+	// PastCount is the number of (not exdated) occurrences before now, which
+	// the new rule keeps.
+	PastCount int `json:"pastCount"`
+}
+
+// LimitFuture rewrites the rrule of the series ev so exactly keep
+// occurrences start at or after now. Exdated and paused instances don't count
+// towards keep, past ones are kept as they are. A rule with COUNT (and any
+// floating series, whose instants depend on the viewer) gets a new COUNT,
+// counting the skipped instances as RFC 5545 does for exdates; other rules get
+// an UNTIL at the last kept occurrence. The limit can also extend a rule that
+// ends sooner, but not revive a series that already ended. loc is the
+// fallback zone of events without a timezone.
+func LimitFuture(ev *Event, now time.Time, keep int, loc *time.Location) (*FutureLimit, error) {
+	if !ev.IsRecurring() {
+		return nil, errors.New("the event is not recurring")
+	}
+	if keep < 0 || keep > MaxKeepNext {
+		return nil, errors.New("keepNext is out of range")
+	}
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil {
+		return nil, err
+	}
+	zone := ev.Zone(loc)
+	dtstart := ev.dtstart(zone)
+	if !hasInstanceFrom(rule, dtstart, now) {
+		return nil, errors.New("the series has already ended")
+	}
+
+	// walk the rule without its bounds, it may have to be extended
+	open := *rule
+	open.Count, open.Until = 0, time.Time{}
+
+	res := &FutureLimit{}
+	instances, kept := 0, 0 // instances counts exdated ones too, as COUNT does
+	excluded := newExdateSet(ev, zone)
+	it := open.Iter(dtstart)
+	for {
+		t, ok := it.Next()
+		if !ok || (!t.Before(now) && kept == keep) {
+			break
+		}
+		instances++
+		if ev.skips(excluded, t) {
+			continue
+		}
+		if t.Before(now) {
+			res.PastCount++
+			continue
+		}
+		kept++
+		res.Last = t.UTC()
+	}
+
+	switch {
+	case kept < keep:
+		return nil, errors.New("the rule has fewer future occurrences than keepNext")
+	case keep == 0 && res.PastCount == 0:
+		return nil, errors.New("the series has no past occurrences to keep")
+	case rule.Count > 0 || ev.Floating:
+		c := open
+		c.Count = instances
+		res.RRule = c.String()
+	case keep == 0:
+		res.RRule = open.EndBefore(now).String()
+	default:
+		res.RRule = open.EndBefore(res.Last.Add(time.Second)).String()
+	}
+	return res, nil
+}
+
+// hasInstanceFrom reports whether rule anchored at dtstart has an instance
+// starting at or after t, exdated or not.
+func hasInstanceFrom(rule *recur.Rule, dtstart, t time.Time) bool {
+	it := rule.Iter(dtstart)
+	it.Seek(t)
+	for {
+		next, ok := it.Next()
+		if !ok {
+			return false
+		}
+		if !next.Before(t) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package calendar
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// LocationCount is a distinct event location with the number of events using
+// it.
+type LocationCount struct {
+	Location string `json:"location"`
+	Count    int    `json:"count"`
+}
+
+// Locations lists the distinct non-empty locations of the events of ownerID
+// (every owner's when empty) starting with prefix, most used first. Locations
+// differing only in case or surrounding spaces count as one, spelled as most
+// of the events spell it.
+func Locations(app core.App, ownerID, prefix string) ([]LocationCount, error) {
+	var rows []struct {
+		Location string `db:"location"`
+		Count    int    `db:"n"`
+	}
+	query := app.DB().
+		Select("location", "COUNT(*) AS n").
+		From(EventsCollection).
+		Where(dbx.NewExp("TRIM([[location]]) != ''")).
+		GroupBy("location")
+	if ownerID != "" {
+		query.AndWhere(dbx.HashExp{"owner": ownerID})
+	}
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	type group struct {
+		total     int
+		spellings map[string]int
+	}
+	groups := map[string]*group{}
+	for _, r := range rows {
+		spelling := strings.TrimSpace(r.Location)
+		key := strings.ToLower(spelling)
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{spellings: map[string]int{}}
+			groups[key] = g
+		}
+		g.total += r.Count
+		g.spellings[spelling] += r.Count
+	}
+
+	out := make([]LocationCount, 0, len(groups))
+	for _, g := range groups {
+		best, n := "", 0
+		for spelling, c := range g.spellings {
+			if c > n || (c == n && spelling < best) {
+				best, n = spelling, c
+			}
+		}
+		out = append(out, LocationCount{Location: best, Count: g.total})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return strings.ToLower(out[i].Location) < strings.ToLower(out[j].Location)
+	})
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// MaterializedCollection stores expanded occurrences for consumers that can't
+// expand RRULEs themselves.
+const MaterializedCollection = "materialized_occurrences"
+
+// materializeBatch is how many events are rebuilt per transaction, so a rebuild
+// never locks the database for the whole window.
+const materializeBatch = 50
+
+// MaterializeResult summarizes a rebuild.
+type MaterializeResult struct {
+	Events   int           `json:"events"`
+	Deleted  int64         `json:"deleted"`
+	Created  int           `json:"created"`
+	Duration time.Duration `json:"-"`
+}
+
+// Rematerialize replaces the materialized occurrences starting in [from, to)
+// with a fresh expansion. Rows of other windows are left untouched.
+func Rematerialize(app core.App, from, to time.Time) (*MaterializeResult, error) {
+	began := time.Now()
+	result := &MaterializeResult{}
+
+	collection, err := app.FindCollectionByNameOrId(MaterializedCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := FindEvents(app, nil, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result.Events = len(events)
+
+	window := dbx.NewExp("start >= {:from} AND start < {:to}", dbx.Params{
+		"from": dateParam(from),
+		"to":   dateParam(to),
+	})
+
+	ids := make([]any, len(events))
+	for i, ev := range events {
+		ids[i] = ev.ID
+	}
+
+	for lo := 0; lo < len(events); lo += materializeBatch {
+		hi := min(lo+materializeBatch, len(events))
+
+		err := app.RunInTransaction(func(txApp core.App) error {
+			res, err := txApp.DB().Delete(MaterializedCollection, dbx.And(window, dbx.In("event", ids[lo:hi]...))).Execute()
+			if err != nil {
+				return err
+			}
+			n, _ := res.RowsAffected()
+			result.Deleted += n
+
+			for _, ev := range events[lo:hi] {
+				for _, o := range ev.Occurrences(from, to, time.UTC) {
+					// rows are keyed by start, so ones overlapping into the window
+					// belong to the previous window
+					if o.Start.Before(from) {
+						continue
+					}
+
+					rec := core.NewRecord(collection)
+					rec.Set("event", ev.ID)
+					rec.Set("start", o.Start)
+					rec.Set("end", o.End)
+					rec.Set("allDay", o.AllDay)
+					if err := txApp.Save(rec); err != nil {
+						return err
+					}
+					result.Created++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// events that no longer occur in the window (e.g. moved away)
+	stale := dbx.Expression(window)
+	if len(ids) > 0 {
+		stale = dbx.And(window, dbx.NotIn("event", ids...))
+	}
+	res, err := app.DB().Delete(MaterializedCollection, stale).Execute()
+	if err != nil {
+		return nil, err
+	}
+	n, _ := res.RowsAffected()
+	result.Deleted += n
+
+	result.Duration = time.Since(began)
+	return result, nil
+}
+
+// HasMaterialized reports whether any occurrences are materialized.
+func HasMaterialized(app core.App) (bool, error) {
+	var id string
+	err := app.DB().Select("id").From(MaterializedCollection).Limit(1).Row(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MaterializeSpan is the window ev's materialized occurrences start in. to is
+// zero for series, whose rows run up to wherever they were materialized.
+// Floating events are widened by MaxZoneOffset, they are materialized in UTC
+// but stored as wall clock times.
+func (ev *Event) MaterializeSpan() (from, to time.Time) {
+	from = ev.Start
+	if ev.Floating {
+		from = from.Add(-MaxZoneOffset)
+	}
+	if ev.IsRecurring() {
+		return from, time.Time{}
+	}
+	to = ev.End
+	if !to.After(ev.Start) {
+		to = ev.Start
+	}
+	to = to.Add(time.Second)
+	if ev.Floating {
+		to = to.Add(MaxZoneOffset)
+	}
+	return from, to
+}
@@ -0,0 +1,164 @@
+package calendar
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// normalizeBatch is how many events are normalized per transaction.
+const normalizeBatch = 100
+
+// NormalizedFields are the events fields Normalize cleans up.
+var NormalizedFields = []string{"title", "color", "tags", "exdates"}
+
+var (
+	shortHexColor = regexp.MustCompile(`^#[0-9a-f]{3}$`)
+	longHexColor  = regexp.MustCompile(`^#?[0-9a-f]{6}$`)
+)
+
+// NormalizeResult counts the events a bulk normalization went through.
+type NormalizeResult struct {
+	Scanned int `json:"scanned"`
+	Changed int `json:"changed"`
+	// Failed lists the events whose save was rejected, e.g. by a validation
+	// the stored record no longer passes.
+	Failed []string `json:"failed"`
+}
+
+// Normalize rewrites the given NormalizedFields of rec in their canonical
+// form and reports whether any of them changed:
+//   - title: trimmed, inner whitespace runs collapsed to one space;
+//   - color: trimmed and lowercased, hex colors as "#rrggbb";
+//   - tags: trimmed, blank ones dropped, case-insensitive duplicates dropped
+//     (the first spelling wins);
+//   - exdates: as ISO strings, sorted and without duplicates. Values that
+//     don't parse are kept as they are, for the audit to report.
+func Normalize(rec *core.Record, fields []string) bool {
+	changed := false
+	for _, name := range fields {
+		switch name {
+		case "title":
+			if v := strings.Join(strings.Fields(rec.GetString("title")), " "); v != rec.GetString("title") {
+				rec.Set("title", v)
+				changed = true
+			}
+		case "color":
+			if v := normalizeColor(rec.GetString("color")); v != rec.GetString("color") {
+				rec.Set("color", v)
+				changed = true
+			}
+		case "tags":
+			var tags []string
+			if err := rec.UnmarshalJSONField("tags", &tags); err != nil || len(tags) == 0 {
+				continue
+			}
+			if v := normalizeTags(tags); !slices.Equal(v, tags) {
+				rec.Set("tags", v)
+				changed = true
+			}
+		case "exdates":
+			var exdates []string
+			if err := rec.UnmarshalJSONField("exdates", &exdates); err != nil || len(exdates) == 0 {
+				continue
+			}
+			if v := normalizeExdates(exdates); !slices.Equal(v, exdates) {
+				rec.Set("exdates", v)
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// NormalizeEvents normalizes every stored event, saving the changed ones in
+// batched transactions through the usual save hooks, so the history records
+// the cleanup. Events of external calendars are normalized too, the next
+// sync does the same to what it mirrors.
+func NormalizeEvents(app core.App) (*NormalizeResult, error) {
+	result := &NormalizeResult{Failed: []string{}}
+	last := ""
+	for {
+		records, err := app.FindRecordsByFilter(EventsCollection, "id > {:last}", "id", normalizeBatch, 0, dbx.Params{"last": last})
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return result, nil
+		}
+		last = records[len(records)-1].Id
+		result.Scanned += len(records)
+
+		err = app.RunInTransaction(func(txApp core.App) error {
+			for _, rec := range records {
+				if !Normalize(rec, NormalizedFields) {
+					continue
+				}
+				rec.Set(MirrorKey, true)
+				if err := txApp.Save(rec); err != nil {
+					app.Logger().Warn("event not normalized", "event", rec.Id, "error", err)
+					result.Failed = append(result.Failed, rec.Id)
+					continue
+				}
+				result.Changed++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// normalizeColor lowercases a color and spells hex colors in full with their
+// "#": "#ABC" and "aabbcc" become "#aabbcc". Tokens such as "blue-500" are
+// only lowercased.
+func normalizeColor(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case shortHexColor.MatchString(s):
+		return "#" + string([]byte{s[1], s[1], s[2], s[2], s[3], s[3]})
+	case longHexColor.MatchString(s):
+		return "#" + strings.TrimPrefix(s, "#")
+	}
+	return s
+}
+
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(t), " ")
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func normalizeExdates(exdates []string) []string {
+	var times []time.Time
+	var invalid []string
+	for _, s := range exdates {
+		if t, err := ParseTime(s, time.UTC); err == nil {
+			times = append(times, t)
+		} else {
+			invalid = append(invalid, s)
+		}
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	out := make([]string, 0, len(exdates))
+	for _, t := range times {
+		if v := ISO(t); len(out) == 0 || out[len(out)-1] != v {
+			out = append(out, v)
+		}
+	}
+	return append(out, invalid...)
+}
@@ -0,0 +1,103 @@
+package calendar
+
+import (
+	"strings"
+
+	"schedule/ics"
+
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Organizer is the ORGANIZER of an event: Email organizes it and SentBy, when
+// set, acts on their behalf (RFC 5545 SENT-BY).
+type Organizer struct {
+	Email  string
+	SentBy string
+}
+
+// OrganizerOf returns the organizer of ev, whose owner has ownerEmail: the
+// owner, or the organizer field sent by the owner. The zero Organizer means
+// unknown.
+func OrganizerOf(ev *Event, ownerEmail string) Organizer {
+	if ev.Organizer == "" {
+		return Organizer{Email: ownerEmail}
+	}
+	o := Organizer{Email: ev.Organizer}
+	if ownerEmail != "" && !strings.EqualFold(ownerEmail, ev.Organizer) {
+		o.SentBy = ownerEmail
+	}
+	return o
+}
+
+// addTo writes the ORGANIZER property, if the organizer is known.
+func (o Organizer) addTo(vev *ics.Component) {
+	if o.Email == "" {
+		return
+	}
+	var params []string
+	if o.SentBy != "" {
+		params = []string{"SENT-BY", "mailto:" + o.SentBy}
+	}
+	vev.Add("ORGANIZER", "mailto:"+o.Email, params...)
+}
+
+// FindOrganizers resolves the organizers of the events that have one for
+// export, keyed by event id: events with attendees (detached occurrences
+// count their series') and events with an organizer set.
+func FindOrganizers(app core.App, events []*Event) (map[string]Organizer, error) {
+	ids := make([]any, 0, len(events))
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+		if ev.IsDetached() {
+			ids = append(ids, ev.SourceID)
+		}
+	}
+	invited := map[string]bool{}
+	if len(ids) > 0 {
+		records, err := app.FindAllRecords(AttendeesCollection, dbx.In("event", ids...))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range records {
+			invited[r.GetString("event")] = true
+		}
+	}
+
+	emails := map[string]string{} // owner id -> email
+	out := map[string]Organizer{}
+	for _, ev := range events {
+		if ev.Organizer == "" && !invited[ev.ID] && !(ev.IsDetached() && invited[ev.SourceID]) {
+			continue
+		}
+		email, ok := emails[ev.Owner]
+		if !ok && ev.Owner != "" {
+			if owner, err := app.FindRecordById(UsersCollection, ev.Owner); err == nil {
+				email = owner.Email()
+			}
+			emails[ev.Owner] = email
+		}
+		if o := OrganizerOf(ev, email); o.Email != "" {
+			out[ev.ID] = o
+		}
+	}
+	return out, nil
+}
+
+// organizerEmail extracts the address of an ORGANIZER value, empty when it is
+// not an email address (e.g. a urn: URI).
+func organizerEmail(vev *ics.Component) string {
+	p := vev.Prop("ORGANIZER")
+	if p == nil {
+		return ""
+	}
+	v := strings.TrimSpace(p.Value)
+	if len(v) >= len("mailto:") && strings.EqualFold(v[:len("mailto:")], "mailto:") {
+		v = v[len("mailto:"):]
+	}
+	if is.EmailFormat.Validate(v) != nil {
+		return ""
+	}
+	return v
+}
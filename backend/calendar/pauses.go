@@ -0,0 +1,87 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MaxPauses caps the pause windows of one series.
+const MaxPauses = 100
+
+// Pause is a window [From, To) in which a series doesn't occur: instances
+// starting in it are skipped like exdated ones, without touching the rrule.
+// The times of floating series are wall clock times, as their start.
+type Pause struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// StoredPauses maps pauses to the pauses field value, ISO strings like the
+// exdates.
+func StoredPauses(pauses []Pause) []map[string]string {
+	out := make([]map[string]string, len(pauses))
+	for i, p := range pauses {
+		out[i] = map[string]string{"from": ISO(p.From), "to": ISO(p.To)}
+	}
+	return out
+}
+
+// ValidatePauses checks that every pause ends after it starts and that they
+// don't overlap. It sorts pauses by start.
+func ValidatePauses(pauses []Pause) error {
+	if len(pauses) > MaxPauses {
+		return fmt.Errorf("at most %d pauses are allowed", MaxPauses)
+	}
+	slices.SortFunc(pauses, func(a, b Pause) int { return a.From.Compare(b.From) })
+	for i, p := range pauses {
+		if !p.To.After(p.From) {
+			return errors.New("a pause must end after it starts")
+		}
+		if i > 0 && p.From.Before(pauses[i-1].To) {
+			return errors.New("pauses must not overlap")
+		}
+	}
+	return nil
+}
+
+// isPaused reports whether the instance starting at t falls in one of the
+// event's pauses.
+func (ev *Event) isPaused(t time.Time) bool {
+	for _, p := range ev.Pauses {
+		from, to := p.From, p.To
+		if ev.Floating {
+			from, to = WallClock(from.UTC(), t.Location()), WallClock(to.UTC(), t.Location())
+		}
+		if !t.Before(from) && t.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// skips reports whether the instance starting at t is left out of the
+// expansion, exdated (excluded is the exdateSet of ev in t's zone) or paused.
+func (ev *Event) skips(excluded exdateSet, t time.Time) bool {
+	return excluded.has(t) || ev.isPaused(t)
+}
+
+// PausedStarts lists the starts of the instances the pauses of ev skip, for
+// formats without pauses (the ICS export lists them as EXDATEs). Times are
+// UTC, wall clock ones for floating events like the exdates.
+func PausedStarts(ev *Event) []time.Time {
+	bare := *ev
+	bare.Pauses = nil
+
+	var out []time.Time
+	for _, p := range ev.Pauses {
+		from, to := p.From, p.To
+		for _, o := range bare.Occurrences(from, to, time.UTC) {
+			if !o.Start.Before(from) && !slices.ContainsFunc(ev.Exdates, o.Start.Equal) {
+				out = append(out, o.Start.UTC())
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package calendar
+
+import (
+	"time"
+
+	"schedule/recur"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// purgeBatch is how many events are deleted per transaction.
+const purgeBatch = 50
+
+// PurgeResult counts the events deleted by a purge.
+type PurgeResult struct {
+	Single   int `json:"single"`
+	Series   int `json:"series"`
+	Detached int `json:"detached"`
+}
+
+// Purge deletes the events that ended before cutoff: single events, and
+// series whose COUNT or UNTIL makes every instance end before it. Open-ended
+// and still running series are never touched, and neither are the detached
+// occurrences of a series that is kept.
+func Purge(app core.App, cutoff time.Time) (*PurgeResult, error) {
+	// a series can only be over if its first instance is, so this bounds the scan
+	records, err := app.FindRecordsByFilter(EventsCollection, "end < {:before}", "start", 0, 0, dbx.Params{
+		"before": dateParam(cutoff),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PurgeResult{}
+	purged := map[string]bool{}
+	var doomed []*core.Record
+	var detached []*core.Record
+
+	for _, r := range records {
+		ev := EventFromRecord(r)
+		switch {
+		case ev.IsDetached():
+			detached = append(detached, r)
+		case ev.IsRecurring():
+			if endsBefore(ev, cutoff) {
+				purged[ev.ID] = true
+				doomed = append(doomed, r)
+				result.Series++
+			}
+		default:
+			doomed = append(doomed, r)
+			result.Single++
+		}
+	}
+
+	for _, r := range detached {
+		sourceID := r.GetString("sourceId")
+		if !purged[sourceID] {
+			// keep overrides of live series; an orphan goes like a single event
+			if _, err := app.FindRecordById(EventsCollection, sourceID); err == nil {
+				continue
+			}
+		}
+		doomed = append(doomed, r)
+		result.Detached++
+	}
+
+	for lo := 0; lo < len(doomed); lo += purgeBatch {
+		batch := doomed[lo:min(lo+purgeBatch, len(doomed))]
+		err := app.RunInTransaction(func(txApp core.App) error {
+			for _, r := range batch {
+				if err := txApp.Delete(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// endsBefore reports whether every instance of the bounded series ev ends
+// before cutoff. Unbounded or unparsable rules never do.
+func endsBefore(ev *Event, cutoff time.Time) bool {
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil || (rule.Count == 0 && rule.Until.IsZero()) {
+		return false
+	}
+
+	it := rule.Iter(ev.dtstart(ev.Zone(time.UTC)))
+	for {
+		t, ok := it.Next()
+		if !ok {
+			return true
+		}
+		if !ev.endAt(t).Before(cutoff) {
+			return false
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ParseTime parses the date formats accepted by the schedule API: RFC 3339,
+// the PocketBase storage layout and date-only values (midnight in loc).
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(types.DefaultDateLayout, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// StartOfDay returns midnight of the day containing t in loc.
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// DayRange returns [midnight, next midnight) of the day containing t in loc.
+// The end is computed from the calendar date, so days are 23 or 25 hours long
+// across DST transitions.
+func DayRange(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := StartOfDay(t, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// WeekRange returns the week containing t in loc, starting on weekStart.
+func WeekRange(t time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time) {
+	day := StartOfDay(t, loc)
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// ISOWeek is an ISO-8601 week number. ISO weeks always start on Monday,
+// whatever week start the calendar is displayed with.
+type ISOWeek struct {
+	Year int `json:"year"`
+	Week int `json:"week"`
+}
+
+// ISOWeekOf returns the ISO week of the day containing t in loc.
+func ISOWeekOf(t time.Time, loc *time.Location) ISOWeek {
+	y, w := StartOfDay(t, loc).ISOWeek()
+	return ISOWeek{Year: y, Week: w}
+}
+
+// ISOWeekRange returns the Monday-start ISO week containing t in loc.
+func ISOWeekRange(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	return WeekRange(t, loc, time.Monday)
+}
+
+// RowISOWeek returns the ISO week a display week row starting at rowStart is
+// labelled with: the week of the Monday inside the row. A Sunday-start row
+// thus carries the number of the ISO week beginning the next day, which covers
+// six of its seven days.
+func RowISOWeek(rowStart time.Time, loc *time.Location) ISOWeek {
+	day := StartOfDay(rowStart, loc)
+	offset := (int(time.Monday) - int(day.Weekday()) + 7) % 7
+	return ISOWeekOf(day.AddDate(0, 0, offset), loc)
+}
+
+// MonthRange returns the calendar month containing t in loc.
+func MonthRange(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	y, m, _ := t.In(loc).Date()
+	start := time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// MonthGridRange returns the days shown by a month grid: whole weeks starting
+// on weekStart, from the week containing the 1st to the week containing the
+// last day of the month (so leading/trailing days of adjacent months are included).
+func MonthGridRange(year int, month time.Month, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	from, _ := WeekRange(first, loc, weekStart)
+	_, to := WeekRange(first.AddDate(0, 1, -1), loc, weekStart)
+	return from, to
+}
+
+// RelativeRanges lists the keywords accepted by RelativeRange.
+var RelativeRanges = []string{"today", "tomorrow", "this-week", "next-week", "this-month"}
+
+// RelativeRange resolves a relative range keyword against now in loc. Weeks
+// start on weekStart, so "this-week" on a Sunday differs between Sunday- and
+// Monday-start calendars.
+func RelativeRange(keyword string, now time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time, error) {
+	switch keyword {
+	case "today":
+		from, to := DayRange(now, loc)
+		return from, to, nil
+	case "tomorrow":
+		from, to := DayRange(StartOfDay(now, loc).AddDate(0, 0, 1), loc)
+		return from, to, nil
+	case "this-week":
+		from, to := WeekRange(now, loc, weekStart)
+		return from, to, nil
+	case "next-week":
+		_, to := WeekRange(now, loc, weekStart)
+		return to, to.AddDate(0, 0, 7), nil
+	case "this-month":
+		from, to := MonthRange(now, loc)
+		return from, to, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown range %q", keyword)
+}
@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekRange(t *testing.T) {
+	pacific := mustZone(t, "Pacific/Auckland")
+	tests := []struct {
+		name      string
+		t         time.Time
+		loc       *time.Location
+		weekStart time.Weekday
+		want      string
+	}{
+		{"Monday start", utc(2026, 3, 15, 12, 0), time.UTC, time.Monday, "2026-03-09"},
+		{"Sunday start on a Sunday", utc(2026, 3, 15, 12, 0), time.UTC, time.Sunday, "2026-03-15"},
+		{"Saturday start", utc(2026, 3, 15, 12, 0), time.UTC, time.Saturday, "2026-03-14"},
+		// 15 March 12:00 UTC is already Monday the 16th in Auckland
+		{"day of the viewer's zone", utc(2026, 3, 15, 12, 0), pacific, time.Monday, "2026-03-16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := WeekRange(tt.t, tt.loc, tt.weekStart)
+			if got := from.Format(time.DateOnly); got != tt.want || from.Location() != tt.loc {
+				t.Fatalf("week starts %v, want %s in %v", from, tt.want, tt.loc)
+			}
+			if to.Sub(from) != 7*24*time.Hour || to.Weekday() != tt.weekStart {
+				t.Fatalf("week ends %v", to)
+			}
+		})
+	}
+}
+
+func TestISOWeeks(t *testing.T) {
+	tests := []struct {
+		day  time.Time
+		want ISOWeek
+	}{
+		{utc(2026, 3, 15, 0, 0), ISOWeek{2026, 11}}, // Sunday ends ISO week 11
+		{utc(2026, 3, 16, 0, 0), ISOWeek{2026, 12}},
+		{utc(2026, 1, 1, 0, 0), ISOWeek{2026, 1}},   // a Thursday
+		{utc(2027, 1, 1, 0, 0), ISOWeek{2026, 53}},  // a Friday, in the last week of 2026
+		{utc(2024, 12, 30, 0, 0), ISOWeek{2025, 1}}, // a Monday, in the first week of 2025
+	}
+	for _, tt := range tests {
+		if got := ISOWeekOf(tt.day, time.UTC); got != tt.want {
+			t.Errorf("ISOWeekOf(%s) = %v, want %v", tt.day.Format(time.DateOnly), got, tt.want)
+		}
+	}
+
+	// the ISO week always starts on Monday
+	from, to := ISOWeekRange(utc(2026, 3, 15, 0, 0), time.UTC)
+	if from.Format(time.DateOnly) != "2026-03-09" || to.Format(time.DateOnly) != "2026-03-16" {
+		t.Errorf("ISOWeekRange = %v – %v", from, to)
+	}
+}
+
+// A Sunday-start grid row begins a day before the ISO week it is numbered
+// with.
+func TestRowISOWeekSundayStart(t *testing.T) {
+	row, _ := WeekRange(utc(2026, 3, 18, 0, 0), time.UTC, time.Sunday)
+	if row.Format(time.DateOnly) != "2026-03-15" {
+		t.Fatalf("row starts %v", row)
+	}
+	if got, first := RowISOWeek(row, time.UTC), ISOWeekOf(row, time.UTC); got != (ISOWeek{2026, 12}) || first != (ISOWeek{2026, 11}) {
+		t.Fatalf("row labelled %v (first column in %v), want 2026-W12", got, first)
+	}
+	// a Monday-start row is its own ISO week
+	row, _ = WeekRange(utc(2026, 3, 18, 0, 0), time.UTC, time.Monday)
+	if got := RowISOWeek(row, time.UTC); got != (ISOWeek{2026, 12}) {
+		t.Fatalf("Monday row labelled %v", got)
+	}
+	// the row holding New Year's Day 2027 is the last ISO week of 2026
+	row, _ = WeekRange(utc(2027, 1, 1, 0, 0), time.UTC, time.Sunday)
+	if got := RowISOWeek(row, time.UTC); got != (ISOWeek{2026, 53}) {
+		t.Fatalf("New Year row labelled %v", got)
+	}
+}
+
+// On a Sunday, this week of a Monday-start calendar is the one ending today.
+func TestRelativeRangeWeekStart(t *testing.T) {
+	sunday := utc(2026, 3, 15, 12, 0)
+	tests := []struct {
+		keyword   string
+		weekStart time.Weekday
+		from, to  string
+	}{
+		{"this-week", time.Sunday, "2026-03-15", "2026-03-22"},
+		{"this-week", time.Monday, "2026-03-09", "2026-03-16"},
+		{"next-week", time.Sunday, "2026-03-22", "2026-03-29"},
+		{"next-week", time.Monday, "2026-03-16", "2026-03-23"},
+	}
+	for _, tt := range tests {
+		from, to, err := RelativeRange(tt.keyword, sunday, time.UTC, tt.weekStart)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if from.Format(time.DateOnly) != tt.from || to.Format(time.DateOnly) != tt.to {
+			t.Errorf("%s starting %v = %v – %v, want %s – %s", tt.keyword, tt.weekStart, from, to, tt.from, tt.to)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"schedule/recur"
+)
+
+// Reanchored is a series moved to a new start by Reanchor.
+type Reanchored struct {
+	Start   time.Time
+	End     time.Time
+	Exdates []time.Time
+	// RecurrenceIDs maps the detached occurrences of the series to the
+	// recurrence id of the instance they now replace.
+	RecurrenceIDs map[string]time.Time
+}
+
+// Reanchor moves the series ev, with its detached occurrences, so it starts
+// at start, keeping its duration and rule. Every instance moves by the same
+// number of days and the same wall clock offset in the event zone (loc as
+// fallback), and so do the exdates and the recurrence ids of the detached
+// occurrences, which keep excluding and replacing the same instances. With
+// clearExdates the exdates of deleted instances are dropped; those of
+// detached occurrences stay, or their instances would show up twice.
+//
+// The series must still have an instance from its new start: a start past
+// the rule's UNTIL is rejected.
+func Reanchor(ev *Event, detached []*Event, start time.Time, clearExdates bool, loc *time.Location) (*Reanchored, error) {
+	if !ev.IsRecurring() {
+		return nil, errors.New("the event is not recurring")
+	}
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := ev.Zone(loc)
+	if ev.Floating {
+		zone = time.UTC // floating times are stored as UTC wall clock times
+	}
+	if ev.AllDay {
+		start = StartOfDay(start, zone)
+	}
+
+	oldDay, newDay := StartOfDay(ev.Start, zone), StartOfDay(start, zone)
+	days := int(newDay.Sub(oldDay).Round(24*time.Hour) / (24 * time.Hour))
+	clock := start.In(zone).Sub(newDay) - ev.Start.In(zone).Sub(oldDay)
+	shift := func(t time.Time) time.Time {
+		y, m, d := t.In(zone).Date()
+		hh, mm, ss := t.In(zone).Clock()
+		return time.Date(y, m, d+days, hh, mm, ss, t.Nanosecond(), zone).Add(clock).UTC()
+	}
+
+	out := &Reanchored{
+		Start:         start.UTC(),
+		End:           start.Add(ev.Duration()).UTC(),
+		Exdates:       []time.Time{},
+		RecurrenceIDs: map[string]time.Time{},
+	}
+	if ev.AllDay {
+		out.End = shift(ev.End)
+	}
+
+	moved := *ev
+	moved.Start, moved.End, moved.Exdates, moved.Pauses = out.Start, out.End, nil, nil
+	if !rule.Until.IsZero() && moved.Start.After(rule.Until) {
+		return nil, errors.New("the new start is after the end (UNTIL) of the series")
+	}
+	if _, ok := rule.Iter(moved.dtstart(ev.Zone(loc))).Next(); !ok {
+		return nil, errors.New("the series has no occurrence from the new start")
+	}
+
+	replaced := []time.Time{}
+	for _, d := range detached {
+		if d.RecurrenceID.IsZero() {
+			continue
+		}
+		out.RecurrenceIDs[d.ID] = shift(d.RecurrenceID)
+		replaced = append(replaced, d.RecurrenceID)
+	}
+	for _, x := range ev.Exdates {
+		if clearExdates && !slices.ContainsFunc(replaced, x.Equal) {
+			continue
+		}
+		out.Exdates = append(out.Exdates, shift(x))
+	}
+	return out, nil
+}
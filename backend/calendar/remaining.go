@@ -0,0 +1,54 @@
+package calendar
+
+import (
+	"time"
+
+	"schedule/recur"
+)
+
+// MaxRemaining caps how many occurrences Remaining counts.
+const MaxRemaining = 10000
+
+// Remaining counts the occurrences of the series ev starting at or after now:
+// its instances that no exdate or pause skips, plus the detached occurrences
+// starting from now that replace a skipped instance (the others replace an
+// instance already counted). ok is false for rules without COUNT or UNTIL, and
+// for events that don't recur or whose rule doesn't parse. At most
+// MaxRemaining occurrences are counted. loc is the fallback zone of events
+// without a timezone.
+func Remaining(ev *Event, detached []*Event, now time.Time, loc *time.Location) (n int, ok bool) {
+	if !ev.IsRecurring() {
+		return 0, false
+	}
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil || rule.IsInfinite() {
+		return 0, false
+	}
+
+	zone := ev.Zone(loc)
+	instant := func(t time.Time) time.Time {
+		if ev.Floating {
+			return WallClock(t.UTC(), zone) // stored as UTC wall clock times
+		}
+		return t.In(zone)
+	}
+	excluded := newExdateSet(ev, zone)
+	for _, d := range detached {
+		if !d.RecurrenceID.IsZero() && !instant(d.Start).Before(now) && ev.skips(excluded, instant(d.RecurrenceID)) {
+			n++
+		}
+	}
+
+	it := rule.Iter(ev.dtstart(zone))
+	it.Seek(now)
+	for n < MaxRemaining {
+		t, more := it.Next()
+		if !more {
+			break
+		}
+		if !t.Before(now) && !ev.skips(excluded, t) {
+			n++
+		}
+	}
+	return min(n, MaxRemaining), true
+}
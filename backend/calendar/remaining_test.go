@@ -0,0 +1,44 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemainingSkipsExdates(t *testing.T) {
+	berlin := mustZone(t, "Europe/Berlin")
+	now := utc(2026, 3, 9, 0, 0)
+	tests := []struct {
+		name string
+		ev   Event
+		want int
+	}{
+		{
+			name: "timed",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), RRule: "FREQ=DAILY;COUNT=5", Exdates: []time.Time{utc(2026, 3, 11, 9, 0), utc(2026, 3, 13, 9, 0)}},
+			want: 3,
+		},
+		{
+			// an exdate at UTC midnight still excludes its day in Berlin
+			name: "all-day in another offset",
+			ev: Event{
+				Start: time.Date(2026, 3, 10, 0, 0, 0, 0, berlin), End: time.Date(2026, 3, 11, 0, 0, 0, 0, berlin), AllDay: true, Timezone: "Europe/Berlin",
+				RRule: "FREQ=DAILY;COUNT=5", Exdates: []time.Time{utc(2026, 3, 12, 0, 0)},
+			},
+			want: 4,
+		},
+		{
+			name: "floating",
+			ev:   Event{Start: utc(2026, 3, 10, 9, 0), End: utc(2026, 3, 10, 10, 0), Floating: true, RRule: "FREQ=DAILY;COUNT=5", Exdates: []time.Time{utc(2026, 3, 14, 9, 0)}},
+			want: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, ok := Remaining(&tt.ev, nil, now, berlin)
+			if !ok || n != tt.want {
+				t.Fatalf("Remaining = %d, %v, want %d", n, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"schedule/ics"
+)
+
+// Reminder channels, the values of reminderChannels.
+const (
+	ChannelEmail   = "email"
+	ChannelPush    = "push"
+	ChannelWebhook = "webhook"
+)
+
+// ReminderChannels lists the reminder channels.
+var ReminderChannels = []string{ChannelEmail, ChannelPush, ChannelWebhook}
+
+// Channels returns the channels the reminders of ev are delivered through:
+// its reminderChannels or, when there are none, Web Push plus email for
+// reminderType email, as before channels could be picked.
+func (ev *Event) Channels() []string {
+	if len(ev.ReminderChannels) > 0 {
+		return ev.ReminderChannels
+	}
+	if ev.ReminderType == ReminderEmail {
+		return []string{ChannelEmail, ChannelPush}
+	}
+	return []string{ChannelPush}
+}
+
+// HasChannel reports whether the reminders of ev go out through channel.
+func (ev *Event) HasChannel(channel string) bool {
+	return slices.Contains(ev.Channels(), channel)
+}
+
+// ParseReminder reads one reminderMinutes entry: whole minutes (a JSON number
+// or numeric string) or an ISO 8601 duration such as "PT15M", "PT1H30M" or
+// "P1D". A leading "-" on a duration, as in VALARM triggers, reads the same:
+// reminders are before the start. Durations must be whole minutes.
+func ParseReminder(v any) (int, error) {
+	var minutes float64
+	switch x := v.(type) {
+	case float64:
+		minutes = x
+	case json.Number:
+		f, err := x.Float64()
+		if err != nil {
+			return 0, err
+		}
+		minutes = f
+	case string:
+		s := strings.ToUpper(strings.TrimSpace(x))
+		if n, err := strconv.Atoi(s); err == nil {
+			minutes = float64(n)
+			break
+		}
+		if strings.HasPrefix(s, "+") {
+			return 0, fmt.Errorf("%q is after the start", x)
+		}
+		d, err := ics.Duration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q is neither minutes nor an ISO 8601 duration", x)
+		}
+		d = d.Abs()
+		if d%time.Minute != 0 {
+			return 0, fmt.Errorf("%q is not a whole number of minutes", x)
+		}
+		minutes = float64(d / time.Minute)
+	default:
+		return 0, fmt.Errorf("%v is neither minutes nor an ISO 8601 duration", v)
+	}
+
+	if minutes != math.Trunc(minutes) || math.Abs(minutes) > math.MaxInt32 {
+		return 0, fmt.Errorf("%v is not a whole number of minutes", v)
+	}
+	return int(minutes), nil
+}
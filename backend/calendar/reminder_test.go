@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseReminder(t *testing.T) {
+	tests := []struct {
+		in   any
+		want int
+	}{
+		{float64(15), 15},
+		{float64(0), 0},
+		{json.Number("90"), 90},
+		{"30", 30},
+		{"PT15M", 15},
+		{"pt15m", 15},
+		{"PT1H", 60},
+		{"PT1H30M", 90},
+		{"P1D", 1440},
+		{"P1DT2H", 1560},
+		{"P1W", 10080},
+		{"-PT10M", 10},
+		{" PT5M ", 5},
+		{"PT120S", 2},
+	}
+	for _, tt := range tests {
+		got, err := ParseReminder(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseReminder(%#v) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []any{
+		"PT90S",
+		"PT30S",
+		float64(1.5),
+		json.Number("2.5"),
+		"+PT15M",
+		"15 minutes",
+		"P",
+		"PT",
+		"",
+		true,
+		nil,
+		float64(1 << 40),
+	} {
+		if got, err := ParseReminder(in); err == nil {
+			t.Errorf("ParseReminder(%#v) = %d, want an error", in, got)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package calendar
+
+import (
+	"strings"
+
+	"schedule/recur"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// ValidateRRule checks a recurrence rule the way saves do: it must parse
+// (recur.Parse refuses what the expansion can't handle, such as an open-ended
+// MINUTELY rule) and can't have both COUNT and UNTIL, which RFC 5545 allows
+// only one of. The error is the validation error of the rrule field.
+func ValidateRRule(rule string) (*recur.Rule, error) {
+	r, err := recur.Parse(rule)
+	if err != nil {
+		return nil, validation.NewError("validation_invalid_rrule",
+			"Invalid recurrence rule: "+strings.TrimPrefix(err.Error(), "recur: ")+".")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, validation.NewError("validation_rrule_count_and_until",
+			"A recurrence rule can't have both COUNT and UNTIL.")
+	}
+	return r, nil
+}
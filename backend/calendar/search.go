@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Highlight is a match of a search word in a field (title, notes or
+// location) of an occurrence. Start and End are UTF-16 offsets, the string
+// indices of JavaScript, so clients can slice the field as is.
+type Highlight struct {
+	Field string `json:"field"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// SearchOccurrence matches the occurrence against the words of q: it matches
+// when each word appears, case-insensitively, in its title, notes or
+// location. The highlights list every match of every word, by field order
+// then offset.
+func SearchOccurrence(o Occurrence, q string) ([]Highlight, bool) {
+	fields := []struct{ name, text string }{{"title", o.Title}, {"notes", o.Notes}, {"location", o.Location}}
+
+	var out []Highlight
+	for _, word := range strings.Fields(q) {
+		found := false
+		for _, f := range fields {
+			for _, span := range findFold(f.text, word) {
+				out = append(out, Highlight{Field: f.name, Start: span[0], End: span[1]})
+				found = true
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	order := map[string]int{"title": 0, "notes": 1, "location": 2}
+	slices.SortFunc(out, func(a, b Highlight) int {
+		if a.Field != b.Field {
+			return order[a.Field] - order[b.Field]
+		}
+		return a.Start - b.Start
+	})
+	return out, true
+}
+
+// findFold returns the non-overlapping case-insensitive matches of word in
+// text as UTF-16 [start, end) offsets.
+func findFold(text, word string) [][2]int {
+	orig := []rune(text)
+	t, w := foldRunes(text), foldRunes(word)
+	if len(w) == 0 {
+		return nil
+	}
+
+	var out [][2]int
+	offset := 0 // UTF-16 offset of t[i]
+	for i := 0; i+len(w) <= len(t); {
+		if !slices.Equal(t[i:i+len(w)], w) {
+			offset += utf16Len(orig[i])
+			i++
+			continue
+		}
+		end := offset
+		for _, r := range orig[i : i+len(w)] {
+			end += utf16Len(r)
+		}
+		out = append(out, [2]int{offset, end})
+		offset, i = end, i+len(w)
+	}
+	return out
+}
+
+// foldRunes lower-cases s rune by rune, so indices stay aligned with s.
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func utf16Len(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// EventSharesCollection stores the single events shared with users beside
+// their owner.
+const EventSharesCollection = "event_shares"
+
+// Permissions of an event share: read lets the user view the event through
+// the records API, write also update it.
+const (
+	SharePermissionRead  = "read"
+	SharePermissionWrite = "write"
+)
+
+// SharePermissions lists the event share permissions.
+var SharePermissions = []string{SharePermissionRead, SharePermissionWrite}
+
+// AccessFilter is the events filter matching what viewer may read, the same
+// events the records API lists: everything for superusers (and a nil viewer,
+// the server itself), otherwise the events viewer owns or that are shared
+// with them. Unowned events stay superuser-only.
+func AccessFilter(viewer *core.Record) (string, dbx.Params) {
+	if viewer == nil || viewer.IsSuperuser() {
+		return "id != ''", dbx.Params{}
+	}
+	return "owner = {:viewer} || (@collection.event_shares.event ?= id && @collection.event_shares.user ?= {:viewer})",
+		dbx.Params{"viewer": viewer.Id}
+}
+
+// CanAccess reports whether viewer may read the event (permission read) or
+// also change it (permission write): superusers always, owners, and users
+// the event is shared with at that permission or above.
+func CanAccess(app core.App, viewer, event *core.Record, permission string) bool {
+	if viewer == nil {
+		return false
+	}
+	if viewer.IsSuperuser() || (event.GetString("owner") != "" && event.GetString("owner") == viewer.Id) {
+		return true
+	}
+	share, err := app.FindFirstRecordByFilter(EventSharesCollection, "event = {:event} && user = {:user}",
+		dbx.Params{"event": event.Id, "user": viewer.Id})
+	if err != nil {
+		return false
+	}
+	return permission == SharePermissionRead || share.GetString("permission") == SharePermissionWrite
+}
+
+// ShareEvent grants user the permission on the event, replacing the one
+// granted before, and returns the share.
+func ShareEvent(app core.App, event, user *core.Record, permission string) (*core.Record, error) {
+	share, err := app.FindFirstRecordByFilter(EventSharesCollection, "event = {:event} && user = {:user}",
+		dbx.Params{"event": event.Id, "user": user.Id})
+	if err != nil {
+		collection, err := app.FindCollectionByNameOrId(EventSharesCollection)
+		if err != nil {
+			return nil, err
+		}
+		share = core.NewRecord(collection)
+		share.Set("event", event.Id)
+		share.Set("user", user.Id)
+	}
+	share.Set("permission", permission)
+	if err := app.Save(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// UnshareEvent revokes the share of the event with the user, reporting false
+// when there was none.
+func UnshareEvent(app core.App, eventID, userID string) (bool, error) {
+	share, err := app.FindFirstRecordByFilter(EventSharesCollection, "event = {:event} && user = {:user}",
+		dbx.Params{"event": eventID, "user": userID})
+	if err != nil {
+		return false, nil
+	}
+	return true, app.Delete(share)
+}
@@ -0,0 +1,222 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+
+	"schedule/recur"
+)
+
+// splitPreviewLimit caps the occurrences listed per side of a split preview.
+const splitPreviewLimit = 100
+
+// SplitEdit is a "this and future" edit of a series: the occurrence starting
+// at At and every later one move to a new series with the given changes. Nil
+// fields keep the series value.
+type SplitEdit struct {
+	At       time.Time
+	Title    *string
+	Start    *time.Time
+	End      *time.Time
+	AllDay   *bool
+	RRule    *string
+	Location *string
+	Notes    *string
+	Category *string
+	Color    *string
+}
+
+// DetachedChange is what a split does to an existing detached occurrence.
+type DetachedChange struct {
+	ID           string    `json:"id"`
+	RecurrenceID time.Time `json:"recurrenceId"`
+	// Action is "relink" when the new series still has the (shifted) instance
+	// and "delete" otherwise.
+	Action string `json:"action"`
+}
+
+// SeriesParams are the fields of the series created by a split.
+type SeriesParams struct {
+	Title    string      `json:"title"`
+	Start    time.Time   `json:"start"`
+	End      time.Time   `json:"end"`
+	AllDay   bool        `json:"allDay"`
+	RRule    string      `json:"rrule"`
+	Exdates  []time.Time `json:"exdates"`
+	Location string      `json:"location,omitempty"`
+	Notes    string      `json:"notes,omitempty"`
+	Category string      `json:"category,omitempty"`
+	Color    string      `json:"color,omitempty"`
+}
+
+// SplitPlan describes the outcome of a SplitEdit without applying it.
+// Occurrence lists cover the horizon and are capped; the counts are not.
+type SplitPlan struct {
+	// At is the occurrence the new series begins with.
+	At time.Time `json:"at"`
+	// OldRRule is the truncated rule of the original series, empty when the
+	// split is at its first occurrence and the series is deleted.
+	OldRRule     string           `json:"oldRRule"`
+	OldDeleted   bool             `json:"oldDeleted"`
+	Removed      []Occurrence     `json:"removed"`
+	RemovedCount int              `json:"removedCount"`
+	NewSeries    SeriesParams     `json:"newSeries"`
+	Added        []Occurrence     `json:"added"`
+	AddedCount   int              `json:"addedCount"`
+	Detached     []DetachedChange `json:"detached"`
+}
+
+// PlanSplit computes what applying edit to the series ev would change, with
+// loc as the zone of series without one. detached are the detached
+// occurrences of the series. Nothing is persisted.
+func PlanSplit(ev *Event, detached []*Event, edit SplitEdit, horizon time.Duration, loc *time.Location) (*SplitPlan, error) {
+	if !ev.IsRecurring() {
+		return nil, errors.New("the event is not recurring")
+	}
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	dtstart := ev.dtstart(ev.Zone(loc))
+	at := edit.At
+
+	// instances before the split point (exdated ones count for COUNT too)
+	before := 0
+	found := false
+	it := rule.Iter(dtstart)
+	for {
+		t, ok := it.Next()
+		if !ok || t.After(at) {
+			break
+		}
+		if t.Equal(at) {
+			found = true
+			break
+		}
+		before++
+	}
+	if !found {
+		return nil, errors.New("at is not an occurrence of the series")
+	}
+
+	// new series
+	start := at
+	if edit.Start != nil {
+		start = *edit.Start
+	}
+	end := start.Add(ev.Duration())
+	if edit.End != nil {
+		end = *edit.End
+	}
+	if end.Before(start) {
+		return nil, errors.New("end must not be before start")
+	}
+	delta := start.Sub(at)
+
+	newRule := rule
+	if edit.RRule != nil {
+		if newRule, err = recur.Parse(*edit.RRule); err != nil {
+			return nil, err
+		}
+	} else if rule.Count > 0 {
+		c := *rule
+		c.Count -= before
+		newRule = &c
+	}
+
+	params := SeriesParams{
+		Title:    pick(edit.Title, ev.Title),
+		Start:    start.UTC(),
+		End:      end.UTC(),
+		AllDay:   ev.AllDay,
+		RRule:    newRule.String(),
+		Exdates:  []time.Time{},
+		Location: pick(edit.Location, ev.Location),
+		Notes:    pick(edit.Notes, ev.Notes),
+		Category: pick(edit.Category, ev.Category),
+		Color:    pick(edit.Color, ev.Color),
+	}
+	if edit.AllDay != nil {
+		params.AllDay = *edit.AllDay
+	}
+	for _, x := range ev.Exdates {
+		if !x.Before(at) {
+			params.Exdates = append(params.Exdates, x.Add(delta).UTC())
+		}
+	}
+
+	next := *ev
+	next.ID, next.UID = "", "" // not created yet
+	next.Title, next.Start, next.End, next.AllDay = params.Title, start, end, params.AllDay
+	next.RRule, next.Exdates = params.RRule, params.Exdates
+	next.Location, next.Notes, next.Category, next.Color = params.Location, params.Notes, params.Category, params.Color
+
+	plan := &SplitPlan{
+		At:        at.UTC(),
+		NewSeries: params,
+		Removed:   []Occurrence{},
+		Added:     []Occurrence{},
+		Detached:  []DetachedChange{},
+	}
+
+	if before == 0 {
+		plan.OldDeleted = true
+	} else {
+		plan.OldRRule = rule.EndBefore(at).String()
+	}
+
+	plan.Removed, plan.RemovedCount = previewFrom(ev, at, horizon, loc)
+	plan.Added, plan.AddedCount = previewFrom(&next, start, horizon, loc)
+
+	// the replaced instances are exdated, so match against the bare rule
+	rrule := next
+	rrule.Exdates = nil
+	for _, d := range detached {
+		if d.RecurrenceID.Before(at) {
+			continue
+		}
+		shifted := d.RecurrenceID.Add(delta)
+		action := "delete"
+		if occursAt(&rrule, shifted, loc) {
+			action = "relink"
+		}
+		plan.Detached = append(plan.Detached, DetachedChange{ID: d.ID, RecurrenceID: d.RecurrenceID, Action: action})
+	}
+
+	return plan, nil
+}
+
+// previewFrom lists (up to splitPreviewLimit) and counts the occurrences of ev
+// starting in [from, from+horizon), with loc as the fallback zone.
+func previewFrom(ev *Event, from time.Time, horizon time.Duration, loc *time.Location) ([]Occurrence, int) {
+	items := []Occurrence{}
+	n := 0
+	for _, o := range ev.Occurrences(from, from.Add(horizon), loc) {
+		if o.Start.Before(from) {
+			continue
+		}
+		if n++; len(items) < splitPreviewLimit {
+			items = append(items, o)
+		}
+	}
+	return items, n
+}
+
+// occursAt reports whether the series ev has an instance starting at t, with
+// loc as the fallback zone.
+func occursAt(ev *Event, t time.Time, loc *time.Location) bool {
+	for _, o := range ev.Occurrences(t, t.Add(time.Second), loc) {
+		if o.Start.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func pick(v *string, fallback string) string {
+	if v != nil {
+		return *v
+	}
+	return fallback
+}
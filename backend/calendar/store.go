@@ -0,0 +1,132 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindEvents loads the events that may have occurrences in [from, to): every
+// recurring event plus the single events overlapping the window (widened by
+// MaxZoneOffset for floating ones, which move with the viewer's zone).
+//
+// Only the events viewer may read are loaded (see AccessFilter); a nil viewer
+// loads every event, for the server's own jobs.
+func FindEvents(app core.App, viewer *core.Record, from, to time.Time) ([]*Event, error) {
+	access, params := AccessFilter(viewer)
+	return findEventsIn(app, access, params, from, to)
+}
+
+// FindReminderEvents loads the owned events with reminderMinutes that may
+// have occurrences in [from, to), for the reminder dispatcher.
+func FindReminderEvents(app core.App, from, to time.Time) ([]*Event, error) {
+	return findEventsIn(app, "owner != '' && reminderMinutes != null && reminderMinutes != '[]'", dbx.Params{}, from, to)
+}
+
+// findEventsIn loads the events matching filter that may have occurrences
+// in [from, to), see FindEvents.
+func findEventsIn(app core.App, filter string, params dbx.Params, from, to time.Time) ([]*Event, error) {
+	params["from"] = dateParam(from)
+	params["to"] = dateParam(to)
+	params["floatFrom"] = dateParam(from.Add(-MaxZoneOffset))
+	params["floatTo"] = dateParam(to.Add(MaxZoneOffset))
+	records, err := app.FindRecordsByFilter(
+		EventsCollection,
+		"("+filter+") && (rrule != '' || (start < {:to} && end >= {:from}) || (floating = true && start < {:floatTo} && end >= {:floatFrom}))",
+		"start",
+		0,
+		0,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventsFromRecords(records), nil
+}
+
+// dateParam formats t the way PocketBase stores DateField values so it can be
+// compared as a string in filters.
+func dateParam(t time.Time) string {
+	return t.UTC().Format(types.DefaultDateLayout)
+}
+
+// FindOwnedEvents loads every event owned by the given user, or every event
+// when ownerID is empty.
+func FindOwnedEvents(app core.App, ownerID string) ([]*Event, error) {
+	filter := "owner = {:owner}"
+	if ownerID == "" {
+		filter = "id != ''"
+	}
+	records, err := app.FindRecordsByFilter(EventsCollection, filter, "start", 0, 0, dbx.Params{"owner": ownerID})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromRecords(records), nil
+}
+
+// FindCategoryEvents loads the events of a category, limited to one owner
+// unless ownerID is empty.
+func FindCategoryEvents(app core.App, category, ownerID string) ([]*Event, error) {
+	filter := "category = {:category}"
+	if ownerID != "" {
+		filter += " && owner = {:owner}"
+	}
+	records, err := app.FindRecordsByFilter(EventsCollection, filter, "start", 0, 0, dbx.Params{"category": category, "owner": ownerID})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromRecords(records), nil
+}
+
+// FindDetached loads the detached occurrences of a series.
+func FindDetached(app core.App, seriesID string) ([]*Event, error) {
+	records, err := app.FindAllRecords(EventsCollection, dbx.HashExp{"sourceId": seriesID})
+	if err != nil {
+		return nil, err
+	}
+	return eventsFromRecords(records), nil
+}
+
+func eventsFromRecords(records []*core.Record) []*Event {
+	events := make([]*Event, len(records))
+	for i, r := range records {
+		events[i] = EventFromRecord(r)
+	}
+	return events
+}
+
+// RecordEventDeletion notes at as the latest deletion of one of ownerID's
+// events. The column is written directly, so the user's updated time and
+// record hooks are left alone.
+func RecordEventDeletion(app core.App, ownerID string, at time.Time) error {
+	if ownerID == "" {
+		return nil
+	}
+	deleted, err := types.ParseDateTime(at)
+	if err != nil {
+		return err
+	}
+	_, err = app.DB().Update(UsersCollection, dbx.Params{"eventsDeleted": deleted.String()}, dbx.HashExp{"id": ownerID}).Execute()
+	return err
+}
+
+// LastEventDeletion is when one of ownerID's events, or anyone's when
+// ownerID is empty, was last deleted. Zero when none was.
+func LastEventDeletion(app core.App, ownerID string) (time.Time, error) {
+	q := app.DB().Select("COALESCE(MAX(eventsDeleted), '')").From(UsersCollection)
+	if ownerID != "" {
+		q = q.Where(dbx.HashExp{"id": ownerID})
+	}
+	var last string
+	if err := q.Row(&last); err != nil {
+		return time.Time{}, err
+	}
+	deleted, err := types.ParseDateTime(last)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return deleted.Time(), nil
+}
@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"container/heap"
+	"time"
+
+	"schedule/recur"
+)
+
+// cursor lazily walks the occurrences of one event overlapping [from, to).
+type cursor struct {
+	ev       *Event
+	it       *recur.Iterator // nil for single events
+	dur      time.Duration
+	from, to time.Time
+	start    time.Time // of single events, placed in the zone if floating
+	excluded exdateSet
+	cur      Occurrence
+	done     bool
+
+	// replaying cursors walk an expansion computed before (see
+	// ExpansionCache) instead of the event
+	replay    []Occurrence
+	replaying bool
+}
+
+func newCursor(ev *Event, from, to time.Time, loc *time.Location) *cursor {
+	c := &cursor{ev: ev, dur: ev.Duration(), from: from, to: to, start: ev.Start}
+	if !ev.IsRecurring() {
+		if ev.Floating {
+			c.start = WallClock(ev.Start.UTC(), ev.Zone(loc))
+		}
+		return c
+	}
+
+	rule, err := recur.Parse(ev.RRule)
+	if err != nil {
+		c.done = true
+		return c
+	}
+
+	c.it = rule.Iter(ev.dtstart(ev.Zone(loc)))
+	c.excluded = newExdateSet(ev, ev.Zone(loc))
+	// instances starting up to one duration before the window may still overlap it
+	c.it.Seek(from.Add(-c.dur))
+	return c
+}
+
+// next advances to the next occurrence, reporting false once exhausted.
+func (c *cursor) next() bool {
+	if c.replaying {
+		if len(c.replay) == 0 {
+			return false
+		}
+		c.cur, c.replay = c.replay[0], c.replay[1:]
+		return true
+	}
+	if c.done {
+		return false
+	}
+
+	if c.it == nil {
+		c.done = true
+		if !overlaps(c.start, c.start.Add(c.dur), c.from, c.to) {
+			return false
+		}
+		c.cur = c.ev.occurrence(c.start, c.start.Add(c.dur))
+		return true
+	}
+
+	for {
+		t, ok := c.it.Next()
+		if !ok || !t.Before(c.to) {
+			c.done = true
+			return false
+		}
+		end := c.ev.endAt(t)
+		if c.ev.skips(c.excluded, t) || !overlaps(t, end, c.from, c.to) {
+			continue
+		}
+		c.cur = c.ev.occurrence(t, end)
+		return true
+	}
+}
+
+// cursorHeap orders cursors by their current occurrence.
+type cursorHeap []*cursor
+
+func (h cursorHeap) Len() int           { return len(h) }
+func (h cursorHeap) Less(i, j int) bool { return less(h[i].cur, h[j].cur) }
+func (h cursorHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeap) Push(x any)        { *h = append(*h, x.(*cursor)) }
+func (h *cursorHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// Stream calls fn for every occurrence of events overlapping [from, to), in
+// the same order Expand returns them, without buffering the whole expansion:
+// only one pending occurrence per event is held in memory. It stops at the
+// first error returned by fn.
+func Stream(events []*Event, from, to time.Time, loc *time.Location, fn func(Occurrence) error) error {
+	return (*ExpansionCache)(nil).Stream(events, from, to, loc, fn)
+}
+
+// streamCursors merges the occurrences of cursors in order.
+func streamCursors(cursors []*cursor, fn func(Occurrence) error) error {
+	h := make(cursorHeap, 0, len(cursors))
+	for _, c := range cursors {
+		if c.next() {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	for h.Len() > 0 {
+		c := h[0]
+		if err := fn(c.cur); err != nil {
+			return err
+		}
+		if c.next() {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package calendar
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// suggestStopwords are words too common in titles to say anything about the
+// category or tags of an event.
+var suggestStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true,
+	"into": true, "about": true, "our": true, "your": true,
+}
+
+// Suggestion is one ranked guess. Score is in (0, 1]: the share of the
+// matching history that used this value, averaged over the title keywords.
+type Suggestion struct {
+	Value string  `json:"value"`
+	Score float64 `json:"score"`
+}
+
+// KeywordModel maps title keywords to the categories and tags of the events
+// they appeared in.
+type KeywordModel struct {
+	categories map[string]map[string]int // keyword -> category -> events
+	tags       map[string]map[string]int // keyword -> tag -> events
+	seen       map[string]int            // keyword -> events
+}
+
+// BuildKeywordModel learns from the titles of past events.
+func BuildKeywordModel(events []*Event) *KeywordModel {
+	m := &KeywordModel{
+		categories: map[string]map[string]int{},
+		tags:       map[string]map[string]int{},
+		seen:       map[string]int{},
+	}
+	for _, ev := range events {
+		for _, kw := range Keywords(ev.Title) {
+			m.seen[kw]++
+			if ev.Category != "" {
+				count(m.categories, kw, ev.Category)
+			}
+			for _, tag := range ev.Tags {
+				if tag != "" {
+					count(m.tags, kw, tag)
+				}
+			}
+		}
+	}
+	return m
+}
+
+func count(index map[string]map[string]int, kw, value string) {
+	if index[kw] == nil {
+		index[kw] = map[string]int{}
+	}
+	index[kw][value]++
+}
+
+// Suggest ranks the categories and tags for a title, best first. Keywords the
+// model has never seen are ignored; ties are broken alphabetically so the
+// result is stable.
+func (m *KeywordModel) Suggest(title string) (categories, tags []Suggestion) {
+	keywords := Keywords(title)
+	return m.rank(m.categories, keywords), m.rank(m.tags, keywords)
+}
+
+func (m *KeywordModel) rank(index map[string]map[string]int, keywords []string) []Suggestion {
+	// summed in keyword order, so the words of a title can come in any order
+	// and still add up to the same floats (and the same ties)
+	keywords = slices.Clone(keywords)
+	sort.Strings(keywords)
+
+	scores := map[string]float64{}
+	known := 0
+	for _, kw := range keywords {
+		if m.seen[kw] == 0 {
+			continue
+		}
+		known++
+		for value, n := range index[kw] {
+			scores[value] += float64(n) / float64(m.seen[kw])
+		}
+	}
+
+	out := []Suggestion{}
+	for value, score := range scores {
+		out = append(out, Suggestion{Value: value, Score: score / float64(known)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
+
+// Keywords splits a title into its distinct lowercased words of three or more
+// letters or digits, minus stopwords, in order of appearance.
+func Keywords(title string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var out []string
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if len([]rune(f)) < 3 || suggestStopwords[f] || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
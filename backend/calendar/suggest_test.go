@@ -0,0 +1,83 @@
+package calendar
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	m := BuildKeywordModel([]*Event{
+		{Title: "Math lecture", Category: "College", Tags: []string{"math"}},
+		{Title: "Math homework", Category: "College", Tags: []string{"math", "homework"}},
+		{Title: "Math tutoring", Category: "Personal", Tags: []string{"tutoring"}},
+		{Title: "Gym with Sam", Category: "Personal", Tags: []string{"sport"}},
+		{Title: "Gym", Category: "Other", Tags: []string{"sport"}},
+		{Title: "Lecture notes"},
+	})
+
+	tests := []struct {
+		title      string
+		categories []Suggestion
+		tags       []Suggestion
+	}{
+		{
+			title:      "Math exam",
+			categories: []Suggestion{{"College", 2.0 / 3}, {"Personal", 1.0 / 3}},
+			tags:       []Suggestion{{"math", 2.0 / 3}, {"homework", 1.0 / 3}, {"tutoring", 1.0 / 3}},
+		},
+		{
+			// a tie, broken alphabetically
+			title:      "gym",
+			categories: []Suggestion{{"Other", 0.5}, {"Personal", 0.5}},
+			tags:       []Suggestion{{"sport", 1}},
+		},
+		{
+			// averaged over the known keywords: math (2/3 College) and
+			// lecture (1/2 College, the other lecture has no category)
+			title:      "Lecture: math",
+			categories: []Suggestion{{"College", (2.0/3 + 1.0/2) / 2}, {"Personal", 1.0 / 6}},
+			tags:       []Suggestion{{"math", (2.0/3 + 1.0/2) / 2}, {"homework", 1.0 / 6}, {"tutoring", 1.0 / 6}},
+		},
+		{
+			title:      "Dentist",
+			categories: []Suggestion{},
+			tags:       []Suggestion{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			categories, tags := m.Suggest(tt.title)
+			if !sameSuggestions(categories, tt.categories) {
+				t.Errorf("categories = %v, want %v", categories, tt.categories)
+			}
+			if !sameSuggestions(tags, tt.tags) {
+				t.Errorf("tags = %v, want %v", tags, tt.tags)
+			}
+		})
+	}
+
+}
+
+// Scores of 1, 1/2 and 1/6 add up to a different float depending on the
+// order they are summed in.
+func TestSuggestWordOrder(t *testing.T) {
+	m := BuildKeywordModel([]*Event{
+		{Title: "Alpha", Category: "College"},
+		{Title: "Beta", Category: "College"}, {Title: "Beta"},
+		{Title: "Gamma", Category: "College"}, {Title: "Gamma"}, {Title: "Gamma"},
+		{Title: "Gamma"}, {Title: "Gamma"}, {Title: "Gamma"},
+	})
+	a, _ := m.Suggest("Alpha Beta Gamma")
+	b, _ := m.Suggest("Gamma Beta Alpha")
+	if !slices.Equal(a, b) {
+		t.Errorf("scores depend on the word order: %v and %v", a, b)
+	}
+}
+
+// sameSuggestions compares rankings, the scores up to rounding.
+func sameSuggestions(got, want []Suggestion) bool {
+	return slices.EqualFunc(got, want, func(a, b Suggestion) bool {
+		return a.Value == b.Value && math.Abs(a.Score-b.Score) < 1e-9
+	})
+}
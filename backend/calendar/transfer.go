@@ -0,0 +1,51 @@
+package calendar
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// TransferKey is a custom (non-persisted) record key marking a save as an
+// ownership transfer, logged as "transfer" instead of "update".
+const TransferKey = "@transfer"
+
+// TransferEvents hands the events to the user to, in one transaction: each
+// gets to as its owner and moves into their default calendar, detached
+// occurrences along with their series. Events of external calendars are left
+// out, they belong to the subscription mirroring them. actor is logged as the
+// author of the change. It returns how many events (occurrences included)
+// changed hands.
+func TransferEvents(app core.App, events []*core.Record, to, actor *core.Record) (int, error) {
+	moved := 0
+	err := app.RunInTransaction(func(txApp core.App) error {
+		cal, err := DefaultCalendar(txApp, to.Id)
+		if err != nil {
+			return err
+		}
+
+		seen := map[string]bool{}
+		for _, rec := range events {
+			detached, err := txApp.FindAllRecords(EventsCollection, dbx.HashExp{"sourceId": rec.Id})
+			if err != nil {
+				return err
+			}
+			for _, r := range append([]*core.Record{rec}, detached...) {
+				if seen[r.Id] || r.GetString("owner") == to.Id || IsExternalCalendar(txApp, r.GetString("calendar")) {
+					continue
+				}
+				seen[r.Id] = true
+
+				r.Set("owner", to.Id)
+				r.Set("calendar", cal.Id)
+				r.Set(TransferKey, true)
+				SetChangedBy(r, actor)
+				if err := txApp.Save(r); err != nil {
+					return err
+				}
+				moved++
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
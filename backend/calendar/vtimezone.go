@@ -0,0 +1,146 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"schedule/ics"
+)
+
+// vtimezoneLookahead is how far past now VTimezone looks for the transitions
+// its yearly rules are derived from.
+const vtimezoneLookahead = 3 * 365 * 24 * time.Hour
+
+// transition is a change of UTC offset of a zone.
+type transition struct {
+	at       time.Time // instant of the change
+	from, to int       // offsets before and after, in seconds
+	name     string    // abbreviation after the change
+	dst      bool      // whether the new offset is daylight time
+}
+
+// onset is the wall clock time the transition happens at, in the offset it
+// ends, as VTIMEZONE observances spell DTSTART.
+func (t transition) onset() time.Time {
+	return t.at.In(time.FixedZone("", t.from))
+}
+
+// yearlyRule is the yearly recurrence the transition follows when it sits on
+// the n-th (or last) weekday of its month, e.g. "BYMONTH=3;BYDAY=-1SU".
+func (t transition) yearlyRule() string {
+	local := t.onset()
+	n := (local.Day()-1)/7 + 1
+	if local.Day()+7 > daysInMonth(local) {
+		n = -1
+	}
+	return "BYMONTH=" + strconv.Itoa(int(local.Month())) + ";BYDAY=" + strconv.Itoa(n) + weekdayCodes[local.Weekday()]
+}
+
+// sameRule reports whether b is a year after a and follows the same rule.
+func (a transition) sameRule(b transition) bool {
+	return b.onset().Year() == a.onset().Year()+1 && a.dst == b.dst && a.from == b.from && a.to == b.to &&
+		a.yearlyRule() == b.yearlyRule() && a.onset().Format("150405") == b.onset().Format("150405")
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// zoneTransitions lists the offset changes of loc in [from, to).
+func zoneTransitions(loc *time.Location, from, to time.Time) []transition {
+	var out []transition
+	for t := from; ; {
+		_, end := t.In(loc).ZoneBounds()
+		if end.IsZero() || !end.Before(to) {
+			return out
+		}
+		_, before := t.In(loc).Zone()
+		name, after := end.In(loc).Zone()
+		if before != after {
+			out = append(out, transition{at: end, from: before, to: after, name: name, dst: end.In(loc).IsDST()})
+		}
+		t = end
+	}
+}
+
+// VTimezone builds the VTIMEZONE of loc for times from from on, so clients
+// can place TZID times of the zone. The latest transitions of each kind that
+// follow one yearly rule up to a few years from now become a single STANDARD
+// or DAYLIGHT observance with an RRULE; older or irregular ones are listed one
+// by one. A zone without transitions gets a single STANDARD observance.
+func VTimezone(loc *time.Location, from time.Time) *ics.Component {
+	start := time.Date(from.In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	to := time.Now().Add(vtimezoneLookahead)
+	trs := zoneTransitions(loc, start, to)
+
+	// rules[i] is the RRULE of the observance trs[i] starts; skip marks the
+	// transitions an earlier RRULE already covers
+	rules := make([]string, len(trs))
+	skip := make([]bool, len(trs))
+	for _, dst := range []bool{false, true} {
+		var kind []int
+		for i, t := range trs {
+			if t.dst == dst {
+				kind = append(kind, i)
+			}
+		}
+		if len(kind) < 2 || trs[kind[len(kind)-1]].onset().Year() < to.Year()-1 {
+			continue // no rule still in force
+		}
+		first := len(kind) - 1
+		for first > 0 && trs[kind[first-1]].sameRule(trs[kind[first]]) {
+			first--
+		}
+		if first == len(kind)-1 {
+			continue
+		}
+		rules[kind[first]] = "FREQ=YEARLY;" + trs[kind[first]].yearlyRule()
+		for _, i := range kind[first+1:] {
+			skip[i] = true
+		}
+	}
+
+	tz := ics.NewComponent("VTIMEZONE").Add("TZID", loc.String())
+	// the offset in effect from the start until the first transition
+	name, offset := start.Zone()
+	tz.AddChild(observance(transition{at: start, from: offset, to: offset, name: name, dst: start.IsDST()}, ""))
+	for i, t := range trs {
+		if !skip[i] {
+			tz.AddChild(observance(t, rules[i]))
+		}
+	}
+	return tz
+}
+
+// observance is the STANDARD or DAYLIGHT sub-component starting with t.
+func observance(t transition, rrule string) *ics.Component {
+	kind := "STANDARD"
+	if t.dst {
+		kind = "DAYLIGHT"
+	}
+	c := ics.NewComponent(kind).
+		Add("DTSTART", ics.FormatFloating(t.onset())).
+		Add("TZOFFSETFROM", formatOffset(t.from)).
+		Add("TZOFFSETTO", formatOffset(t.to))
+	if rrule != "" {
+		c.Add("RRULE", rrule)
+	}
+	return c.AddText("TZNAME", t.name)
+}
+
+// formatOffset spells a UTC offset in seconds as a UTC-OFFSET value (+0100,
+// seconds only when there are some).
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
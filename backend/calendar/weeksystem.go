@@ -0,0 +1,92 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// WeekSystem is a week numbering convention, used to label weeks and months;
+// dates stay Gregorian. Weeks start on FirstDay and week 1 of a year is the
+// first week with at least MinDays days in it, so it is the week holding
+// January MinDays.
+type WeekSystem struct {
+	Name     string       `json:"name"`
+	FirstDay time.Weekday `json:"firstDay"`
+	MinDays  int          `json:"minDays"`
+}
+
+// WeekSystems lists the supported week numbering conventions by name: ISO
+// 8601 (Monday weeks, week 1 holds the first Thursday), the North American
+// one (Sunday weeks, week 1 holds January 1st) and the Middle Eastern one
+// (Saturday weeks, week 1 holds January 1st).
+var WeekSystems = map[string]WeekSystem{
+	"iso": {Name: "iso", FirstDay: time.Monday, MinDays: 4},
+	"us":  {Name: "us", FirstDay: time.Sunday, MinDays: 1},
+	"me":  {Name: "me", FirstDay: time.Saturday, MinDays: 1},
+}
+
+// SystemWeek is a week number in a WeekSystem. Year is the year the week
+// belongs to, which differs from the calendar year of its first or last days
+// around New Year.
+type SystemWeek struct {
+	Year  int    `json:"year"`
+	Week  int    `json:"week"`
+	Label string `json:"label"`
+}
+
+// WeekOf returns the week of the day containing t in loc.
+func (s WeekSystem) WeekOf(t time.Time, loc *time.Location) SystemWeek {
+	day := civilDay(StartOfDay(t, loc))
+	year := day.Year()
+	for y := year + 1; y >= year-1; y-- {
+		first := s.firstWeek(y)
+		if !day.Before(first) {
+			w := SystemWeek{Year: y, Week: int(day.Sub(first).Hours()/24)/7 + 1}
+			w.Label = s.label(w)
+			return w
+		}
+	}
+	return SystemWeek{} // unreachable: week 1 of year-1 starts before the day
+}
+
+// WeekRange returns the week containing t in loc, in the system's days.
+func (s WeekSystem) WeekRange(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	return WeekRange(t, loc, s.FirstDay)
+}
+
+// RowWeek returns the week a display row starting at rowStart, whatever its
+// first day, is labelled with: the week of the system's first day inside the
+// row (see RowISOWeek).
+func (s WeekSystem) RowWeek(rowStart time.Time, loc *time.Location) SystemWeek {
+	day := StartOfDay(rowStart, loc)
+	offset := (int(s.FirstDay) - int(day.Weekday()) + 7) % 7
+	return s.WeekOf(day.AddDate(0, 0, offset), loc)
+}
+
+// MonthOf returns the month a week starting at weekStart is listed under:
+// the month of its middle (fourth) day, which holds most of its days.
+func (s WeekSystem) MonthOf(weekStart time.Time, loc *time.Location) (int, time.Month) {
+	y, m, _ := StartOfDay(weekStart, loc).AddDate(0, 0, 3).Date()
+	return y, m
+}
+
+// firstWeek returns the first day of week 1 of year, as a civil day.
+func (s WeekSystem) firstWeek(year int) time.Time {
+	anchor := time.Date(year, time.January, s.MinDays, 0, 0, 0, 0, time.UTC)
+	offset := (int(anchor.Weekday()) - int(s.FirstDay) + 7) % 7
+	return anchor.AddDate(0, 0, -offset)
+}
+
+func (s WeekSystem) label(w SystemWeek) string {
+	if s.Name == "iso" {
+		return fmt.Sprintf("%d-W%02d", w.Year, w.Week)
+	}
+	return fmt.Sprintf("Week %d, %d", w.Week, w.Year)
+}
+
+// civilDay returns the date of t as midnight UTC, where days are always 24
+// hours long.
+func civilDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
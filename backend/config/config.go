@@ -0,0 +1,326 @@
+// Package config reads the schedule specific settings from the environment.
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the schedule settings. Values come from SCHEDULE_* environment
+// variables and fall back to the defaults documented on each field.
+type Config struct {
+	// WeekStart is the first day of the week used for week ranges and grids
+	// (SCHEDULE_WEEK_START, 0=Sunday .. 6=Saturday, default 1=Monday).
+	WeekStart time.Weekday
+
+	// Timezone is used when a request does not pass ?timezone
+	// (SCHEDULE_TIMEZONE, default UTC).
+	Timezone *time.Location
+
+	// Horizon bounds how far ahead open-ended recurrences are expanded for
+	// checks such as resource conflicts (SCHEDULE_HORIZON_DAYS, default 366).
+	Horizon time.Duration
+
+	// VAPID keys and subject (a mailto: or https: contact) used to sign Web
+	// Push reminders (SCHEDULE_VAPID_PUBLIC_KEY, SCHEDULE_VAPID_PRIVATE_KEY,
+	// SCHEDULE_VAPID_SUBJECT). Push is disabled unless both keys are set.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
+
+	// MailRate caps how many queued emails are sent per minute and
+	// MailConcurrency how many are in flight at once (SCHEDULE_MAIL_RATE,
+	// default 30; SCHEDULE_MAIL_CONCURRENCY, default 2). MailMaxAttempts is
+	// how often a failing email is tried before it is given up
+	// (SCHEDULE_MAIL_MAX_ATTEMPTS, default 5).
+	MailRate        int
+	MailConcurrency int
+	MailMaxAttempts int
+
+	// HistoryRetention is how long event change log entries are kept
+	// (SCHEDULE_HISTORY_RETENTION_DAYS, default 180, 0 keeps them forever);
+	// the HistoryKeep most recent entries of each event stay regardless
+	// (SCHEDULE_HISTORY_KEEP, default 20).
+	HistoryRetention time.Duration
+	HistoryKeep      int
+
+	// ReminderGrace is how late reminders missed while the server was down
+	// are still sent on startup (SCHEDULE_REMINDER_GRACE_MINUTES, default 10,
+	// 0 sends none); older ones are only logged.
+	ReminderGrace time.Duration
+
+	// ReminderWebhookSecret signs the reminders posted to user webhooks
+	// (SCHEDULE_REMINDER_WEBHOOK_SECRET, default none, unsigned): the
+	// X-Schedule-Signature header carries "sha256=" and the hex HMAC-SHA256
+	// of the body.
+	ReminderWebhookSecret string
+
+	// MinEventDuration and MaxEventDuration bound how long a timed event can
+	// last (SCHEDULE_MIN_EVENT_MINUTES, SCHEDULE_MAX_EVENT_HOURS, default 0,
+	// no limit). All-day events are exempt from the minimum, events flagged
+	// multiDay from the maximum.
+	MinEventDuration time.Duration
+	MaxEventDuration time.Duration
+
+	// DefaultDuration, DefaultCategory and DefaultColor fill the end, category
+	// and color of events created without them (SCHEDULE_DEFAULT_DURATION_MINUTES,
+	// default 60, all-day events last a day; SCHEDULE_DEFAULT_CATEGORY and
+	// SCHEDULE_DEFAULT_COLOR, default none).
+	DefaultDuration time.Duration
+	DefaultCategory string
+	DefaultColor    string
+
+	// CascadeDependencies makes moving an event push the events depending on
+	// it (dependsOn) that would start before it ends, instead of rejecting
+	// the change (SCHEDULE_CASCADE_DEPENDENCIES, default false).
+	CascadeDependencies bool
+
+	// WorkStart and WorkEnd are the working hours as offsets from midnight
+	// and WorkDays the working days, used by the free time summary
+	// (SCHEDULE_WORK_HOURS, default 09:00-17:00; SCHEDULE_WORK_DAYS, comma
+	// separated 0=Sunday .. 6=Saturday, default 1,2,3,4,5).
+	WorkStart time.Duration
+	WorkEnd   time.Duration
+	WorkDays  []time.Weekday
+
+	// MaxExdates caps the exdates of one event; saves growing them past it
+	// are rejected (SCHEDULE_MAX_EXDATES, default 5000, 0 for no limit).
+	MaxExdates int
+
+	// EventsCollection is the name of the PocketBase collection storing events
+	// (SCHEDULE_EVENTS_COLLECTION, default events), to keep clear of other
+	// collections when the backend is embedded into a larger PocketBase app.
+	// It has to be set before the first start: the migrations create the
+	// collection under it, and later changes need the collection renamed to
+	// match.
+	EventsCollection string
+
+	// BasePath is the sub-path the frontend is served under, e.g. /schedule
+	// behind a reverse proxy (SCHEDULE_BASE_PATH, default none, the root).
+	// The root then redirects to it. The API and the dashboard stay at /api
+	// and /_.
+	BasePath string
+}
+
+// PushEnabled reports whether Web Push reminders are configured.
+func (c *Config) PushEnabled() bool {
+	return c.VAPIDPublicKey != "" && c.VAPIDPrivateKey != ""
+}
+
+// Load reads the configuration from the environment.
+func Load() (*Config, error) {
+	cfg := &Config{
+		WeekStart: time.Monday,
+		Timezone:  time.UTC,
+		Horizon:   366 * 24 * time.Hour,
+
+		MailRate:        30,
+		MailConcurrency: 2,
+		MailMaxAttempts: 5,
+
+		HistoryRetention: 180 * 24 * time.Hour,
+		HistoryKeep:      20,
+
+		ReminderGrace: 10 * time.Minute,
+
+		DefaultDuration: time.Hour,
+
+		WorkStart: 9 * time.Hour,
+		WorkEnd:   17 * time.Hour,
+		WorkDays:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+
+		MaxExdates: 5000,
+
+		EventsCollection: "events",
+	}
+
+	if v := os.Getenv("SCHEDULE_WEEK_START"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 6 {
+			return nil, fmt.Errorf("SCHEDULE_WEEK_START must be 0-6, got %q", v)
+		}
+		cfg.WeekStart = time.Weekday(n)
+	}
+
+	if err := CheckTZData(); err != nil {
+		return nil, err
+	}
+
+	if v := os.Getenv("SCHEDULE_TIMEZONE"); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return nil, fmt.Errorf("SCHEDULE_TIMEZONE: %w", err)
+		}
+		cfg.Timezone = loc
+	}
+
+	if v := os.Getenv("SCHEDULE_HORIZON_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("SCHEDULE_HORIZON_DAYS must be a positive number, got %q", v)
+		}
+		cfg.Horizon = time.Duration(n) * 24 * time.Hour
+	}
+
+	cfg.VAPIDPublicKey = os.Getenv("SCHEDULE_VAPID_PUBLIC_KEY")
+	cfg.VAPIDPrivateKey = os.Getenv("SCHEDULE_VAPID_PRIVATE_KEY")
+	cfg.VAPIDSubject = os.Getenv("SCHEDULE_VAPID_SUBJECT")
+	if (cfg.VAPIDPublicKey == "") != (cfg.VAPIDPrivateKey == "") {
+		return nil, fmt.Errorf("SCHEDULE_VAPID_PUBLIC_KEY and SCHEDULE_VAPID_PRIVATE_KEY must be set together")
+	}
+	if cfg.PushEnabled() && cfg.VAPIDSubject == "" {
+		return nil, fmt.Errorf("SCHEDULE_VAPID_SUBJECT is required when Web Push is enabled")
+	}
+
+	for _, v := range []struct {
+		env string
+		dst *int
+	}{
+		{"SCHEDULE_MAIL_RATE", &cfg.MailRate},
+		{"SCHEDULE_MAIL_CONCURRENCY", &cfg.MailConcurrency},
+		{"SCHEDULE_MAIL_MAX_ATTEMPTS", &cfg.MailMaxAttempts},
+	} {
+		raw := os.Getenv(v.env)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("%s must be a positive number, got %q", v.env, raw)
+		}
+		*v.dst = n
+	}
+
+	if v := os.Getenv("SCHEDULE_HISTORY_RETENTION_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SCHEDULE_HISTORY_RETENTION_DAYS must be 0 or a positive number, got %q", v)
+		}
+		cfg.HistoryRetention = time.Duration(n) * 24 * time.Hour
+	}
+	if v := os.Getenv("SCHEDULE_HISTORY_KEEP"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SCHEDULE_HISTORY_KEEP must be 0 or a positive number, got %q", v)
+		}
+		cfg.HistoryKeep = n
+	}
+
+	if v := os.Getenv("SCHEDULE_REMINDER_GRACE_MINUTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 24*60 {
+			return nil, fmt.Errorf("SCHEDULE_REMINDER_GRACE_MINUTES must be 0-1440, got %q", v)
+		}
+		cfg.ReminderGrace = time.Duration(n) * time.Minute
+	}
+
+	cfg.ReminderWebhookSecret = os.Getenv("SCHEDULE_REMINDER_WEBHOOK_SECRET")
+
+	if v := os.Getenv("SCHEDULE_MIN_EVENT_MINUTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SCHEDULE_MIN_EVENT_MINUTES must be 0 or a positive number, got %q", v)
+		}
+		cfg.MinEventDuration = time.Duration(n) * time.Minute
+	}
+	if v := os.Getenv("SCHEDULE_MAX_EVENT_HOURS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SCHEDULE_MAX_EVENT_HOURS must be 0 or a positive number, got %q", v)
+		}
+		cfg.MaxEventDuration = time.Duration(n) * time.Hour
+	}
+	if cfg.MaxEventDuration > 0 && cfg.MaxEventDuration < cfg.MinEventDuration {
+		return nil, fmt.Errorf("SCHEDULE_MAX_EVENT_HOURS must not be below SCHEDULE_MIN_EVENT_MINUTES")
+	}
+
+	if v := os.Getenv("SCHEDULE_DEFAULT_DURATION_MINUTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 7*24*60 {
+			return nil, fmt.Errorf("SCHEDULE_DEFAULT_DURATION_MINUTES must be 1-10080, got %q", v)
+		}
+		cfg.DefaultDuration = time.Duration(n) * time.Minute
+	}
+	cfg.DefaultCategory = os.Getenv("SCHEDULE_DEFAULT_CATEGORY")
+	cfg.DefaultColor = os.Getenv("SCHEDULE_DEFAULT_COLOR")
+	if len(cfg.DefaultColor) > 50 {
+		return nil, fmt.Errorf("SCHEDULE_DEFAULT_COLOR must be at most 50 characters")
+	}
+
+	if v := os.Getenv("SCHEDULE_CASCADE_DEPENDENCIES"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("SCHEDULE_CASCADE_DEPENDENCIES must be true or false, got %q", v)
+		}
+		cfg.CascadeDependencies = on
+	}
+
+	if v := os.Getenv("SCHEDULE_WORK_HOURS"); v != "" {
+		from, to, ok := strings.Cut(v, "-")
+		start, err1 := parseClock(from)
+		end, err2 := parseClock(to)
+		if !ok || err1 != nil || err2 != nil || end <= start {
+			return nil, fmt.Errorf("SCHEDULE_WORK_HOURS must look like 09:00-17:00, got %q", v)
+		}
+		cfg.WorkStart, cfg.WorkEnd = start, end
+	}
+	if v := os.Getenv("SCHEDULE_WORK_DAYS"); v != "" {
+		cfg.WorkDays = nil
+		for _, part := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n < 0 || n > 6 {
+				return nil, fmt.Errorf("SCHEDULE_WORK_DAYS must list days 0-6, got %q", v)
+			}
+			cfg.WorkDays = append(cfg.WorkDays, time.Weekday(n))
+		}
+	}
+
+	if v := os.Getenv("SCHEDULE_MAX_EXDATES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("SCHEDULE_MAX_EXDATES must be 0 or a positive number, got %q", v)
+		}
+		cfg.MaxExdates = n
+	}
+
+	if v := os.Getenv("SCHEDULE_EVENTS_COLLECTION"); v != "" {
+		if !collectionName.MatchString(v) {
+			return nil, fmt.Errorf("SCHEDULE_EVENTS_COLLECTION must be letters, digits and underscores starting with a letter, got %q", v)
+		}
+		cfg.EventsCollection = v
+	}
+
+	if v := strings.Trim(os.Getenv("SCHEDULE_BASE_PATH"), "/"); v != "" {
+		first, _, _ := strings.Cut(v, "/")
+		if !basePath.MatchString(v) || first == "api" || first == "_" {
+			return nil, fmt.Errorf("SCHEDULE_BASE_PATH must be a URL path such as /schedule, outside /api and /_, got %q", os.Getenv("SCHEDULE_BASE_PATH"))
+		}
+		cfg.BasePath = "/" + v
+	}
+
+	return cfg, nil
+}
+
+// basePath matches the paths SCHEDULE_BASE_PATH accepts, slashes trimmed:
+// segments of unreserved URL characters, none of them dot segments.
+var basePath = regexp.MustCompile(`^(?:[A-Za-z0-9_~-][A-Za-z0-9._~-]*/)*[A-Za-z0-9_~-][A-Za-z0-9._~-]*$`)
+
+// collectionName matches the collection names SCHEDULE_EVENTS_COLLECTION
+// accepts, which are also valid table and index name parts.
+var collectionName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,99}$`)
+
+// parseClock parses an HH:MM time of day (24:00 included) into its offset
+// from midnight.
+func parseClock(s string) (time.Duration, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	h, err1 := strconv.Atoi(hh)
+	m, err2 := strconv.Atoi(mm)
+	if !ok || err1 != nil || err2 != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
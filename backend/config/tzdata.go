@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tzProbe is a zone with DST rules; it only loads when real tz data is
+// available (UTC always loads, even without any).
+const tzProbe = "Europe/Berlin"
+
+// systemZoneDirs are the directories the Go runtime searches for zoneinfo on
+// unix systems, after $ZONEINFO.
+var systemZoneDirs = []string{
+	"/usr/share/zoneinfo/",
+	"/usr/share/lib/zoneinfo/",
+	"/usr/lib/locale/TZ/",
+	"/etc/zoneinfo/",
+}
+
+// TZDataSource reports where time zone data comes from: "system" when the host
+// has a zoneinfo database, "embedded" when only the copy compiled in through
+// time/tzdata is available.
+func TZDataSource() string {
+	if z := os.Getenv("ZONEINFO"); z != "" {
+		if _, err := os.Stat(z); err == nil {
+			return "system"
+		}
+	}
+	for _, dir := range systemZoneDirs {
+		if _, err := os.Stat(filepath.Join(dir, tzProbe)); err == nil {
+			return "system"
+		}
+	}
+	return "embedded"
+}
+
+// CheckTZData fails when no time zone database can be loaded. Without one every
+// zone but UTC fails to load and timezone-aware recurrences silently fall back
+// to the default zone.
+func CheckTZData() error {
+	if _, err := time.LoadLocation(tzProbe); err != nil {
+		return fmt.Errorf("time zone database unavailable (install tzdata or build with time/tzdata): %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckTZData(t *testing.T) {
+	if err := CheckTZData(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTZDataSource(t *testing.T) {
+	dirs := systemZoneDirs
+	t.Cleanup(func() { systemZoneDirs = dirs })
+
+	// a host zoneinfo directory
+	zoneinfo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(zoneinfo, "Europe"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(zoneinfo, tzProbe), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZONEINFO", "")
+	systemZoneDirs = []string{filepath.Join(t.TempDir(), "missing"), zoneinfo}
+	if got := TZDataSource(); got != "system" {
+		t.Errorf("with a zoneinfo directory: %q, want system", got)
+	}
+
+	// $ZONEINFO pointing at a zip of the database
+	archive := filepath.Join(t.TempDir(), "zoneinfo.zip")
+	if err := os.WriteFile(archive, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZONEINFO", archive)
+	systemZoneDirs = nil
+	if got := TZDataSource(); got != "system" {
+		t.Errorf("with $ZONEINFO: %q, want system", got)
+	}
+
+	// neither: the copy compiled into the binary
+	t.Setenv("ZONEINFO", filepath.Join(t.TempDir(), "missing.zip"))
+	if got := TZDataSource(); got != "embedded" {
+		t.Errorf("without host data: %q, want embedded", got)
+	}
+}
+
+func TestLoadRejectsUnknownTimezone(t *testing.T) {
+	t.Setenv("SCHEDULE_TIMEZONE", "Mars/Olympus_Mons")
+	if _, err := Load(); err == nil {
+		t.Fatal("Load accepted an unknown SCHEDULE_TIMEZONE")
+	}
+	t.Setenv("SCHEDULE_TIMEZONE", "America/Sao_Paulo")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timezone.String() != "America/Sao_Paulo" {
+		t.Fatalf("Timezone = %v", cfg.Timezone)
+	}
+}
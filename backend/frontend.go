@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// rootRelativeURL matches the src and href attributes of index.html holding
+// a root-relative URL.
+var rootRelativeURL = regexp.MustCompile(`(\s(?:src|href)=["'])(/[^"']*)`)
+
+// registerFrontend serves the frontend build under basePath (the root when
+// empty), falling back to index.html for the client-side routes. With a base
+// path the root redirects to it.
+func registerFrontend(se *core.ServeEvent, dist fs.FS, basePath string) error {
+	frontend, err := newFrontendFS(dist, basePath)
+	if err != nil {
+		return err
+	}
+	if basePath != "" {
+		se.Router.GET("/{$}", func(e *core.RequestEvent) error {
+			target := basePath + "/"
+			if q := e.Request.URL.RawQuery; q != "" {
+				target += "?" + q
+			}
+			return e.Redirect(http.StatusFound, target)
+		})
+	}
+	se.Router.GET(basePath+"/{path...}", apis.Static(frontend, true))
+	return nil
+}
+
+// frontendFS is the frontend build with the root-relative URLs of its
+// index.html moved under the base path, so a bundle built for the root loads
+// its scripts and styles under the prefix too.
+type frontendFS struct {
+	fs.FS
+	index     []byte
+	indexInfo fs.FileInfo
+}
+
+func newFrontendFS(dist fs.FS, basePath string) (fs.FS, error) {
+	if basePath == "" {
+		return dist, nil
+	}
+	info, err := fs.Stat(dist, router.IndexPage)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dist, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index, err := fs.ReadFile(dist, router.IndexPage)
+	if err != nil {
+		return nil, err
+	}
+	return &frontendFS{FS: dist, index: prefixURLs(index, basePath), indexInfo: info}, nil
+}
+
+func (f *frontendFS) Open(name string) (fs.File, error) {
+	if name != router.IndexPage {
+		return f.FS.Open(name)
+	}
+	return &memFile{Reader: bytes.NewReader(f.index), info: sizedInfo{f.indexInfo, int64(len(f.index))}}, nil
+}
+
+// prefixURLs puts basePath in front of the root-relative src and href URLs of
+// an HTML page. Protocol-relative URLs and those already under basePath, from
+// a bundle built with that base, are left alone.
+func prefixURLs(page []byte, basePath string) []byte {
+	return rootRelativeURL.ReplaceAllFunc(page, func(m []byte) []byte {
+		sub := rootRelativeURL.FindSubmatch(m)
+		url := string(sub[2])
+		if strings.HasPrefix(url, "//") || url == basePath || strings.HasPrefix(url, basePath+"/") {
+			return m
+		}
+		out := append([]byte{}, sub[1]...)
+		return append(append(out, basePath...), url...)
+	})
+}
+
+// memFile is an in-memory file, seekable as http.ServeContent needs.
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *memFile) Close() error { return nil }
+
+// sizedInfo is the info of a file whose content was rewritten.
+type sizedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i sizedInfo) Size() int64 { return i.size }
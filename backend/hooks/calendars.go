@@ -0,0 +1,33 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultCalendar files owned events saved without a calendar into the owner's
+// default calendar (created on first use). Detached occurrences follow their
+// series.
+func defaultCalendar(e *core.RecordEvent) error {
+	if e.Record.GetString("calendar") != "" {
+		return e.Next()
+	}
+
+	if sourceID := e.Record.GetString("sourceId"); sourceID != "" {
+		if parent, err := e.App.FindRecordById(calendar.EventsCollection, sourceID); err == nil && parent.GetString("calendar") != "" {
+			e.Record.Set("calendar", parent.GetString("calendar"))
+			return e.Next()
+		}
+	}
+
+	if owner := e.Record.GetString("owner"); owner != "" {
+		cal, err := calendar.DefaultCalendar(e.App, owner)
+		if err != nil {
+			return err
+		}
+		e.Record.Set("calendar", cal.Id)
+	}
+
+	return e.Next()
+}
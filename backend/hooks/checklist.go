@@ -0,0 +1,32 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// validateChecklist rejects a checklist that isn't a list of {text, done}
+// items. Valid ones are stored trimmed, with done always present.
+func validateChecklist(e *core.RecordEvent) error {
+	if isUnset(e.Record.Get("checklist")) {
+		return e.Next()
+	}
+
+	var raw []map[string]any
+	if err := e.Record.UnmarshalJSONField("checklist", &raw); err != nil {
+		return checklistError("The checklist must be a list of {text, done} items.")
+	}
+	items, err := calendar.ParseChecklist(raw)
+	if err != nil {
+		return checklistError("Invalid checklist: " + err.Error() + ".")
+	}
+	e.Record.Set("checklist", items)
+
+	return e.Next()
+}
+
+func checklistError(message string) error {
+	return validation.Errors{"checklist": validation.NewError("validation_invalid_checklist", message)}
+}
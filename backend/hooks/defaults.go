@@ -0,0 +1,29 @@
+package hooks
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultFields fills the configured defaults into a new event: the end from
+// the default duration (one day for all-day events), the category and the
+// color. Values given explicitly are kept.
+func (h *eventHooks) defaultFields(e *core.RecordEvent) error {
+	rec := e.Record
+
+	start := rec.GetDateTime("start")
+	if rec.GetDateTime("end").IsZero() && !start.IsZero() {
+		if rec.GetBool("allDay") {
+			rec.Set("end", start.Time().AddDate(0, 0, 1))
+		} else {
+			rec.Set("end", start.Time().Add(h.cfg.DefaultDuration))
+		}
+	}
+	if rec.GetString("category") == "" && h.cfg.DefaultCategory != "" {
+		rec.Set("category", h.cfg.DefaultCategory)
+	}
+	if rec.GetString("color") == "" && h.cfg.DefaultColor != "" {
+		rec.Set("color", h.cfg.DefaultColor)
+	}
+
+	return e.Next()
+}
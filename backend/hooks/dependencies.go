@@ -0,0 +1,71 @@
+package hooks
+
+import (
+	"errors"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// dependencyFields are the events fields a dependsOn link is checked on.
+var dependencyFields = []string{"dependsOn", "start", "end", "owner"}
+
+// checkDependencies keeps an event starting no earlier than its dependency
+// (dependsOn) ends. Moving an event its dependents would then overlap is
+// rejected, or with CascadeDependencies pushes them (and theirs) back in the
+// same transaction.
+func (h *eventHooks) checkDependencies(e *core.RecordEvent) error {
+	rec := e.Record
+	if rec.GetString("dependsOn") != "" && (rec.IsNew() || changesAny(rec, dependencyFields)) {
+		if err := calendar.CheckDependency(e.App, rec); err != nil {
+			return dependencyError(err)
+		}
+	}
+	if rec.IsNew() || !changesAny(rec, []string{"start", "end"}) {
+		return e.Next()
+	}
+
+	end := rec.GetDateTime("end").Time()
+	if !h.cfg.CascadeDependencies {
+		dependents, err := calendar.FindDependents(e.App, rec.Id)
+		if err != nil {
+			return err
+		}
+		for _, d := range dependents {
+			if d.GetDateTime("start").Time().Before(end) {
+				return validation.Errors{"end": validation.NewError("validation_dependent_overlap",
+					"An event depending on this one would start before it ends.")}
+			}
+		}
+		return e.Next()
+	}
+
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		if _, err := calendar.ShiftDependents(txApp, rec.Id, end, calendar.ChangedBy(rec)); err != nil {
+			return err
+		}
+		e.App = txApp
+		return e.Next()
+	})
+}
+
+func dependencyError(err error) error {
+	switch {
+	case errors.Is(err, calendar.ErrStartsTooEarly):
+		return validation.Errors{"start": validation.NewError("validation_starts_before_dependency",
+			"The event must not start before its dependency ends.")}
+	case errors.Is(err, calendar.ErrDependencyCycle):
+		return validation.Errors{"dependsOn": validation.NewError("validation_dependency_cycle",
+			"The dependency would form a cycle.")}
+	case errors.Is(err, calendar.ErrDependencyOwner):
+		return validation.Errors{"dependsOn": validation.NewError("validation_dependency_owner",
+			"The dependency must belong to the same user.")}
+	case errors.Is(err, calendar.ErrDependencyDepth):
+		return validation.Errors{"dependsOn": validation.NewError("validation_dependency_depth",
+			"The dependency chain is too long.")}
+	}
+	return validation.Errors{"dependsOn": validation.NewError("validation_invalid_dependency",
+		"The dependency must be an existing event.")}
+}
@@ -0,0 +1,66 @@
+package hooks
+
+import (
+	"fmt"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// durationFields are the events fields the duration policy depends on.
+var durationFields = []string{"start", "end", "allDay", "multiDay"}
+
+// enforceDuration rejects events shorter than the configured minimum (timed
+// events only) or longer than the maximum (unless flagged multiDay). Updates
+// leaving the times alone pass, so events saved before a stricter policy
+// stay editable.
+func (h *eventHooks) enforceDuration(e *core.RecordEvent) error {
+	minimum, maximum := h.cfg.MinEventDuration, h.cfg.MaxEventDuration
+	if minimum == 0 && maximum == 0 {
+		return e.Next()
+	}
+	if !e.Record.IsNew() && !changesAny(e.Record, durationFields) {
+		return e.Next()
+	}
+
+	ev := calendar.EventFromRecord(e.Record)
+	d := ev.End.Sub(ev.Start)
+	switch {
+	case minimum > 0 && !ev.AllDay && d < minimum:
+		return durationError("validation_event_too_short", "Timed events must last at least "+formatPolicy(minimum)+".")
+	case maximum > 0 && !e.Record.GetBool("multiDay") && d > maximum:
+		return durationError("validation_event_too_long", "Events can last at most "+formatPolicy(maximum)+" unless flagged multiDay.")
+	}
+
+	return e.Next()
+}
+
+// changesAny reports whether an update changes one of fields.
+func changesAny(rec *core.Record, fields []string) bool {
+	changes := calendar.Diff(rec)
+	for _, name := range fields {
+		if _, ok := changes[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatPolicy spells a policy duration in its configured unit.
+func formatPolicy(d time.Duration) string {
+	n, unit := int64(d/time.Minute), "minute"
+	if d%time.Hour == 0 {
+		n, unit = int64(d/time.Hour), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
+
+func durationError(code, message string) error {
+	return validation.Errors{"end": validation.NewError(code, message)}
+}
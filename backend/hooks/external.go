@@ -0,0 +1,338 @@
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// externalFetchTimeout bounds one fetch of an external feed.
+	externalFetchTimeout = 30 * time.Second
+
+	// maxExternalBytes caps the size of an external feed.
+	maxExternalBytes = 10 << 20
+
+	// maxExternalRedirects caps the redirects followed per fetch.
+	maxExternalRedirects = 5
+)
+
+// errNotPublic rejects feed addresses outside the public internet, so a
+// subscription can't make the server reach its own network.
+var errNotPublic = errors.New("the feed address is not public")
+
+// feedError is a sync failure described well enough to show the subscriber.
+// Other errors, which may tell about the server's network, are only logged.
+type feedError struct{ msg string }
+
+func (e *feedError) Error() string { return e.msg }
+
+func feedErrorf(format string, args ...any) error {
+	return &feedError{fmt.Sprintf(format, args...)}
+}
+
+// publicError is the lastError text of a failed sync.
+func publicError(err error) string {
+	var fe *feedError
+	switch {
+	case errors.As(err, &fe):
+		return fe.msg
+	case errors.Is(err, errNotPublic):
+		return errNotPublic.Error()
+	}
+	return "the feed could not be fetched"
+}
+
+// newExternalClient is the HTTP client of the feed fetches: it only connects
+// to public addresses, checked after DNS resolution so a name can't point it
+// elsewhere, bypasses proxies and follows at most maxExternalRedirects
+// redirects, to http and https URLs.
+func newExternalClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   externalFetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxExternalRedirects {
+				return feedErrorf("the feed redirects more than %d times", maxExternalRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return feedErrorf("the feed redirects to an unsupported URL scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// dialPublicOnly is a net.Dialer Control rejecting the connections to
+// loopback, private, link-local, multicast and unspecified addresses.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip.Unmap()) {
+		return errNotPublic
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598).
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func isPublic(ip netip.Addr) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// errReadOnlyCalendar rejects changes to the events of external calendars.
+var errReadOnlyCalendar = validation.Errors{"calendar": validation.NewError("validation_external_calendar",
+	"The events of external calendars are read-only.")}
+
+// externalSyncer fetches the due external feeds every minute, one feed at a
+// time, and mirrors them into their calendars.
+type externalSyncer struct {
+	app    core.App
+	client *http.Client
+
+	run sync.Mutex // a slow round makes the next ticks skip
+}
+
+// registerExternalCalendars creates and deletes the mirror calendar along with
+// each subscription, keeps the mirrored events read-only and schedules the
+// feed fetches.
+func registerExternalCalendars(app core.App) {
+	app.OnRecordCreate(calendar.ExternalCalendarsCollection).BindFunc(createMirrorCalendar)
+	app.OnRecordUpdate(calendar.ExternalCalendarsCollection).BindFunc(resetExternalState)
+	app.OnRecordDelete(calendar.ExternalCalendarsCollection).BindFunc(deleteMirrorCalendar)
+
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(protectExternalEvents)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(protectExternalEvents)
+	app.OnRecordDeleteRequest(calendar.EventsCollection).BindFunc(func(e *core.RecordRequestEvent) error {
+		if calendar.IsExternalCalendar(e.App, e.Record.GetString("calendar")) {
+			return e.BadRequestError("Failed to delete record.", errReadOnlyCalendar)
+		}
+		return e.Next()
+	})
+
+	s := &externalSyncer{app: app, client: newExternalClient()}
+	app.OnRecordAfterCreateSuccess(calendar.ExternalCalendarsCollection).BindFunc(s.syncSoon)
+	app.OnRecordAfterUpdateSuccess(calendar.ExternalCalendarsCollection).BindFunc(s.syncSoon)
+	app.Cron().MustAdd("scheduleExternalCalendars", "* * * * *", s.tick)
+}
+
+// createMirrorCalendar creates the calendar a new subscription is mirrored
+// into, named after the subscription or else the feed host. A restored
+// subscription (see calendar.MirrorKey) keeps the calendar it comes with.
+func createMirrorCalendar(e *core.RecordEvent) error {
+	sub := e.Record
+	if sub.GetBool(calendar.MirrorKey) && calendar.IsExternalCalendar(e.App, sub.GetString("calendar")) {
+		return e.Next()
+	}
+	name := sub.GetString("name")
+	if name == "" {
+		if u, err := url.Parse(sub.GetString("url")); err == nil {
+			name = u.Hostname()
+		}
+	}
+
+	collection, err := e.App.FindCollectionByNameOrId(calendar.CalendarsCollection)
+	if err != nil {
+		return err
+	}
+	cal := core.NewRecord(collection)
+	cal.Set("name", name)
+	cal.Set("owner", sub.GetString("owner"))
+	cal.Set("external", true)
+	if err := e.App.Save(cal); err != nil {
+		return validation.Errors{"name": validation.NewError("validation_calendar_name",
+			"Failed to create the calendar "+name+": "+err.Error())}
+	}
+	sub.Set("calendar", cal.Id)
+	return e.Next()
+}
+
+// resetExternalState forgets the HTTP validators and the last sync of a
+// subscription whose URL changed, so the new feed is fetched right away.
+func resetExternalState(e *core.RecordEvent) error {
+	if changesAny(e.Record, []string{"url"}) {
+		for _, name := range []string{"etag", "lastModified", "lastSynced", "lastError"} {
+			e.Record.Set(name, "")
+		}
+	}
+	return e.Next()
+}
+
+// deleteMirrorCalendar deletes the calendar of a deleted subscription, and
+// with it the mirrored events.
+func deleteMirrorCalendar(e *core.RecordEvent) error {
+	if err := e.Next(); err != nil {
+		return err
+	}
+	cal, err := e.App.FindRecordById(calendar.CalendarsCollection, e.Record.GetString("calendar"))
+	if err != nil {
+		return nil // already deleted, e.g. the deletion cascades from it
+	}
+	return e.App.Delete(cal)
+}
+
+// protectExternalEvents rejects saving an event into, or out of, an external
+// calendar anywhere but in the mirror.
+func protectExternalEvents(e *core.RecordEvent) error {
+	rec := e.Record
+	if rec.GetBool(calendar.MirrorKey) {
+		return e.Next()
+	}
+	if calendar.IsExternalCalendar(e.App, rec.GetString("calendar")) ||
+		(!rec.IsNew() && changesAny(rec, []string{"calendar"}) && calendar.IsExternalCalendar(e.App, rec.Original().GetString("calendar"))) {
+		return errReadOnlyCalendar
+	}
+	return e.Next()
+}
+
+// syncSoon fetches a new subscription, or one whose URL changed (see
+// resetExternalState), in the background rather than on the next tick.
+func (s *externalSyncer) syncSoon(e *core.RecordEvent) error {
+	if e.Record.GetDateTime("lastSynced").IsZero() {
+		id := e.Record.Id
+		go func() {
+			s.run.Lock()
+			defer s.run.Unlock()
+			if sub, err := s.app.FindRecordById(calendar.ExternalCalendarsCollection, id); err == nil {
+				s.sync(sub)
+			}
+		}()
+	}
+	return e.Next()
+}
+
+func (s *externalSyncer) tick() {
+	if !s.run.TryLock() {
+		return
+	}
+	defer s.run.Unlock()
+
+	subs, err := s.app.FindAllRecords(calendar.ExternalCalendarsCollection)
+	if err != nil {
+		s.app.Logger().Error("external calendars lookup failed", "error", err)
+		return
+	}
+	now := time.Now()
+	for _, sub := range subs {
+		last := sub.GetDateTime("lastSynced").Time()
+		if !last.IsZero() && now.Before(last.Add(calendar.ExternalRefresh(sub))) {
+			continue
+		}
+		s.sync(sub)
+	}
+}
+
+// sync fetches the feed of sub, conditionally on the validators of the last
+// fetch, mirrors it when it changed and records the outcome on sub (see
+// publicError). A failed fetch is retried after the usual refresh interval,
+// not on the next tick.
+func (s *externalSyncer) sync(sub *core.Record) {
+	result, err := s.fetch(sub)
+	sub.Set("lastSynced", types.NowDateTime())
+	sub.Set("lastError", "")
+	if err != nil {
+		sub.Set("lastError", truncate(publicError(err), 1000))
+		s.app.Logger().Warn("external calendar sync failed", "subscription", sub.Id, "error", err)
+	} else if result != nil {
+		s.app.Logger().Info("external calendar synced", "subscription", sub.Id,
+			"created", result.Created, "updated", result.Updated, "removed", result.Removed, "errors", len(result.Errors))
+	}
+	if err := s.app.Save(sub); err != nil {
+		s.app.Logger().Error("external calendar state not saved", "subscription", sub.Id, "error", err)
+	}
+}
+
+// fetch downloads and mirrors the feed of sub, returning nil without error
+// when it is unchanged (304).
+func (s *externalSyncer) fetch(sub *core.Record) (*calendar.MirrorResult, error) {
+	cal, err := s.app.FindRecordById(calendar.CalendarsCollection, sub.GetString("calendar"))
+	if err != nil {
+		return nil, feedErrorf("the mirror calendar is missing")
+	}
+
+	u, err := url.Parse(sub.GetString("url"))
+	if err != nil {
+		return nil, feedErrorf("the feed URL is invalid")
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, feedErrorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+	if v := sub.GetString("etag"); v != "" {
+		req.Header.Set("If-None-Match", v)
+	}
+	if v := sub.GetString("lastModified"); v != "" {
+		req.Header.Set("If-Modified-Since", v)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return nil, nil
+	case res.StatusCode != http.StatusOK:
+		return nil, feedErrorf("the feed answered %s", res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxExternalBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxExternalBytes {
+		return nil, feedErrorf("the feed is larger than %d MB", maxExternalBytes>>20)
+	}
+
+	result, err := calendar.MirrorICS(s.app, cal, bytes.NewReader(body))
+	if err != nil {
+		return nil, feedErrorf("the feed is not a valid calendar: %v", err)
+	}
+	sub.Set("etag", res.Header.Get("ETag"))
+	sub.Set("lastModified", res.Header.Get("Last-Modified"))
+	return result, nil
+}
+
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+package hooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestExternalClientRejectsLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	}))
+	defer srv.Close()
+
+	_, err := newExternalClient().Get(srv.URL)
+	if !errors.Is(err, errNotPublic) {
+		t.Fatalf("expected errNotPublic, got %v", err)
+	}
+	if got := publicError(err); got != errNotPublic.Error() {
+		t.Fatalf("publicError = %q", got)
+	}
+}
+
+func TestPublicErrorHidesNetworkDetails(t *testing.T) {
+	err := errors.New("dial tcp 10.0.0.5:5432: connect: connection refused")
+	if got := publicError(err); got != "the feed could not be fetched" {
+		t.Fatalf("publicError = %q", got)
+	}
+	if got := publicError(feedErrorf("the feed answered %s", "404 Not Found")); got != "the feed answered 404 Not Found" {
+		t.Fatalf("publicError = %q", got)
+	}
+}
+
+// externalFixture is an app with a user owning an external calendar with one
+// mirrored College event, plus an event of their own, and a second user.
+type externalFixture struct {
+	app           *tests.TestApp
+	owner, other  *core.Record
+	mirrored, own *core.Record
+}
+
+func newExternalFixture(t *testing.T) *externalFixture {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app.Cleanup)
+	Register(app, cfg)
+
+	save := func(collection string, fields map[string]any) *core.Record {
+		t.Helper()
+		c, err := app.FindCollectionByNameOrId(collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := core.NewRecord(c)
+		rec.Load(fields)
+		if c.IsAuth() {
+			rec.SetPassword("password123")
+		}
+		if err := app.Save(rec); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	f := &externalFixture{app: app}
+	f.owner = save(calendar.UsersCollection, map[string]any{"email": "owner@example.com"})
+	f.other = save(calendar.UsersCollection, map[string]any{"email": "other@example.com"})
+	cal := save(calendar.CalendarsCollection, map[string]any{"name": "Holidays", "owner": f.owner.Id, "external": true})
+	f.mirrored = save(calendar.EventsCollection, map[string]any{
+		"title": "Term starts", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 10:00:00.000Z",
+		"category": "College", "owner": f.owner.Id, "calendar": cal.Id, calendar.MirrorKey: true,
+	})
+	f.own = save(calendar.EventsCollection, map[string]any{
+		"title": "Lecture", "start": "2026-03-11 09:00:00.000Z", "end": "2026-03-11 10:00:00.000Z",
+		"category": "College", "owner": f.owner.Id,
+	})
+	return f
+}
+
+func TestExternalEventsStayReadOnly(t *testing.T) {
+	f := newExternalFixture(t)
+	mirrored, err := f.app.FindRecordById(calendar.EventsCollection, f.mirrored.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mirrored.Set("title", "Edited")
+	if err := f.app.Save(mirrored); err == nil {
+		t.Fatal("an ordinary save of a mirrored event should be refused")
+	}
+	f.own.Set("calendar", f.mirrored.GetString("calendar"))
+	if err := f.app.Save(f.own); err == nil {
+		t.Fatal("moving an event into an external calendar should be refused")
+	}
+}
+
+func TestRestoreExternalEvents(t *testing.T) {
+	f := newExternalFixture(t)
+	b, err := calendar.NewBackup(f.app, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := calendar.Restore(f.app, b, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Failed) > 0 {
+		t.Fatalf("restore failed: %+v", res.Failed)
+	}
+}
+
+func TestTransferLeavesExternalEvents(t *testing.T) {
+	f := newExternalFixture(t)
+	events, err := f.app.FindAllRecords(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := calendar.TransferEvents(f.app, events, f.other, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 1 {
+		t.Fatalf("transferred %d events, want 1", moved)
+	}
+	for rec, owner := range map[*core.Record]string{f.mirrored: f.owner.Id, f.own: f.other.Id} {
+		got, err := f.app.FindRecordById(calendar.EventsCollection, rec.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetString("owner") != owner {
+			t.Errorf("%s owned by %s, want %s", got.GetString("title"), got.GetString("owner"), owner)
+		}
+	}
+}
+
+func TestMergeCategoryOfExternalEvents(t *testing.T) {
+	f := newExternalFixture(t)
+	source, err := f.app.FindFirstRecordByData(calendar.CategoriesCollection, "name", "College")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := f.app.FindFirstRecordByData(calendar.CategoriesCollection, "name", "Other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := calendar.MergeCategory(f.app, source, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 2 {
+		t.Fatalf("merged %d events, want 2", moved)
+	}
+	got, err := f.app.FindRecordById(calendar.EventsCollection, f.mirrored.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetString("category") != "Other" {
+		t.Fatalf("mirrored event category %q, want Other", got.GetString("category"))
+	}
+}
@@ -0,0 +1,78 @@
+package hooks
+
+import (
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// registerHistoryRetention schedules the nightly trim of the change log to
+// cfg.HistoryRetention. A zero retention keeps every entry.
+func registerHistoryRetention(app core.App, cfg *config.Config) {
+	if cfg.HistoryRetention == 0 {
+		return
+	}
+	app.Cron().MustAdd("scheduleHistoryRetention", "30 3 * * *", func() {
+		n, err := calendar.TrimHistory(app, time.Now().Add(-cfg.HistoryRetention), cfg.HistoryKeep)
+		if err != nil {
+			app.Logger().Error("history retention failed", "error", err)
+			return
+		}
+		if n > 0 {
+			app.Logger().Info("history trimmed", "deleted", n)
+		}
+	})
+}
+
+// markChangedBy names the authenticated record saving an event through the
+// records API as the actor of its change log entry.
+func markChangedBy(e *core.RecordRequestEvent) error {
+	e.Record.Set(calendar.ChangedByKey, nil)
+	calendar.SetChangedBy(e.Record, e.Auth)
+	return e.Next()
+}
+
+// logCreate adds the "create" entry of a new event, without field changes.
+func logCreate(e *core.RecordEvent) error {
+	if err := e.Next(); err != nil {
+		return err
+	}
+	return logChange(e.App, e.Record, "create", nil)
+}
+
+// logUpdate records which fields an event update changed, as a "transfer" for
+// saves of calendar.TransferEvents. Saves that change nothing worth logging
+// leave no entry.
+func logUpdate(e *core.RecordEvent) error {
+	changes := calendar.Diff(e.Record)
+	if err := e.Next(); err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if e.Record.GetBool(calendar.TransferKey) {
+		return logChange(e.App, e.Record, "transfer", changes)
+	}
+	return logChange(e.App, e.Record, "update", changes)
+}
+
+func logChange(app core.App, event *core.Record, action string, changes map[string]calendar.FieldChange) error {
+	collection, err := app.FindCollectionByNameOrId(calendar.EventChangesCollection)
+	if err != nil {
+		return err
+	}
+
+	rec := core.NewRecord(collection)
+	rec.Set("event", event.Id)
+	rec.Set("action", action)
+	rec.Set("changes", changes)
+	if actor := calendar.ChangedBy(event); actor != nil {
+		rec.Set("actor", actor.Id)
+		rec.Set("actorCollection", actor.Collection().Name)
+	}
+	return app.Save(rec)
+}
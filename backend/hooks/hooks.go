@@ -0,0 +1,65 @@
+// Package hooks binds the schedule record hooks (defaults and validation) to
+// the PocketBase app.
+package hooks
+
+import (
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// eventHooks carries the configuration the events hooks depend on.
+type eventHooks struct {
+	cfg *config.Config
+}
+
+// Register binds the events collection hooks, schedules the change log
+// retention and the external calendar fetches, and keeps the materialized
+// occurrences up to date.
+func Register(app core.App, cfg *config.Config) {
+	h := &eventHooks{cfg: cfg}
+
+	app.OnRecordCreateRequest(calendar.EventsCollection).BindFunc(defaultOwner)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(normalizeReminderMinutes)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(normalizeReminderMinutes)
+	app.OnRecordCreate(calendar.CategoriesCollection).BindFunc(normalizeReminderMinutes)
+	app.OnRecordUpdate(calendar.CategoriesCollection).BindFunc(normalizeReminderMinutes)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(normalizeEvent)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(normalizeEvent)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(h.defaultFields)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(inheritCategoryReminders)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(defaultCalendar)
+
+	app.OnRecordCreateRequest(calendar.EventsCollection).BindFunc(markAllowOverlap)
+	app.OnRecordUpdateRequest(calendar.EventsCollection).BindFunc(markAllowOverlap)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(h.preventResourceOverlap)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(h.preventResourceOverlap)
+
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(h.checkDependencies)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(h.checkDependencies)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(h.validateRRule)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(h.validateRRule)
+	app.OnRecordEnrich(calendar.EventsCollection).BindFunc(addSaveWarnings)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(h.enforceDuration)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(h.enforceDuration)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(validatePauses)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(validatePauses)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(validateChecklist)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(validateChecklist)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(validateReminderChannels)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(validateReminderChannels)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(bumpSequence)
+
+	app.OnRecordCreateRequest(calendar.EventsCollection).BindFunc(markChangedBy)
+	app.OnRecordUpdateRequest(calendar.EventsCollection).BindFunc(markChangedBy)
+	app.OnRecordCreate(calendar.EventsCollection).BindFunc(logCreate)
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(logUpdate)
+	app.OnRecordCreate(calendar.UsersCollection).BindFunc(validateUserTimezone)
+	app.OnRecordUpdate(calendar.UsersCollection).BindFunc(validateUserTimezone)
+	app.OnRecordAfterDeleteSuccess(calendar.EventsCollection).BindFunc(recordEventDeletion)
+
+	registerHistoryRetention(app, cfg)
+	registerExternalCalendars(app)
+	registerMaterializer(app, cfg)
+}
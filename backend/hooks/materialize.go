@@ -0,0 +1,156 @@
+package hooks
+
+import (
+	"sync"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	// materializeQuiet is how long the events must stay unchanged before the
+	// pending rebuild runs, so a bulk edit triggers one rebuild instead of one
+	// per record.
+	materializeQuiet = 2 * time.Second
+
+	// materializeMaxWait bounds how long a steady stream of changes can keep
+	// postponing the rebuild.
+	materializeMaxWait = 30 * time.Second
+
+	// materializeSpanKey is a custom (non-persisted) record key holding the
+	// span of an event before an update, so the rows it leaves are rebuilt too.
+	materializeSpanKey = "@materializeSpan"
+)
+
+// materializeFields are the events fields the materialized rows depend on.
+var materializeFields = []string{"start", "end", "allDay", "rrule", "exdates", "pauses", "timezone", "floating"}
+
+// materializer coalesces the windows touched by event changes and rebuilds
+// their union once the changes have settled.
+type materializer struct {
+	app core.App
+	cfg *config.Config
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	pending  bool
+	since    time.Time // first change of the pending window
+	from, to time.Time // to is zero for open-ended windows
+
+	run sync.Mutex // one rebuild at a time
+}
+
+// registerMaterializer keeps the materialized occurrences in sync with event
+// changes. Hooks run after the commit, so the rebuild always reads the saved
+// state, and a window still pending on shutdown is rebuilt before exiting.
+func registerMaterializer(app core.App, cfg *config.Config) {
+	m := &materializer{app: app, cfg: cfg}
+
+	app.OnRecordUpdate(calendar.EventsCollection).BindFunc(rememberMaterializeSpan)
+	app.OnRecordAfterCreateSuccess(calendar.EventsCollection).BindFunc(m.onCreate)
+	app.OnRecordAfterUpdateSuccess(calendar.EventsCollection).BindFunc(m.onUpdate)
+	app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+		m.flush()
+		return e.Next()
+	})
+}
+
+// rememberMaterializeSpan stores the span of an event before an update that
+// can move its occurrences. Other updates leave the materialized rows alone.
+func rememberMaterializeSpan(e *core.RecordEvent) error {
+	e.Record.Set(materializeSpanKey, nil)
+	if changesAny(e.Record, materializeFields) {
+		from, to := calendar.EventFromRecord(e.Record.Original()).MaterializeSpan()
+		e.Record.Set(materializeSpanKey, [2]time.Time{from, to})
+	}
+	return e.Next()
+}
+
+// onCreate queues the span of a new event. Deleted events need no rebuild,
+// their rows cascade.
+func (m *materializer) onCreate(e *core.RecordEvent) error {
+	m.mark(calendar.EventFromRecord(e.Record).MaterializeSpan())
+	return e.Next()
+}
+
+// onUpdate queues the old and new spans of an event whose occurrences may
+// have moved.
+func (m *materializer) onUpdate(e *core.RecordEvent) error {
+	if old, ok := e.Record.Get(materializeSpanKey).([2]time.Time); ok {
+		m.mark(old[0], old[1])
+		m.mark(calendar.EventFromRecord(e.Record).MaterializeSpan())
+	}
+	return e.Next()
+}
+
+// mark widens the pending window to [from, to) and (re)starts the quiet
+// period, unless the window has been pending for materializeMaxWait already.
+func (m *materializer) mark(from, to time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.pending {
+		m.pending, m.since, m.from, m.to = true, time.Now(), from, to
+	} else {
+		if from.Before(m.from) {
+			m.from = from
+		}
+		if !m.to.IsZero() && (to.IsZero() || to.After(m.to)) {
+			m.to = to
+		}
+	}
+
+	switch {
+	case m.timer == nil:
+		m.timer = time.AfterFunc(materializeQuiet, m.flush)
+	case time.Since(m.since) < materializeMaxWait:
+		m.timer.Reset(materializeQuiet)
+	}
+}
+
+// flush rebuilds the pending window, up to the expansion horizon. Nothing is
+// rebuilt while no occurrences are materialized at all. Changes made while it
+// runs queue a new window, and a failed rebuild is queued again.
+func (m *materializer) flush() {
+	m.run.Lock()
+	defer m.run.Unlock()
+
+	m.mu.Lock()
+	if !m.pending {
+		m.mu.Unlock()
+		return
+	}
+	from, to := m.from, m.to
+	m.pending = false
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	m.mu.Unlock()
+
+	used, err := calendar.HasMaterialized(m.app)
+	if err != nil || !used {
+		if err != nil {
+			m.app.Logger().Error("rematerialize failed", "error", err)
+		}
+		return
+	}
+	if horizon := time.Now().Add(m.cfg.Horizon); to.IsZero() || to.After(horizon) {
+		to = horizon
+	}
+	if !from.Before(to) {
+		return
+	}
+
+	result, err := calendar.Rematerialize(m.app, from, to)
+	if err != nil {
+		// retry after the next quiet period rather than losing the window
+		m.app.Logger().Error("rematerialize failed", "from", from, "to", to, "error", err)
+		m.mark(from, to)
+		return
+	}
+	m.app.Logger().Debug("rematerialized changed events", "from", from, "to", to, "events", result.Events, "created", result.Created, "durationMs", result.Duration.Milliseconds())
+}
@@ -0,0 +1,26 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// normalizeEvent brings the title, color, tags and exdates of a saved event
+// into their canonical form (see calendar.Normalize). Updates only touch the
+// fields they change, older records are left to the normalize maintenance
+// route.
+func normalizeEvent(e *core.RecordEvent) error {
+	fields := calendar.NormalizedFields
+	if !e.Record.IsNew() {
+		changes := calendar.Diff(e.Record)
+		fields = nil
+		for _, name := range calendar.NormalizedFields {
+			if _, ok := changes[name]; ok {
+				fields = append(fields, name)
+			}
+		}
+	}
+	calendar.Normalize(e.Record, fields)
+	return e.Next()
+}
@@ -0,0 +1,16 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// defaultOwner makes the authenticated app user the owner of the events they
+// create without an explicit owner.
+func defaultOwner(e *core.RecordRequestEvent) error {
+	if e.Record.GetString("owner") == "" && e.Auth != nil && e.Auth.Collection().Name == calendar.UsersCollection {
+		e.Record.Set("owner", e.Auth.Id)
+	}
+	return e.Next()
+}
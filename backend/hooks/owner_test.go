@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+	"schedule/config"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestDefaultOwner(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer seed.Cleanup()
+	users, err := seed.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	var ids []string
+	for _, email := range []string{"owner@example.com", "other@example.com"} {
+		u := core.NewRecord(users)
+		u.SetEmail(email)
+		u.SetPassword("password123")
+		if err := seed.Save(u); err != nil {
+			t.Fatal(err)
+		}
+		token, err := u.NewAuthToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens, ids = append(tokens, token), append(ids, u.Id)
+	}
+	factory := func(t testing.TB) *tests.TestApp {
+		app, err := tests.NewTestApp(seed.DataDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		Register(app, cfg)
+		return app
+	}
+	url := "/api/collections/" + calendar.EventsCollection + "/records"
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "app user becomes the owner",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"title":"Lunch","start":"2026-03-10 12:00:00.000Z","end":"2026-03-10 13:00:00.000Z"}`),
+			Headers:         map[string]string{"Authorization": tokens[0]},
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"owner":"` + ids[0] + `"`},
+		},
+		{
+			Name:            "app user can't create for someone else",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"title":"Lunch","owner":"` + ids[1] + `","start":"2026-03-10 12:00:00.000Z","end":"2026-03-10 13:00:00.000Z"}`),
+			Headers:         map[string]string{"Authorization": tokens[0]},
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "guest",
+			Method:          http.MethodPost,
+			URL:             url,
+			Body:            strings.NewReader(`{"title":"Lunch","start":"2026-03-10 12:00:00.000Z","end":"2026-03-10 13:00:00.000Z"}`),
+			ExpectedStatus:  http.StatusBadRequest,
+			ExpectedContent: []string{`"data":{}`},
+		},
+	}
+	for _, s := range scenarios {
+		s.TestAppFactory = factory
+		s.Test(t)
+	}
+}
@@ -0,0 +1,39 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// validatePauses rejects malformed or overlapping pauses, and pauses of
+// events that don't recur. Valid ones are stored sorted, in the ISO form of
+// the exdates.
+func validatePauses(e *core.RecordEvent) error {
+	var raw []map[string]any
+	if err := e.Record.UnmarshalJSONField("pauses", &raw); err != nil {
+		return pausesError("validation_invalid_pauses", "Pauses must be a list of {from, to} ranges.")
+	}
+	if len(raw) == 0 {
+		return e.Next()
+	}
+
+	ev := calendar.EventFromRecord(e.Record)
+	switch {
+	case len(ev.Pauses) != len(raw):
+		return pausesError("validation_invalid_pauses", "Every pause needs a valid from and to date.")
+	case !ev.IsRecurring():
+		return pausesError("validation_not_recurring", "Only recurring events can be paused.")
+	}
+	if err := calendar.ValidatePauses(ev.Pauses); err != nil {
+		return pausesError("validation_invalid_pauses", "Invalid pauses: "+err.Error()+".")
+	}
+	e.Record.Set("pauses", calendar.StoredPauses(ev.Pauses))
+
+	return e.Next()
+}
+
+func pausesError(code, message string) error {
+	return validation.Errors{"pauses": validation.NewError(code, message)}
+}
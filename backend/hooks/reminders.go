@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// inheritCategoryReminders copies the category's default reminderMinutes onto
+// a new event that doesn't specify its own.
+//
+// Only an unset (or null) value inherits: an explicit empty array means
+// "no reminders" and is kept as is.
+func inheritCategoryReminders(e *core.RecordEvent) error {
+	if !isUnset(e.Record.Get("reminderMinutes")) {
+		return e.Next()
+	}
+
+	name := e.Record.GetString("category")
+	if name == "" {
+		return e.Next()
+	}
+
+	category, err := e.App.FindFirstRecordByData(calendar.CategoriesCollection, "name", name)
+	if err != nil {
+		return e.Next() // unknown category, nothing to inherit
+	}
+
+	var defaults []int
+	if err := category.UnmarshalJSONField("reminderMinutes", &defaults); err == nil && len(defaults) > 0 {
+		e.Record.Set("reminderMinutes", defaults)
+	}
+
+	return e.Next()
+}
+
+// isUnset reports whether a JSON field value was never provided.
+func isUnset(v any) bool {
+	switch raw := v.(type) {
+	case nil:
+		return true
+	case types.JSONRaw:
+		return len(raw) == 0 || string(raw) == "null"
+	}
+	return false
+}
+
+// normalizeReminderMinutes stores the reminderMinutes of events and
+// categories as integer minutes, converting ISO 8601 durations ("PT15M",
+// "P1D") and numeric strings (see calendar.ParseReminder).
+func normalizeReminderMinutes(e *core.RecordEvent) error {
+	if isUnset(e.Record.Get("reminderMinutes")) {
+		return e.Next()
+	}
+
+	var raw []any
+	if err := e.Record.UnmarshalJSONField("reminderMinutes", &raw); err != nil {
+		return reminderMinutesError("Must be a list of minutes or ISO 8601 durations.")
+	}
+	minutes := make([]int, len(raw))
+	for i, v := range raw {
+		n, err := calendar.ParseReminder(v)
+		if err != nil {
+			return reminderMinutesError("Invalid reminder: " + err.Error() + ".")
+		}
+		minutes[i] = n
+	}
+	e.Record.Set("reminderMinutes", minutes)
+
+	return e.Next()
+}
+
+func reminderMinutesError(message string) error {
+	return validation.Errors{"reminderMinutes": validation.NewError("validation_invalid_reminder", message)}
+}
+
+// validateReminderChannels rejects picking the webhook channel for an event
+// whose owner has no reminderWebhook to post to. The select field already
+// refuses unknown channel names.
+func validateReminderChannels(e *core.RecordEvent) error {
+	rec := e.Record
+	if !rec.IsNew() && !changesAny(rec, []string{"reminderChannels", "owner"}) {
+		return e.Next()
+	}
+	ev := calendar.EventFromRecord(rec)
+	if !ev.HasChannel(calendar.ChannelWebhook) || ev.Owner == "" {
+		return e.Next()
+	}
+	owner, err := e.App.FindRecordById(calendar.UsersCollection, ev.Owner)
+	if err != nil || owner.GetString("reminderWebhook") == "" {
+		return validation.Errors{"reminderChannels": validation.NewError("validation_missing_webhook",
+			"Set a reminderWebhook on the owner before picking the webhook channel.")}
+	}
+	return e.Next()
+}
@@ -0,0 +1,114 @@
+package hooks
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+	"schedule/config"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestInheritCategoryReminders(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	Register(app, cfg)
+
+	category, err := app.FindFirstRecordByData(calendar.CategoriesCollection, "name", "Personal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	category.Set("reminderMinutes", []int{1440, 60})
+	if err := app.Save(category); err != nil {
+		t.Fatal(err)
+	}
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		fields map[string]any
+		want   []int
+	}{
+		{"unset inherits", map[string]any{"category": "Personal"}, []int{60, 1440}},
+		{"null inherits", map[string]any{"category": "Personal", "reminderMinutes": nil}, []int{60, 1440}},
+		{"explicitly empty keeps no reminders", map[string]any{"category": "Personal", "reminderMinutes": []int{}}, nil},
+		{"explicit reminders win", map[string]any{"category": "Personal", "reminderMinutes": []int{5}}, []int{5}},
+		{"category without defaults", map[string]any{"category": "Other"}, nil},
+		{"no category", map[string]any{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := core.NewRecord(events)
+			rec.Load(map[string]any{"title": "Ada", "start": "2026-03-14 00:00:00.000Z", "end": "2026-03-15 00:00:00.000Z", "allDay": true})
+			rec.Load(tt.fields)
+			if err := app.Save(rec); err != nil {
+				t.Fatal(err)
+			}
+			saved, err := app.FindRecordById(calendar.EventsCollection, rec.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := calendar.EventFromRecord(saved).ReminderMinutes
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("reminderMinutes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeReminderMinutes(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	Register(app, cfg)
+
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := core.NewRecord(events)
+	rec.Load(map[string]any{
+		"title": "Dentist", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 10:00:00.000Z",
+		"reminderMinutes": []any{"PT15M", 30, "P1D", "-PT1H30M", "45"},
+	})
+	if err := app.Save(rec); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := app.FindRecordById(calendar.EventsCollection, rec.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := calendar.EventFromRecord(saved).ReminderMinutes, []int{15, 30, 1440, 90, 45}; !slices.Equal(got, want) {
+		t.Fatalf("reminderMinutes = %v, want %v", got, want)
+	}
+
+	bad := core.NewRecord(events)
+	bad.Load(map[string]any{
+		"title": "Dentist", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 10:00:00.000Z",
+		"reminderMinutes": []any{"PT15M", "PT30S"},
+	})
+	if err := app.Save(bad); err == nil || !strings.Contains(err.Error(), "reminderMinutes") {
+		t.Fatalf("saving a reminder of 30 seconds: %v, want a reminderMinutes error", err)
+	}
+}
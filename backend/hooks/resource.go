@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"fmt"
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// allowOverlapKey is a custom (non-persisted) record key set by the request
+// hooks when a superuser asks to bypass the resource double-booking check.
+const allowOverlapKey = "@allowOverlap"
+
+// markAllowOverlap honours ?allowOverlap=true for superusers only. It always
+// overwrites the key so regular clients can't smuggle it in.
+func markAllowOverlap(e *core.RecordRequestEvent) error {
+	allowed := e.HasSuperuserAuth() && e.Request.URL.Query().Get("allowOverlap") == "true"
+	e.Record.Set(allowOverlapKey, allowed)
+	return e.Next()
+}
+
+// preventResourceOverlap rejects saving an event whose occurrences overlap
+// another event booking the same resource. Recurrences on both sides are
+// expanded from the event start up to the configured horizon, in the zone of
+// the event owner (the configured one for unowned events).
+func (h *eventHooks) preventResourceOverlap(e *core.RecordEvent) error {
+	if e.Record.GetString("resource") == "" || e.Record.GetBool(allowOverlapKey) {
+		return e.Next()
+	}
+
+	ev := calendar.EventFromRecord(e.Record)
+	from := ev.Start
+	to := ev.End
+	if ev.IsRecurring() {
+		to = ev.Start.Add(h.cfg.Horizon)
+	}
+	if !to.After(from) {
+		to = from.Add(time.Second)
+	}
+
+	owner, _ := e.App.FindRecordById(calendar.UsersCollection, ev.Owner)
+	loc := calendar.UserZone(owner, h.cfg.Timezone)
+	conflict, err := calendar.FindResourceConflict(e.App, ev, from, to, loc)
+	if err != nil {
+		return err
+	}
+	if conflict != nil {
+		return validation.Errors{
+			"resource": validation.NewError(
+				"validation_resource_conflict",
+				fmt.Sprintf("The resource is already booked by %q at %s.", conflict.With.Title, conflict.With.Start.Format(time.RFC3339)),
+			),
+		}
+	}
+
+	return e.Next()
+}
@@ -0,0 +1,72 @@
+package hooks
+
+import (
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+	"schedule/config"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestResourceConflictInOwnerZone(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	Register(app, cfg)
+
+	users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := core.NewRecord(users)
+	owner.SetEmail("owner@example.com")
+	owner.SetPassword("password123")
+	owner.Set("timezone", "Europe/Berlin")
+	if err := app.Save(owner); err != nil {
+		t.Fatal(err)
+	}
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func(fields map[string]any) error {
+		rec := core.NewRecord(events)
+		rec.Load(fields)
+		return app.Save(rec)
+	}
+
+	// the room every day at 09:00 Berlin time, from before summer time
+	if err := save(map[string]any{
+		"title": "Standup", "start": "2026-03-27 08:00:00.000Z", "end": "2026-03-27 08:30:00.000Z",
+		"rrule": "FREQ=DAILY;COUNT=10", "resource": "room-1", "owner": owner.Id,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 09:00 CEST is 07:00 UTC: taken
+	err = save(map[string]any{
+		"title": "Interview", "start": "2026-03-31 07:00:00.000Z", "end": "2026-03-31 07:30:00.000Z",
+		"resource": "room-1", "owner": owner.Id,
+	})
+	if err == nil || !strings.Contains(err.Error(), "already booked") {
+		t.Fatalf("expected a resource conflict, got %v", err)
+	}
+
+	// 08:00 UTC is free once the series moved with summer time
+	if err := save(map[string]any{
+		"title": "Interview", "start": "2026-03-31 08:00:00.000Z", "end": "2026-03-31 08:30:00.000Z",
+		"resource": "room-1", "owner": owner.Id,
+	}); err != nil {
+		t.Fatalf("expected no conflict at 08:00 UTC, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"strconv"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// noOccurrencesKey is a custom (non-persisted) record key set by
+// validateRRule on the saves of a series left without any occurrence.
+const noOccurrencesKey = "@noOccurrences"
+
+// validateRRule rejects an rrule the expansion couldn't parse, such as an
+// open-ended MINUTELY rule, or one with both COUNT and UNTIL (RFC 5545 allows
+// only one), and exdates outside the bounds of the series or more of them
+// than cfg.MaxExdates. Updates leaving the rrule alone are not checked, nor
+// updates leaving the exdates alone for the exdates, so series stored before
+// a rule was refused stay editable (and shortening a rule may leave old
+// exdates behind). A series left without any occurrence is saved, with a
+// warning in the logs and in the record API response (see addSaveWarnings).
+func (h *eventHooks) validateRRule(e *core.RecordEvent) error {
+	rec := e.Record
+	rule := rec.GetString("rrule")
+	if rule == "" {
+		return e.Next()
+	}
+
+	if rec.IsNew() || rule != rec.Original().GetString("rrule") {
+		if _, err := calendar.ValidateRRule(rule); err != nil {
+			return validation.Errors{"rrule": err}
+		}
+	}
+
+	ev := calendar.EventFromRecord(rec)
+	if rec.IsNew() || changesAny(rec, []string{"exdates"}) {
+		if h.cfg.MaxExdates > 0 && len(ev.Exdates) > h.cfg.MaxExdates {
+			return validation.Errors{"exdates": validation.NewError("validation_too_many_exdates",
+				"An event can have at most "+strconv.Itoa(h.cfg.MaxExdates)+" exdates.")}
+		}
+		if outside := calendar.ExdatesOutside(ev, h.cfg.Timezone); len(outside) > 0 {
+			return validation.Errors{"exdates": validation.NewError("validation_exdate_outside_series",
+				"Exdate "+calendar.ISO(outside[0])+" is outside the series.")}
+		}
+	}
+
+	if rec.IsNew() || changesAny(rec, []string{"rrule", "start", "exdates", "pauses"}) {
+		_, ok := ev.FirstOccurrence(h.cfg.Timezone)
+		if !ok {
+			e.App.Logger().Warn("event rrule yields no occurrences", "event", rec.Id, "rrule", rule)
+		}
+		rec.Set(noOccurrencesKey, !ok)
+	}
+
+	return e.Next()
+}
+
+// addSaveWarnings adds the warnings of the save to the record API response of
+// an event, as {"warnings": {field: {code, message}}} next to its fields (the
+// other custom keys stay hidden). The event is saved all the same.
+func addSaveWarnings(e *core.RecordEnrichEvent) error {
+	if e.Record.GetBool(noOccurrencesKey) {
+		for key := range e.Record.CustomData() {
+			e.Record.Hide(key)
+		}
+		e.Record.WithCustomData(true).Set("warnings", map[string]any{
+			"rrule": map[string]string{
+				"code":    "validation_rrule_no_occurrences",
+				"message": "The series has no occurrences.",
+			},
+		})
+	}
+	return e.Next()
+}
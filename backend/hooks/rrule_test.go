@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"schedule/calendar"
+	"schedule/config"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestValidateRRule(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	Register(app, cfg)
+
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEvent := func(rule string) *core.Record {
+		rec := core.NewRecord(events)
+		rec.Load(map[string]any{"title": "Pill", "start": "2026-03-10 08:00:00.000Z", "end": "2026-03-10 08:05:00.000Z", "rrule": rule})
+		return rec
+	}
+
+	for _, rule := range []string{"FREQ=HOURLY;INTERVAL=2;COUNT=5", "FREQ=HOURLY", "FREQ=MINUTELY;INTERVAL=90", "FREQ=MINUTELY;INTERVAL=15;COUNT=8"} {
+		if err := app.Save(newEvent(rule)); err != nil {
+			t.Errorf("%s rejected: %v", rule, err)
+		}
+	}
+	for _, rule := range []string{"FREQ=MINUTELY;INTERVAL=15", "FREQ=SECONDLY", "FREQ=DAILY;BYSETPOS=1"} {
+		err := app.Save(newEvent(rule))
+		if err == nil || !strings.Contains(err.Error(), "rrule") {
+			t.Errorf("%s: %v, want an rrule error", rule, err)
+		}
+	}
+
+	// a series stored before its rule was refused stays editable
+	legacy := newEvent("FREQ=HOURLY;COUNT=3")
+	if err := app.Save(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.DB().Update(calendar.EventsCollection, dbx.Params{"rrule": "FREQ=MINUTELY;INTERVAL=15"}, dbx.HashExp{"id": legacy.Id}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	legacy, err = app.FindRecordById(calendar.EventsCollection, legacy.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy.Set("title", "Pill (renamed)")
+	if err := app.Save(legacy); err != nil {
+		t.Fatalf("renaming a stored series: %v", err)
+	}
+}
+
+// A series without any occurrence is saved, and the response says so.
+func TestNoOccurrencesWarning(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer seed.Cleanup()
+	users, err := seed.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := core.NewRecord(users)
+	owner.SetEmail("owner@example.com")
+	owner.SetPassword("password123")
+	if err := seed.Save(owner); err != nil {
+		t.Fatal(err)
+	}
+	token, err := owner.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := func(t testing.TB) *tests.TestApp {
+		app, err := tests.NewTestApp(seed.DataDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		Register(app, cfg)
+		return app
+	}
+	// 10 March 2026 is a Tuesday
+	series := func(rule string) *strings.Reader {
+		return strings.NewReader(`{"title":"Pill","start":"2026-03-10 08:00:00.000Z","end":"2026-03-10 08:05:00.000Z","rrule":"` + rule + `"}`)
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:               "series without occurrences",
+			Body:               series("FREQ=WEEKLY;BYDAY=MO;UNTIL=20260310T235959Z"),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Pill"`, `"warnings":{"rrule":{"code":"validation_rrule_no_occurrences","message":"The series has no occurrences."}}`},
+			NotExpectedContent: []string{`"@`},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if n, _ := app.CountRecords(calendar.EventsCollection); n != 1 {
+					t.Fatalf("%d events saved, want the series", n)
+				}
+			},
+		},
+		{
+			Name:               "series with occurrences",
+			Body:               series("FREQ=WEEKLY;BYDAY=MO;COUNT=2"),
+			ExpectedStatus:     http.StatusOK,
+			ExpectedContent:    []string{`"title":"Pill"`},
+			NotExpectedContent: []string{`"warnings"`, `"@`},
+		},
+	}
+	for _, s := range scenarios {
+		s.Method = http.MethodPost
+		s.URL = "/api/collections/" + calendar.EventsCollection + "/records"
+		s.Headers = map[string]string{"Authorization": token}
+		s.TestAppFactory = factory
+		s.Test(t)
+	}
+}
@@ -0,0 +1,24 @@
+package hooks
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// bumpSequence increments the sequence of an event whose time or location
+// changed (see calendar.SequenceFields), unless the save already raises it,
+// as imports of a newer revision do. Other changes, and attempts to lower
+// it, keep the stored sequence.
+func bumpSequence(e *core.RecordEvent) error {
+	rec := e.Record
+	stored := rec.Original().GetInt("sequence")
+	switch {
+	case rec.GetInt("sequence") > stored:
+	case changesAny(rec, calendar.SequenceFields):
+		rec.Set("sequence", stored+1)
+	default:
+		rec.Set("sequence", stored)
+	}
+	return e.Next()
+}
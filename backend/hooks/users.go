@@ -0,0 +1,31 @@
+package hooks
+
+import (
+	"time"
+
+	"schedule/calendar"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// validateUserTimezone rejects a users timezone the server can't load, the
+// emails of the user would silently fall back to the default zone.
+func validateUserTimezone(e *core.RecordEvent) error {
+	if name := e.Record.GetString("timezone"); name != "" {
+		if _, err := time.LoadLocation(name); err != nil {
+			return validation.Errors{"timezone": validation.NewError("validation_invalid_timezone", "Unknown time zone.")}
+		}
+	}
+	return e.Next()
+}
+
+// recordEventDeletion keeps the owner's eventsDeleted current, so the feeds'
+// Last-Modified moves when an event leaves them. The deletion itself already
+// succeeded, a failure is only logged.
+func recordEventDeletion(e *core.RecordEvent) error {
+	if err := calendar.RecordEventDeletion(e.App, e.Record.GetString("owner"), time.Now()); err != nil {
+		e.App.Logger().Warn("event deletion not recorded", "event", e.Record.Id, "error", err)
+	}
+	return e.Next()
+}
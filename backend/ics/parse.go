@@ -0,0 +1,349 @@
+// Package ics reads and writes iCalendar (RFC 5545) data.
+//
+// It only models what the schedule backend needs: components, properties with
+// parameters, and the DATE/DATE-TIME/TEXT value types.
+package ics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Property is a single content line, e.g. DTSTART;TZID=Europe/Berlin:20250101T090000.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param returns the value of a parameter (case-insensitive name).
+func (p *Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component is a BEGIN/END block such as VCALENDAR, VEVENT or VALARM.
+type Component struct {
+	Name       string
+	Properties []*Property
+	Children   []*Component
+}
+
+// Prop returns the first property with the given name, or nil.
+func (c *Component) Prop(name string) *Property {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Props returns all properties with the given name.
+func (c *Component) Props(name string) []*Property {
+	var out []*Property
+	for _, p := range c.Properties {
+		if p.Name == name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Text returns the unescaped TEXT value of the first property with the given
+// name, or an empty string.
+func (c *Component) Text(name string) string {
+	if p := c.Prop(name); p != nil {
+		return UnescapeText(p.Value)
+	}
+	return ""
+}
+
+// TextList returns the values of every property with the given name, each a
+// comma separated TEXT list (as in CATEGORIES), split and unescaped.
+func (c *Component) TextList(name string) []string {
+	var out []string
+	for _, p := range c.Props(name) {
+		start := 0
+		for i := 0; i < len(p.Value); i++ {
+			switch p.Value[i] {
+			case '\\':
+				i++ // escaped character, \, is not a separator
+			case ',':
+				out = append(out, UnescapeText(p.Value[start:i]))
+				start = i + 1
+			}
+		}
+		out = append(out, UnescapeText(p.Value[start:]))
+	}
+	return out
+}
+
+// Components returns the direct children with the given name.
+func (c *Component) Components(name string) []*Component {
+	var out []*Component
+	for _, child := range c.Children {
+		if child.Name == name {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// Parse reads an iCalendar stream and returns its root component (usually VCALENDAR).
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("ics: line %d: %w", i+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, c)
+			} else if root == nil {
+				root = c
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("ics: line %d: unexpected END:%s", i+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("ics: line %d: property outside of a component", i+1)
+			}
+			c := stack[len(stack)-1]
+			c.Properties = append(c.Properties, prop)
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("ics: no component found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("ics: unterminated %s", stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfold joins folded content lines (continuations start with a space or tab).
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// parseLine splits "NAME;PARAM=VALUE:value" honouring quoted parameter values.
+func parseLine(line string) (*Property, error) {
+	p := &Property{Params: map[string]string{}}
+
+	inQuote := false
+	nameEnd, valueStart := -1, -1
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == ';' && !inQuote && nameEnd < 0:
+			nameEnd = i
+		case r == ':' && !inQuote:
+			valueStart = i
+		}
+		if valueStart >= 0 {
+			break
+		}
+	}
+	if valueStart < 0 {
+		return nil, fmt.Errorf("missing ':' in %q", line)
+	}
+	if nameEnd < 0 {
+		nameEnd = valueStart
+	}
+
+	p.Name = strings.ToUpper(line[:nameEnd])
+	p.Value = line[valueStart+1:]
+
+	if nameEnd < valueStart {
+		for _, param := range splitParams(line[nameEnd+1 : valueStart]) {
+			k, v, _ := strings.Cut(param, "=")
+			p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+
+	return p, nil
+}
+
+func splitParams(s string) []string {
+	var out []string
+	inQuote := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case r == ';' && !inQuote:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+// UnescapeText decodes a TEXT value (\n, \,, \; and \\).
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Time decodes a DATE or DATE-TIME property. It reports whether the value was
+// a DATE (all-day). Floating times and TZIDs that cannot be loaded resolve in
+// fallback.
+func (p *Property) Time(fallback *time.Location) (time.Time, bool, error) {
+	return parseTimeValue(p.Value, p.Param("VALUE"), p.Param("TZID"), fallback)
+}
+
+// Times decodes a multi-valued DATE/DATE-TIME property such as EXDATE.
+func (p *Property) Times(fallback *time.Location) ([]time.Time, error) {
+	var out []time.Time
+	for _, v := range strings.Split(p.Value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		t, _, err := parseTimeValue(v, p.Param("VALUE"), p.Param("TZID"), fallback)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func parseTimeValue(v, valueType, tzid string, fallback *time.Location) (time.Time, bool, error) {
+	if fallback == nil {
+		fallback = time.UTC
+	}
+
+	if strings.EqualFold(valueType, "DATE") || (len(v) == 8 && !strings.Contains(v, "T")) {
+		t, err := time.ParseInLocation("20060102", v, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(v, "Z") {
+		t, err := time.Parse("20060102T150405Z", v)
+		return t, false, err
+	}
+
+	loc := fallback
+	if tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", v, loc)
+	return t, false, err
+}
+
+// Duration decodes a DURATION value such as "PT15M", "-P1D" or "-PT1H30M".
+func Duration(s string) (time.Duration, error) {
+	orig := s
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("ics: invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	num, parts := 0, 0
+	digits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("ics: invalid duration %q", orig)
+		}
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(num) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(num) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(num) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(num) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(num) * time.Second
+		default:
+			return 0, fmt.Errorf("ics: invalid duration %q", orig)
+		}
+		num = 0
+		digits = false
+		parts++
+	}
+	// "P" and "PT" have no value at all
+	if digits || parts == 0 {
+		return 0, fmt.Errorf("ics: invalid duration %q", orig)
+	}
+
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
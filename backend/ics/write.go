@@ -0,0 +1,136 @@
+package ics
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the RFC 5545 content line limit before folding.
+const maxLineOctets = 75
+
+// NewComponent returns an empty component with the given name.
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add appends a property with a raw (already encoded) value.
+func (c *Component) Add(name, value string, params ...string) *Component {
+	p := &Property{Name: name, Value: value, Params: map[string]string{}}
+	for i := 0; i+1 < len(params); i += 2 {
+		p.Params[strings.ToUpper(params[i])] = params[i+1]
+	}
+	c.Properties = append(c.Properties, p)
+	return c
+}
+
+// AddText appends a TEXT property, escaping the value. Empty values are skipped.
+func (c *Component) AddText(name, value string) *Component {
+	if value == "" {
+		return c
+	}
+	return c.Add(name, EscapeText(value))
+}
+
+// AddTextList appends a property holding a comma separated TEXT list, escaping
+// each value. Empty values are dropped and nothing is added when none remain.
+func (c *Component) AddTextList(name string, values ...string) *Component {
+	escaped := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			escaped = append(escaped, EscapeText(v))
+		}
+	}
+	if len(escaped) == 0 {
+		return c
+	}
+	return c.Add(name, strings.Join(escaped, ","))
+}
+
+// AddChild appends a nested component.
+func (c *Component) AddChild(child *Component) *Component {
+	c.Children = append(c.Children, child)
+	return c
+}
+
+// FormatUTC formats t as a UTC DATE-TIME value.
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// FormatFloating formats the wall clock time of t as a floating DATE-TIME
+// value (no Z, no TZID).
+func FormatFloating(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// FormatDate formats t as a DATE value.
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// EscapeText encodes a TEXT value.
+func EscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// Encode writes c (and its children) as iCalendar content lines, folding long
+// lines and terminating each with CRLF.
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	writeComponent(bw, c)
+	return bw.Flush()
+}
+
+func writeComponent(w *bufio.Writer, c *Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		writeLine(w, formatProperty(p))
+	}
+	for _, child := range c.Children {
+		writeComponent(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+func formatProperty(p *Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := p.Params[k]
+		if strings.ContainsAny(v, ":;,") {
+			v = `"` + v + `"`
+		}
+		b.WriteString(";" + k + "=" + v)
+	}
+
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// writeLine folds a content line at 75 octets without splitting UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // the leading space counts
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
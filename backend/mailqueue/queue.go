@@ -0,0 +1,148 @@
+// Package mailqueue sends the schedule emails (reminders, invitations,
+// digests) through a persistent queue, so bulk sends go out at a controlled
+// pace with retries instead of hammering the SMTP server.
+package mailqueue
+
+import (
+	"net/mail"
+	"sync"
+	"time"
+
+	"schedule/config"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Collection stores queued emails.
+const Collection = "email_queue"
+
+// Queue statuses.
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// Message is an email to enqueue.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Enqueue stores m for sending by the worker.
+func Enqueue(app core.App, m Message) error {
+	collection, err := app.FindCollectionByNameOrId(Collection)
+	if err != nil {
+		return err
+	}
+	rec := core.NewRecord(collection)
+	rec.Set("to", m.To)
+	rec.Set("subject", m.Subject)
+	rec.Set("html", m.HTML)
+	rec.Set("text", m.Text)
+	rec.Set("status", StatusPending)
+	rec.Set("nextAttempt", time.Now())
+	return app.Save(rec)
+}
+
+// Depth counts the queued emails per status.
+func Depth(app core.App) (map[string]int64, error) {
+	out := map[string]int64{}
+	for _, status := range []string{StatusPending, StatusSent, StatusFailed} {
+		n, err := app.CountRecords(Collection, dbx.HashExp{"status": status})
+		if err != nil {
+			return nil, err
+		}
+		out[status] = n
+	}
+	return out, nil
+}
+
+// worker drains the queue once a minute, sending at most cfg.MailRate emails
+// per run with cfg.MailConcurrency in flight.
+type worker struct {
+	app core.App
+	cfg *config.Config
+	mu  sync.Mutex
+}
+
+// Register schedules the queue worker.
+func Register(app core.App, cfg *config.Config) {
+	w := &worker{app: app, cfg: cfg}
+	app.Cron().MustAdd("scheduleMailQueue", "* * * * *", w.run)
+}
+
+func (w *worker) run() {
+	// a slow run must not overlap the next one, it would double the rate
+	if !w.mu.TryLock() {
+		return
+	}
+	defer w.mu.Unlock()
+
+	due, err := w.app.FindRecordsByFilter(Collection,
+		"status = {:status} && nextAttempt <= {:now}", "nextAttempt", w.cfg.MailRate, 0,
+		dbx.Params{"status": StatusPending, "now": time.Now().UTC().Format(types.DefaultDateLayout)},
+	)
+	if err != nil {
+		w.app.Logger().Error("mail queue: failed to load due emails", "error", err)
+		return
+	}
+
+	// spread the sends over the minute instead of bursting them
+	spacing := time.Minute / time.Duration(w.cfg.MailRate)
+	slots := make(chan struct{}, w.cfg.MailConcurrency)
+	var wg sync.WaitGroup
+	for i, rec := range due {
+		if i > 0 {
+			time.Sleep(spacing)
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(rec *core.Record) {
+			defer func() { <-slots; wg.Done() }()
+			w.send(rec)
+		}(rec)
+	}
+	wg.Wait()
+}
+
+// send delivers one queued email and records the outcome. Failures are
+// retried with an exponential backoff (1, 2, 4... minutes) until
+// cfg.MailMaxAttempts.
+func (w *worker) send(rec *core.Record) {
+	settings := w.app.Settings()
+	err := w.app.NewMailClient().Send(&mailer.Message{
+		From:    mail.Address{Name: settings.Meta.SenderName, Address: settings.Meta.SenderAddress},
+		To:      []mail.Address{{Address: rec.GetString("to")}},
+		Subject: rec.GetString("subject"),
+		HTML:    rec.GetString("html"),
+		Text:    rec.GetString("text"),
+	})
+
+	attempts := rec.GetInt("attempts") + 1
+	rec.Set("attempts", attempts)
+	switch {
+	case err == nil:
+		rec.Set("status", StatusSent)
+		rec.Set("sentAt", time.Now())
+		rec.Set("lastError", "")
+	case attempts >= w.cfg.MailMaxAttempts:
+		rec.Set("status", StatusFailed)
+		rec.Set("lastError", err.Error())
+	default:
+		rec.Set("nextAttempt", time.Now().Add(time.Minute<<(attempts-1)))
+		rec.Set("lastError", err.Error())
+	}
+	if err != nil {
+		w.app.Logger().Warn("mail queue: send failed", "id", rec.Id, "attempt", attempts, "error", err)
+	}
+
+	if err := w.app.Save(rec); err != nil {
+		w.app.Logger().Error("mail queue: failed to save email state", "id", rec.Id, "error", err)
+	}
+}
@@ -6,15 +6,24 @@ package main
 
 import (
 	"embed"
+	"fmt"
 	"io/fs"
 	"log"
+	"mime"
 	"os"
 	"strings"
+	_ "time/tzdata" // zoneinfo fallback for hosts/containers without tzdata
 
+	"schedule/api"
+	"schedule/calendar"
+	"schedule/config"
+	"schedule/hooks"
+	"schedule/mailqueue"
 	_ "schedule/migrations"
+	"schedule/reminders"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
-	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 )
@@ -24,9 +33,26 @@ import (
 //go:embed all:dist
 var distFiles embed.FS
 
+// assetTypes are the content types of the frontend assets that minimal
+// containers (no /etc/mime.types) get wrong or leave out. Browsers refuse
+// module scripts and WebAssembly served with a generic type.
+var assetTypes = map[string]string{
+	".mjs":   "text/javascript; charset=utf-8",
+	".wasm":  "application/wasm",
+	".webp":  "image/webp",
+	".woff2": "font/woff2",
+}
+
 func main() {
 	app := pocketbase.New()
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// before the migrations and hooks refer to it
+	calendar.EventsCollection = cfg.EventsCollection
+
 	var DistDirFS, _ = fs.Sub(distFiles, "dist")
 
 	// app.OnServe().BindFunc(func(se *core.ServeEvent) error {
@@ -36,9 +62,33 @@ func main() {
 	// 	return se.Next()
 	//
 	// })
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		e.App.Logger().Info("time zone database loaded", "source", config.TZDataSource())
+		return nil
+	})
+
+	hooks.Register(app, cfg)
+	reminders.Register(app, cfg)
+	mailqueue.Register(app, cfg)
+
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if err := bootstrapSuperuser(se.App); err != nil {
+			return err
+		}
+
+		api.Register(se, cfg)
 
-		se.Router.GET("/{path...}", apis.Static(DistDirFS, false))
+		for ext, typ := range assetTypes {
+			if err := mime.AddExtensionType(ext, typ); err != nil {
+				return err
+			}
+		}
+		if err := registerFrontend(se, DistDirFS, cfg.BasePath); err != nil {
+			return err
+		}
 
 		return se.Next()
 	})
@@ -56,3 +106,35 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// bootstrapSuperuser creates the first superuser from SUPERUSER_EMAIL and
+// SUPERUSER_PASSWORD, only while no superuser exists (PocketBase's installer
+// placeholder aside), so it is a no-op on every later start and never
+// resets an existing account. Without the variables it warns instead.
+func bootstrapSuperuser(app core.App) error {
+	total, err := app.CountRecords(core.CollectionNameSuperusers, dbx.Not(dbx.HashExp{
+		"email": core.DefaultInstallerEmail,
+	}))
+	if err != nil || total > 0 {
+		return err
+	}
+
+	email, password := os.Getenv("SUPERUSER_EMAIL"), os.Getenv("SUPERUSER_PASSWORD")
+	if email == "" || password == "" {
+		app.Logger().Warn("no superuser exists: set SUPERUSER_EMAIL and SUPERUSER_PASSWORD, or run \"superuser upsert EMAIL PASS\"")
+		return nil
+	}
+
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		return err
+	}
+	record := core.NewRecord(superusers)
+	record.SetEmail(email)
+	record.SetPassword(password)
+	if err := app.Save(record); err != nil {
+		return fmt.Errorf("creating the superuser from SUPERUSER_EMAIL: %w", err)
+	}
+	app.Logger().Info("created the first superuser", "email", email)
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBootstrapSuperuser(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+	count := func() int64 {
+		t.Helper()
+		n, err := app.CountRecords(core.CollectionNameSuperusers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// without the variables nothing is created
+	t.Setenv("SUPERUSER_EMAIL", "")
+	t.Setenv("SUPERUSER_PASSWORD", "")
+	if err := bootstrapSuperuser(app); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 0 {
+		t.Fatalf("%d superusers without the variables", n)
+	}
+
+	t.Setenv("SUPERUSER_EMAIL", "admin@example.com")
+	t.Setenv("SUPERUSER_PASSWORD", "first-password")
+	if err := bootstrapSuperuser(app); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !admin.ValidatePassword("first-password") {
+		t.Fatal("the superuser doesn't have the configured password")
+	}
+
+	// later starts leave the account alone, even with other values
+	t.Setenv("SUPERUSER_EMAIL", "someone@example.com")
+	t.Setenv("SUPERUSER_PASSWORD", "second-password")
+	if err := bootstrapSuperuser(app); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("%d superusers after a second start, want 1", n)
+	}
+	admin, err = app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "admin@example.com")
+	if err != nil || !admin.ValidatePassword("first-password") {
+		t.Fatal("a second start changed the superuser")
+	}
+}
+
+func TestBootstrapSuperuserIgnoresInstaller(t *testing.T) {
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	// the placeholder PocketBase creates for its installer link
+	superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installer := core.NewRecord(superusers)
+	installer.SetEmail(core.DefaultInstallerEmail)
+	installer.SetRandomPassword()
+	if err := app.Save(installer); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SUPERUSER_EMAIL", "admin@example.com")
+	t.Setenv("SUPERUSER_PASSWORD", "first-password")
+	if err := bootstrapSuperuser(app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "admin@example.com"); err != nil {
+		t.Fatal("the installer placeholder prevented the bootstrap")
+	}
+}
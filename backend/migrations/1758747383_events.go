@@ -1,6 +1,8 @@
 package migrations
 
 import (
+	"schedule/calendar"
+
 	"github.com/pocketbase/pocketbase/core"
 	m "github.com/pocketbase/pocketbase/migrations"
 )
@@ -8,7 +10,7 @@ import (
 func init() {
 	m.Register(func(app core.App) error {
 		// --- UP (create collection) ---
-		collection := core.NewBaseCollection("events")
+		collection := core.NewBaseCollection(calendar.EventsCollection)
 
 		collection.Fields.Add(
 			// title
@@ -81,7 +83,7 @@ func init() {
 		return app.Save(collection)
 	}, func(app core.App) error {
 		// --- DOWN (drop collection) ---
-		coll, err := app.FindCollectionByNameOrId("events")
+		coll, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
 		if err != nil {
 			return err
 		}
@@ -6,27 +6,14 @@ import (
 	m "github.com/pocketbase/pocketbase/migrations"
 )
 
+// This migration used to create admin@example.com with a fixed password. It
+// is kept (empty) so databases that already applied it stay consistent; the
+// first superuser now comes from SUPERUSER_EMAIL/SUPERUSER_PASSWORD on
+// startup (see bootstrapSuperuser in main.go).
 func init() {
 	m.Register(func(app core.App) error {
-		superusers, err := app.FindCollectionByNameOrId(core.CollectionNameSuperusers)
-		if err != nil {
-			return err
-		}
-
-		record := core.NewRecord(superusers)
-
-		// note: the values can be eventually loaded via os.Getenv(key)
-		// or from a special local config file
-		record.Set("email", "admin@example.com")
-		record.Set("password", "changeme123")
-
-		return app.Save(record)
-	}, func(app core.App) error { // optional revert operation
-		record, _ := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "admin@example.com")
-		if record == nil {
-			return nil // probably already deleted
-		}
-
-		return app.Delete(record)
+		return nil
+	}, func(app core.App) error {
+		return nil
 	})
 }
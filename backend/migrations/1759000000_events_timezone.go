@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add timezone) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// IANA zone recurrences are expanded in (empty = viewer's timezone)
+		collection.Fields.Add(&core.TextField{
+			Name: "timezone",
+			Max:  64,
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop timezone) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("timezone")
+		return app.Save(collection)
+	})
+}
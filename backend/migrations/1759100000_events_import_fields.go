@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add import/override fields) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			// iCalendar UID, kept so re-imports and overrides can find their series
+			&core.TextField{
+				Name: "uid",
+				Max:  255,
+			},
+			// hash of the imported VEVENT, used to skip unchanged re-imports
+			&core.TextField{
+				Name: "importHash",
+				Max:  64,
+			},
+			// sourceId links a detached occurrence to its series
+			&core.RelationField{
+				Name:          "sourceId",
+				CollectionId:  collection.Id,
+				MaxSelect:     1,
+				CascadeDelete: true,
+			},
+			// original start of the series instance a detached occurrence replaces
+			&core.DateField{
+				Name: "recurrenceId",
+			},
+		)
+
+		collection.AddIndex("idx_"+calendar.EventsCollection+"_uid", false, "uid", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop import/override fields) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.RemoveIndex("idx_" + calendar.EventsCollection + "_uid")
+		collection.Fields.RemoveByName("uid")
+		collection.Fields.RemoveByName("importHash")
+		collection.Fields.RemoveByName("sourceId")
+		collection.Fields.RemoveByName("recurrenceId")
+		return app.Save(collection)
+	})
+}
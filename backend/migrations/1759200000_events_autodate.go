@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add created/updated) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection.Fields.Add(
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop created/updated) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("created")
+		collection.Fields.RemoveByName("updated")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("hidden_events")
+
+		// each user manages only their own hidden entries
+		collection.ListRule = types.Pointer("user = @request.auth.id")
+		collection.ViewRule = types.Pointer("user = @request.auth.id")
+		collection.CreateRule = types.Pointer("@request.auth.id != '' && user = @request.auth.id")
+		collection.DeleteRule = types.Pointer("user = @request.auth.id")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.RelationField{
+				Name:          "eventId",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			// optional: hide only the occurrence starting at this instant
+			&core.DateField{
+				Name: "occurrenceStart",
+			},
+		)
+
+		collection.AddIndex("idx_hidden_events_unique", true, "user, eventId, occurrenceStart", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("hidden_events")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		collection := core.NewBaseCollection("categories")
+
+		collection.Fields.Add(
+			// matches the events.category value
+			&core.TextField{
+				Name:     "name",
+				Required: true,
+				Max:      50,
+			},
+			&core.TextField{
+				Name: "color",
+				Max:  50,
+			},
+			// reminders inherited by new events that don't set their own
+			&core.JSONField{
+				Name: "reminderMinutes",
+			},
+		)
+
+		collection.AddIndex("idx_categories_name", true, "name", "")
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// seed the built-in categories
+		for _, name := range []string{"College", "Personal", "Other"} {
+			record := core.NewRecord(collection)
+			record.Set("name", name)
+			record.Set("reminderMinutes", []int{})
+			if err := app.Save(record); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("categories")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add resource) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// room/equipment the event books; at most one event per resource at a time
+		collection.Fields.Add(&core.TextField{
+			Name: "resource",
+			Max:  100,
+		})
+		collection.AddIndex("idx_"+calendar.EventsCollection+"_resource", false, "resource", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop resource) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.RemoveIndex("idx_" + calendar.EventsCollection + "_resource")
+		collection.Fields.RemoveByName("resource")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add events.owner and users.icsTokenVersion) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// bumping the version revokes every ICS subscription URL issued before
+		users.Fields.Add(&core.NumberField{
+			Name:    "icsTokenVersion",
+			OnlyInt: true,
+		})
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		events.Fields.Add(&core.RelationField{
+			Name:          "owner",
+			CollectionId:  users.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		events.AddIndex("idx_"+calendar.EventsCollection+"_owner", false, "owner", "")
+
+		return app.Save(events)
+	}, func(app core.App) error {
+		// --- DOWN ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.RemoveIndex("idx_" + calendar.EventsCollection + "_owner")
+		events.Fields.RemoveByName("owner")
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("icsTokenVersion")
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("materialized_occurrences")
+
+		// readable by any authenticated user, written by the server only
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.DateField{
+				Name:     "start",
+				Required: true,
+			},
+			&core.DateField{
+				Name:     "end",
+				Required: true,
+			},
+			&core.BoolField{
+				Name: "allDay",
+			},
+		)
+
+		collection.AddIndex("idx_materialized_occurrences_event_start", true, "event, start", "")
+		collection.AddIndex("idx_materialized_occurrences_start", false, "start", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("materialized_occurrences")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("push_subscriptions")
+
+		// registered through /api/schedule/push/subscriptions, users can list and
+		// drop their own
+		collection.ListRule = types.Pointer("user = @request.auth.id")
+		collection.ViewRule = types.Pointer("user = @request.auth.id")
+		collection.DeleteRule = types.Pointer("user = @request.auth.id")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.URLField{
+				Name:     "endpoint",
+				Required: true,
+			},
+			// keys of the browser PushSubscription (base64url)
+			&core.TextField{
+				Name:     "p256dh",
+				Required: true,
+			},
+			&core.TextField{
+				Name:     "auth",
+				Required: true,
+			},
+		)
+
+		collection.AddIndex("idx_push_subscriptions_endpoint", true, "endpoint", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("push_subscriptions")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
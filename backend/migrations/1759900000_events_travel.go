@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add travel buffers) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// minutes blocked before/after the event for conflicts and freebusy only
+		collection.Fields.Add(
+			&core.NumberField{
+				Name:    "travelBeforeMinutes",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+				Max:     types.Pointer(1440.0),
+			},
+			&core.NumberField{
+				Name:    "travelAfterMinutes",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+				Max:     types.Pointer(1440.0),
+			},
+		)
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop travel buffers) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("travelBeforeMinutes")
+		collection.Fields.RemoveByName("travelAfterMinutes")
+		return app.Save(collection)
+	})
+}
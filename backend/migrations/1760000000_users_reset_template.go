@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (password reset email) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		users.PasswordAuth.Enabled = true
+		users.ResetPasswordTemplate = core.EmailTemplate{
+			Subject: "Reset your {APP_NAME} password",
+			Body: `<p>Hello,</p>
+<p>Someone asked to reset the password of your {APP_NAME} account. Use the button below to choose a new one.</p>
+<p>
+  <a class="btn" href="{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}" target="_blank" rel="noopener">Reset password</a>
+</p>
+<p><i>If you didn't ask for this, you can ignore this email; your password stays the same.</i></p>
+<p>
+  Thanks,<br/>
+  {APP_NAME}
+</p>`,
+		}
+
+		return app.Save(users)
+	}, func(app core.App) error {
+		// --- DOWN (default template) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.ResetPasswordTemplate = core.NewAuthCollection("tmp").ResetPasswordTemplate
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,76 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("attendees")
+
+		// the event owner manages the invitations, invitees can see their own
+		// and answer through /api/schedule/events/{id}/rsvp
+		collection.ListRule = types.Pointer("event.owner = @request.auth.id || email:lower = @request.auth.email:lower")
+		collection.ViewRule = types.Pointer("event.owner = @request.auth.id || email:lower = @request.auth.email:lower")
+		collection.CreateRule = types.Pointer("event.owner = @request.auth.id")
+		collection.UpdateRule = types.Pointer("event.owner = @request.auth.id")
+		collection.DeleteRule = types.Pointer("event.owner = @request.auth.id")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.EmailField{
+				Name:     "email",
+				Required: true,
+			},
+			&core.TextField{
+				Name: "name",
+				Max:  255,
+			},
+			// iCalendar PARTSTAT, empty means NEEDS-ACTION
+			&core.SelectField{
+				Name:      "status",
+				MaxSelect: 1,
+				Values:    []string{"NEEDS-ACTION", "ACCEPTED", "DECLINED", "TENTATIVE"},
+			},
+			// set when the invitee has an account
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				CascadeDelete: true,
+			},
+		)
+
+		collection.AddIndex("idx_attendees_event_email", true, "event, email", "")
+		collection.AddIndex("idx_attendees_email", false, "email", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("attendees")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create calendars, add events.calendar) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		calendars := core.NewBaseCollection("calendars")
+
+		// each user manages their own calendars
+		calendars.ListRule = types.Pointer("owner = @request.auth.id")
+		calendars.ViewRule = types.Pointer("owner = @request.auth.id")
+		calendars.CreateRule = types.Pointer("@request.auth.id != '' && owner = @request.auth.id")
+		calendars.UpdateRule = types.Pointer("owner = @request.auth.id")
+		calendars.DeleteRule = types.Pointer("owner = @request.auth.id")
+
+		calendars.Fields.Add(
+			&core.TextField{
+				Name:     "name",
+				Required: true,
+				Max:      100,
+			},
+			&core.TextField{
+				Name: "color",
+				Max:  50,
+			},
+			&core.RelationField{
+				Name:          "owner",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			// new events of the owner without a calendar land here
+			&core.BoolField{
+				Name: "isDefault",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+		calendars.AddIndex("idx_calendars_owner_name", true, "owner, name", "")
+
+		if err := app.Save(calendars); err != nil {
+			return err
+		}
+
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// deleting a calendar deletes its events
+		events.Fields.Add(&core.RelationField{
+			Name:          "calendar",
+			CollectionId:  calendars.Id,
+			MaxSelect:     1,
+			CascadeDelete: true,
+		})
+		events.AddIndex("idx_"+calendar.EventsCollection+"_calendar", false, "calendar", "")
+
+		return app.Save(events)
+	}, func(app core.App) error {
+		// --- DOWN (drop events.calendar and calendars) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.RemoveIndex("idx_" + calendar.EventsCollection + "_calendar")
+		events.Fields.RemoveByName("calendar")
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		calendars, err := app.FindCollectionByNameOrId("calendars")
+		if err != nil {
+			return err
+		}
+		return app.Delete(calendars)
+	})
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// managed by /api/schedule/events/{id}/link and resolved by /e/{code};
+		// no API rules, so superusers only
+		collection := core.NewBaseCollection("short_links")
+
+		collection.Fields.Add(
+			&core.TextField{
+				Name:     "code",
+				Required: true,
+				Max:      32,
+			},
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("idx_short_links_code", true, "code", "")
+		// one link per event, reused on every request
+		collection.AddIndex("idx_short_links_event", true, "event", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("short_links")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add reminder type) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// VALARM ACTION of the reminders; empty means display
+		collection.Fields.Add(&core.SelectField{
+			Name:      "reminderType",
+			MaxSelect: 1,
+			Values:    []string{"display", "email"},
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop reminder type) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("reminderType")
+		return app.Save(collection)
+	})
+}
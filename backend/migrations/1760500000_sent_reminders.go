@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// written by the reminder dispatcher so a reminder is sent once;
+		// no API rules, so superusers only
+		collection := core.NewBaseCollection("sent_reminders")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			// start of the reminded occurrence
+			&core.DateField{
+				Name:     "occurrenceStart",
+				Required: true,
+			},
+			// lead time of the reminder (one of the event reminderMinutes)
+			&core.NumberField{
+				Name:    "minutes",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("idx_sent_reminders_key", true, "event, occurrenceStart, minutes", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("sent_reminders")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
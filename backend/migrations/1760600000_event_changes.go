@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection("event_changes")
+
+		// written by the events hooks only; owners can read the history of
+		// their events
+		collection.ListRule = types.Pointer("event.owner = @request.auth.id")
+		collection.ViewRule = types.Pointer("event.owner = @request.auth.id")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.SelectField{
+				Name:      "action",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"create", "update"},
+			},
+			// {"<field>": {"from": ..., "to": ...}} for the changed fields only
+			&core.JSONField{
+				Name: "changes",
+			},
+			// id and collection of the auth record that saved the event, empty
+			// for changes made by the server itself
+			&core.TextField{
+				Name: "actor",
+				Max:  50,
+			},
+			&core.TextField{
+				Name: "actorCollection",
+				Max:  100,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("idx_event_changes_event", false, "event, created", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("event_changes")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add floating flag) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// wall clock time in whatever zone the viewer is in; start/end hold
+		// that wall time as UTC
+		collection.Fields.Add(&core.BoolField{
+			Name: "floating",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop floating flag) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("floating")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create collection) ---
+
+		// filled and drained by the server; no API rules, so superusers only
+		collection := core.NewBaseCollection("email_queue")
+
+		collection.Fields.Add(
+			&core.EmailField{
+				Name:     "to",
+				Required: true,
+			},
+			&core.TextField{
+				Name:     "subject",
+				Required: true,
+				Max:      255,
+			},
+			&core.TextField{
+				Name: "html",
+			},
+			&core.TextField{
+				Name: "text",
+			},
+			&core.SelectField{
+				Name:      "status",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    []string{"pending", "sent", "failed"},
+			},
+			&core.NumberField{
+				Name:    "attempts",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+			},
+			// earliest time of the next attempt (retries back off)
+			&core.DateField{
+				Name: "nextAttempt",
+			},
+			&core.TextField{
+				Name: "lastError",
+			},
+			&core.DateField{
+				Name: "sentAt",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+		)
+
+		collection.AddIndex("idx_email_queue_due", false, "status, nextAttempt", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop collection) ---
+		coll, err := app.FindCollectionByNameOrId("email_queue")
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
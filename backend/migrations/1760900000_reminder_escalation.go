@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add escalation policy and state) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// minutes between follow-ups of an undismissed reminder; 0 disables
+		// escalation
+		events.Fields.Add(&core.NumberField{
+			Name:    "escalateEveryMinutes",
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+			Max:     types.Pointer(1440.0),
+		})
+		// follow-ups sent at most after the first reminder
+		events.Fields.Add(&core.NumberField{
+			Name:    "escalateMax",
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+			Max:     types.Pointer(10.0),
+		})
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		// written by the reminder dispatcher and the dismiss route;
+		// no API rules, so superusers only
+		collection := core.NewBaseCollection("reminder_state")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			// start of the reminded occurrence
+			&core.DateField{
+				Name:     "occurrenceStart",
+				Required: true,
+			},
+			// reminders delivered so far, the first one included
+			&core.NumberField{
+				Name:    "attempts",
+				OnlyInt: true,
+				Min:     types.Pointer(0.0),
+			},
+			// when the next follow-up is due
+			&core.DateField{
+				Name: "nextAt",
+			},
+			&core.BoolField{
+				Name: "dismissed",
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+
+		collection.AddIndex("idx_reminder_state_key", true, "event, occurrenceStart", "")
+		collection.AddIndex("idx_reminder_state_next", false, "dismissed, nextAt", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop escalation policy and state) ---
+		coll, err := app.FindCollectionByNameOrId("reminder_state")
+		if err != nil {
+			return err
+		}
+		if err := app.Delete(coll); err != nil {
+			return err
+		}
+
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.Fields.RemoveByName("escalateEveryMinutes")
+		events.Fields.RemoveByName("escalateMax")
+		return app.Save(events)
+	})
+}
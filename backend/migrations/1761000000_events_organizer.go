@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add organizer) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// ORGANIZER when someone else than the owner organizes the event
+		// (the owner then sends on their behalf); empty means the owner
+		collection.Fields.Add(&core.EmailField{
+			Name: "organizer",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop organizer) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("organizer")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add pauses) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		// [{"from", "to"}] windows in which a series doesn't occur
+		collection.Fields.Add(&core.JSONField{
+			Name: "pauses",
+		})
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop pauses) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		collection.Fields.RemoveByName("pauses")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add locale and timezone) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+
+		// BCP 47 tag (e.g. "en-US") and IANA zone the emails of the user are
+		// formatted in; empty falls back to the server defaults
+		users.Fields.Add(
+			&core.TextField{
+				Name: "locale",
+				Max:  35,
+			},
+			&core.TextField{
+				Name: "timezone",
+				Max:  64,
+			},
+		)
+
+		return app.Save(users)
+	}, func(app core.App) error {
+		// --- DOWN (drop locale and timezone) ---
+		users, err := app.FindCollectionByNameOrId("users")
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("locale")
+		users.Fields.RemoveByName("timezone")
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (transfer action) ---
+		collection, err := app.FindCollectionByNameOrId("event_changes")
+		if err != nil {
+			return err
+		}
+
+		// ownership handed to another user
+		action := collection.Fields.GetByName("action").(*core.SelectField)
+		action.Values = []string{"create", "update", "transfer"}
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop transfer action) ---
+		collection, err := app.FindCollectionByNameOrId("event_changes")
+		if err != nil {
+			return err
+		}
+		// keep the entries, as the owner change they are
+		if _, err := app.DB().Update("event_changes", dbx.Params{"action": "update"}, dbx.HashExp{"action": "transfer"}).Execute(); err != nil {
+			return err
+		}
+		action := collection.Fields.GetByName("action").(*core.SelectField)
+		action.Values = []string{"create", "update"}
+		return app.Save(collection)
+	})
+}
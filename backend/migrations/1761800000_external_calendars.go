@@ -0,0 +1,133 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// externalStateFields are the external_calendars fields kept by the mirror,
+// which the records API can't set.
+var externalStateFields = []string{"calendar", "etag", "lastModified", "lastSynced", "lastError"}
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add calendars.external, create external_calendars) ---
+		calendars, err := app.FindCollectionByNameOrId(calendar.CalendarsCollection)
+		if err != nil {
+			return err
+		}
+
+		// mirrors of an external ICS feed, read-only; only the mirror sets it
+		calendars.Fields.Add(&core.BoolField{
+			Name: "external",
+		})
+		calendars.CreateRule = types.Pointer(*calendars.CreateRule + " && @request.body.external:isset = false")
+		calendars.UpdateRule = types.Pointer(*calendars.UpdateRule + " && @request.body.external:isset = false")
+		if err := app.Save(calendars); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection(calendar.ExternalCalendarsCollection)
+
+		notSet := ""
+		for _, name := range externalStateFields {
+			notSet += " && @request.body." + name + ":isset = false"
+		}
+		collection.ListRule = types.Pointer("owner = @request.auth.id")
+		collection.ViewRule = types.Pointer("owner = @request.auth.id")
+		collection.CreateRule = types.Pointer("@request.auth.id != '' && owner = @request.auth.id" + notSet)
+		collection.UpdateRule = types.Pointer("owner = @request.auth.id && @request.body.owner:isset = false" + notSet)
+		collection.DeleteRule = types.Pointer("owner = @request.auth.id")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "owner",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			// http(s) or webcal URL of the ICS feed
+			&core.URLField{
+				Name:     "url",
+				Required: true,
+			},
+			// name of the mirror calendar, the URL host when empty
+			&core.TextField{
+				Name: "name",
+				Max:  100,
+			},
+			// minutes between two fetches
+			&core.NumberField{
+				Name:    "refreshMinutes",
+				OnlyInt: true,
+				Min:     types.Pointer(float64(calendar.MinExternalRefresh)),
+				Max:     types.Pointer(float64(7 * 24 * 60)),
+			},
+			// the mirror calendar, created with the subscription
+			&core.RelationField{
+				Name:          "calendar",
+				CollectionId:  calendars.Id,
+				MaxSelect:     1,
+				CascadeDelete: true,
+			},
+			// HTTP validators of the last fetched feed
+			&core.TextField{
+				Name:   "etag",
+				Max:    500,
+				Hidden: true,
+			},
+			&core.TextField{
+				Name:   "lastModified",
+				Max:    100,
+				Hidden: true,
+			},
+			&core.DateField{
+				Name: "lastSynced",
+			},
+			// why the last fetch failed, empty when it succeeded
+			&core.TextField{
+				Name: "lastError",
+				Max:  1000,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+		collection.AddIndex("idx_external_calendars_owner", false, "owner", "")
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN (drop external_calendars and calendars.external) ---
+		collection, err := app.FindCollectionByNameOrId(calendar.ExternalCalendarsCollection)
+		if err != nil {
+			return err
+		}
+		if err := app.Delete(collection); err != nil {
+			return err
+		}
+
+		calendars, err := app.FindCollectionByNameOrId(calendar.CalendarsCollection)
+		if err != nil {
+			return err
+		}
+		calendars.Fields.RemoveByName("external")
+		calendars.CreateRule = types.Pointer("@request.auth.id != '' && owner = @request.auth.id")
+		calendars.UpdateRule = types.Pointer("owner = @request.auth.id")
+		return app.Save(calendars)
+	})
+}
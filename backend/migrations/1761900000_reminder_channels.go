@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add reminder channels, per channel sent state) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		// channels the reminders are delivered through; empty keeps push,
+		// plus email for reminderType email
+		events.Fields.Add(&core.SelectField{
+			Name:      "reminderChannels",
+			MaxSelect: len(calendar.ReminderChannels),
+			Values:    calendar.ReminderChannels,
+		})
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+		// where the webhook channel posts the user's reminders
+		users.Fields.Add(&core.URLField{
+			Name: "reminderWebhook",
+		})
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		sent, err := app.FindCollectionByNameOrId("sent_reminders")
+		if err != nil {
+			return err
+		}
+		// channel the reminder was delivered through; empty for the rows
+		// written before, which stand for every channel
+		sent.Fields.Add(&core.TextField{
+			Name: "channel",
+			Max:  20,
+		})
+		sent.RemoveIndex("idx_sent_reminders_key")
+		sent.AddIndex("idx_sent_reminders_key", true, "event, occurrenceStart, minutes, channel", "")
+		return app.Save(sent)
+	}, func(app core.App) error {
+		// --- DOWN (drop reminder channels) ---
+		sent, err := app.FindCollectionByNameOrId("sent_reminders")
+		if err != nil {
+			return err
+		}
+		// keep one row per reminder for the old unique key
+		if _, err := app.DB().NewQuery("DELETE FROM sent_reminders WHERE rowid NOT IN " +
+			"(SELECT MIN(rowid) FROM sent_reminders GROUP BY event, occurrenceStart, minutes)").Execute(); err != nil {
+			return err
+		}
+		sent.RemoveIndex("idx_sent_reminders_key")
+		sent.AddIndex("idx_sent_reminders_key", true, "event, occurrenceStart, minutes", "")
+		sent.Fields.RemoveByName("channel")
+		if err := app.Save(sent); err != nil {
+			return err
+		}
+
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("reminderWebhook")
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.Fields.RemoveByName("reminderChannels")
+		return app.Save(events)
+	})
+}
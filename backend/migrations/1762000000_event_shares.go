@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// sharedWith matches the events shared with the requesting user; further
+// conditions on @collection.event_shares apply to the same share.
+const sharedWith = "@collection.event_shares.event ?= id && @collection.event_shares.user ?= @request.auth.id"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (create event_shares, open events to owners and shares) ---
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+
+		collection := core.NewBaseCollection(calendar.EventSharesCollection)
+
+		// owners grant and revoke through /api/schedule/events/{id}/shares,
+		// both sides can see them
+		collection.ListRule = types.Pointer("@request.auth.id != '' && (event.owner = @request.auth.id || user = @request.auth.id)")
+		collection.ViewRule = types.Pointer("@request.auth.id != '' && (event.owner = @request.auth.id || user = @request.auth.id)")
+
+		collection.Fields.Add(
+			&core.RelationField{
+				Name:          "event",
+				CollectionId:  events.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.RelationField{
+				Name:          "user",
+				CollectionId:  users.Id,
+				MaxSelect:     1,
+				Required:      true,
+				CascadeDelete: true,
+			},
+			&core.SelectField{
+				Name:      "permission",
+				MaxSelect: 1,
+				Required:  true,
+				Values:    calendar.SharePermissions,
+			},
+			&core.AutodateField{
+				Name:     "created",
+				OnCreate: true,
+			},
+			&core.AutodateField{
+				Name:     "updated",
+				OnCreate: true,
+				OnUpdate: true,
+			},
+		)
+		collection.AddIndex("idx_event_shares_event_user", true, "event, user", "")
+		collection.AddIndex("idx_event_shares_user", false, "user", "")
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+
+		// owners and shared users reach single events through the records
+		// API (unowned events stay superuser-only); owners move them with
+		// /transfer, shared users can't move them to another calendar
+		events.ListRule = types.Pointer("@request.auth.id != '' && (owner = @request.auth.id || (" + sharedWith + "))")
+		events.ViewRule = types.Pointer("@request.auth.id != '' && (owner = @request.auth.id || (" + sharedWith + "))")
+		events.UpdateRule = types.Pointer("@request.auth.id != '' && @request.body.owner:isset = false && (owner = @request.auth.id || (" +
+			sharedWith + " && @collection.event_shares.permission ?= 'write' && @request.body.calendar:isset = false))")
+		return app.Save(events)
+	}, func(app core.App) error {
+		// --- DOWN (close events, drop event_shares) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.ListRule = nil
+		events.ViewRule = nil
+		events.UpdateRule = nil
+		if err := app.Save(events); err != nil {
+			return err
+		}
+
+		coll, err := app.FindCollectionByNameOrId(calendar.EventSharesCollection)
+		if err != nil {
+			return err
+		}
+		return app.Delete(coll)
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add events.sequence) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		// iCalendar SEQUENCE, kept by the server
+		events.Fields.Add(&core.NumberField{
+			Name:    "sequence",
+			OnlyInt: true,
+			Min:     types.Pointer(0.0),
+		})
+		return app.Save(events)
+	}, func(app core.App) error {
+		// --- DOWN ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.Fields.RemoveByName("sequence")
+		return app.Save(events)
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// occurrenceReaders matches the materialized occurrences of the events the
+// requesting user owns or that are shared with them, as the events rules do.
+const occurrenceReaders = "@request.auth.id != '' && (event.owner = @request.auth.id || " +
+	"(@collection.event_shares.event ?= event && @collection.event_shares.user ?= @request.auth.id))"
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (limit materialized_occurrences to the event readers) ---
+		collection, err := app.FindCollectionByNameOrId("materialized_occurrences")
+		if err != nil {
+			return err
+		}
+		collection.ListRule = types.Pointer(occurrenceReaders)
+		collection.ViewRule = types.Pointer(occurrenceReaders)
+		return app.Save(collection)
+	}, func(app core.App) error {
+		// --- DOWN ---
+		collection, err := app.FindCollectionByNameOrId("materialized_occurrences")
+		if err != nil {
+			return err
+		}
+		collection.ListRule = types.Pointer("@request.auth.id != ''")
+		collection.ViewRule = types.Pointer("@request.auth.id != ''")
+		return app.Save(collection)
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (let app users create their events) ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		// the rule is checked before the create hooks run: an empty owner is
+		// then set to the creator (hooks.defaultOwner), an empty calendar to
+		// their default one
+		events.CreateRule = types.Pointer("@request.auth.id != '' && (owner = '' || owner = @request.auth.id) && " +
+			"(calendar = '' || calendar.owner = @request.auth.id)")
+		return app.Save(events)
+	}, func(app core.App) error {
+		// --- DOWN ---
+		events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+		if err != nil {
+			return err
+		}
+		events.CreateRule = nil
+		return app.Save(events)
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"schedule/calendar"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		// --- UP (add users.eventsDeleted) ---
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+		// when one of the user's events was last deleted, for the
+		// Last-Modified of their feeds; kept by the server
+		users.Fields.Add(&core.DateField{
+			Name:   "eventsDeleted",
+			Hidden: true,
+		})
+		return app.Save(users)
+	}, func(app core.App) error {
+		// --- DOWN ---
+		users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+		if err != nil {
+			return err
+		}
+		users.Fields.RemoveByName("eventsDeleted")
+		return app.Save(users)
+	})
+}
@@ -0,0 +1,154 @@
+package recur
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// freqUnits are the singular units of the frequencies, for Describe.
+var freqUnits = map[Frequency]string{
+	Minutely: "minute",
+	Hourly:   "hour",
+	Daily:    "day",
+	Weekly:   "week",
+	Monthly:  "month",
+	Yearly:   "year",
+}
+
+// weekdays are the BYDAY days of "Every weekday".
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// Describe returns the rule in plain English, e.g. "Every weekday", "Every 2
+// weeks on Monday and Thursday" or "Every month on the last Friday, 10
+// times". WKST is left out, it only shifts weeks of multi-week intervals.
+func (r *Rule) Describe() string {
+	var b strings.Builder
+
+	switch {
+	case r.isEveryWeekday():
+		b.WriteString("Every weekday")
+	case r.Freq == Yearly && len(r.ByMonth) == 1 && len(r.ByMonthDay) == 1 && r.ByMonthDay[0] > 0 && len(r.ByDay) == 0 && r.Interval == 1:
+		b.WriteString("Every year on " + r.ByMonth[0].String() + " " + strconv.Itoa(r.ByMonthDay[0]))
+	default:
+		b.WriteString("Every ")
+		if r.Interval > 1 {
+			b.WriteString(strconv.Itoa(r.Interval) + " " + freqUnits[r.Freq] + "s")
+		} else {
+			b.WriteString(freqUnits[r.Freq])
+		}
+		if len(r.ByMonth) > 0 {
+			months := make([]string, len(r.ByMonth))
+			for i, m := range r.ByMonth {
+				months[i] = m.String()
+			}
+			b.WriteString(" in " + joinAnd(months))
+		}
+		if len(r.ByMonthDay) > 0 {
+			days := make([]string, len(r.ByMonthDay))
+			for i, d := range r.ByMonthDay {
+				days[i] = monthDayName(d)
+			}
+			b.WriteString(" on " + joinAnd(days))
+		}
+		if len(r.ByDay) > 0 {
+			days := make([]string, len(r.ByDay))
+			for i, wd := range r.ByDay {
+				days[i] = weekdayNumName(wd)
+			}
+			if len(r.ByMonthDay) > 0 {
+				b.WriteString(" if a " + joinOr(days))
+			} else {
+				b.WriteString(" on " + joinAnd(days))
+			}
+		}
+	}
+
+	switch {
+	case r.Count == 1:
+		b.WriteString(", once")
+	case r.Count > 1:
+		b.WriteString(", " + strconv.Itoa(r.Count) + " times")
+	case !r.Until.IsZero():
+		b.WriteString(", until " + r.Until.Format("January 2, 2006"))
+	}
+	return b.String()
+}
+
+// isEveryWeekday reports whether the rule repeats daily, or weekly, on Monday
+// to Friday and nothing more.
+func (r *Rule) isEveryWeekday() bool {
+	if r.Interval != 1 || (r.Freq != Daily && r.Freq != Weekly) || len(r.ByMonth) > 0 || len(r.ByMonthDay) > 0 ||
+		len(r.ByDay) != len(weekdays) {
+		return false
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 || !slices.Contains(weekdays, wd.Day) {
+			return false
+		}
+	}
+	return true
+}
+
+// weekdayNumName names a BYDAY entry: "Monday", "the second Tuesday", "the
+// last Friday".
+func weekdayNumName(wd WeekdayNum) string {
+	if wd.N == 0 {
+		return wd.Day.String()
+	}
+	return "the " + ordinalName(wd.N) + " " + wd.Day.String()
+}
+
+// monthDayName names a BYMONTHDAY entry: "the 15th", "the last day", "the
+// 2nd to last day".
+func monthDayName(d int) string {
+	switch {
+	case d == -1:
+		return "the last day"
+	case d < 0:
+		return "the " + ordinal(-d) + " to last day"
+	}
+	return "the " + ordinal(d)
+}
+
+// ordinalName spells out the ordinal of BYDAY: "first" to "fifth", "last",
+// "second to last"...
+func ordinalName(n int) string {
+	names := []string{"first", "second", "third", "fourth", "fifth"}
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return names[-n-1] + " to last"
+	}
+	return names[n-1]
+}
+
+// ordinal returns n with its English suffix: 1st, 2nd, 3rd, 4th, 11th, 21st...
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func joinAnd(items []string) string { return joinList(items, "and") }
+
+func joinOr(items []string) string { return joinList(items, "or") }
+
+// joinList joins items as an English list: "a", "a and b", "a, b and c".
+func joinList(items []string, conj string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " " + conj + " " + items[len(items)-1]
+}
@@ -0,0 +1,40 @@
+package recur
+
+import "testing"
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		rule, want string
+	}{
+		{"FREQ=DAILY", "Every day"},
+		{"FREQ=DAILY;INTERVAL=3", "Every 3 days"},
+		{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "Every weekday"},
+		{"FREQ=DAILY;BYDAY=FR,TH,WE,TU,MO", "Every weekday"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU,WE,TH,FR", "Every 2 weeks on Monday, Tuesday, Wednesday, Thursday and Friday"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "Every 2 weeks on Monday and Thursday"},
+		{"FREQ=WEEKLY;BYDAY=SA,SU;WKST=SU", "Every week on Saturday and Sunday"},
+		{"FREQ=HOURLY;INTERVAL=3;COUNT=4", "Every 3 hours, 4 times"},
+		{"FREQ=MONTHLY;BYDAY=-1FR;COUNT=10", "Every month on the last Friday, 10 times"},
+		{"FREQ=MONTHLY;BYDAY=-2MO", "Every month on the second to last Monday"},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", "Every month on the last day"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-3", "Every month on the 1st and the 3rd to last day"},
+		{"FREQ=MONTHLY;BYMONTHDAY=11,22,23", "Every month on the 11th, the 22nd and the 23rd"},
+		{"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", "Every month on the 13th if a Friday"},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=14", "Every year on March 14"},
+		{"FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "Every year in November on the fourth Thursday"},
+		{"FREQ=MONTHLY;BYMONTH=3,6,9,12;BYMONTHDAY=15", "Every month in March, June, September and December on the 15th"},
+		{"FREQ=DAILY;COUNT=1", "Every day, once"},
+		{"FREQ=DAILY;UNTIL=20261231T235959Z", "Every day, until December 31, 2026"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Describe(); got != tt.want {
+				t.Fatalf("Describe = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package recur
+
+import (
+	"sort"
+	"time"
+)
+
+// maxEmptyPeriods bounds how many consecutive periods without a single candidate
+// the iterator tolerates before giving up. It protects against rules that can
+// never match (e.g. BYMONTHDAY=31 for a rule pinned to February) while still
+// allowing sparse ones such as "Monday the 13th".
+const maxEmptyPeriods = 5000
+
+// Iterator yields the occurrence instants of a rule in chronological order.
+//
+// Instants keep the wall-clock time of dtstart in dtstart's location, so a
+// 09:00 Europe/Berlin weekly event stays at 09:00 across DST transitions.
+type Iterator struct {
+	rule    *Rule
+	start   time.Time
+	until   time.Time
+	period  int
+	buf     []time.Time
+	emitted int
+	empty   int
+	done    bool
+}
+
+// Iter returns an iterator over the occurrences of r anchored at dtstart.
+func (r *Rule) Iter(dtstart time.Time) *Iterator {
+	return &Iterator{
+		rule:  r,
+		start: dtstart,
+		until: r.until(dtstart.Location()),
+	}
+}
+
+// Next returns the next occurrence, or false once the rule is exhausted.
+func (it *Iterator) Next() (time.Time, bool) {
+	if it.done {
+		return time.Time{}, false
+	}
+
+	for len(it.buf) == 0 {
+		if it.empty > maxEmptyPeriods {
+			it.done = true
+			return time.Time{}, false
+		}
+		it.buf = it.candidates(it.period)
+		it.period++
+		if len(it.buf) == 0 && it.rule.step() > 0 {
+			it.skipDay()
+		}
+
+		// candidates before dtstart never count as occurrences
+		for len(it.buf) > 0 && it.buf[0].Before(it.start) {
+			it.buf = it.buf[1:]
+		}
+		if len(it.buf) == 0 {
+			it.empty++
+		} else {
+			it.empty = 0
+		}
+	}
+
+	t := it.buf[0]
+	it.buf = it.buf[1:]
+
+	if !it.until.IsZero() && t.After(it.until) {
+		it.done = true
+		return time.Time{}, false
+	}
+
+	it.emitted++
+	if it.rule.Count > 0 && it.emitted >= it.rule.Count {
+		it.done = true
+	}
+
+	return t, true
+}
+
+// Seek advances the iterator close to (but never past) t, so expanding a
+// window far from dtstart doesn't walk every earlier period. It is a no-op for
+// COUNT rules because their occurrences must be counted from the start.
+func (it *Iterator) Seek(t time.Time) {
+	if it.rule.Count > 0 || !t.After(it.start) {
+		return
+	}
+
+	loc := it.start.Location()
+	t = t.In(loc)
+	s := it.start
+
+	var n int
+	switch it.rule.Freq {
+	case Daily:
+		n = daysBetween(s, t) / it.rule.Interval
+	case Weekly:
+		n = daysBetween(s, t) / 7 / it.rule.Interval
+	case Monthly:
+		n = ((t.Year()-s.Year())*12 + int(t.Month()-s.Month())) / it.rule.Interval
+	case Yearly:
+		n = (t.Year() - s.Year()) / it.rule.Interval
+	case Hourly, Minutely:
+		n = int(t.Sub(s) / it.rule.step())
+	}
+
+	// step back one period so occurrences at the boundary are not skipped
+	if n--; n > it.period {
+		it.period = n
+		it.buf = nil
+	}
+}
+
+// Between calls fn for each occurrence in [from, to) in chronological order,
+// stopping early when fn returns false.
+func (r *Rule) Between(dtstart, from, to time.Time, fn func(time.Time) bool) {
+	it := r.Iter(dtstart)
+	it.Seek(from)
+	for {
+		t, ok := it.Next()
+		if !ok || !t.Before(to) {
+			return
+		}
+		if t.Before(from) {
+			continue
+		}
+		if !fn(t) {
+			return
+		}
+	}
+}
+
+// All returns up to limit occurrences starting at dtstart.
+func (r *Rule) All(dtstart time.Time, limit int) []time.Time {
+	var out []time.Time
+	it := r.Iter(dtstart)
+	for len(out) < limit {
+		t, ok := it.Next()
+		if !ok {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// candidates returns the sorted occurrence candidates of the k-th period.
+func (it *Iterator) candidates(k int) []time.Time {
+	r := it.rule
+	s := it.start
+	loc := s.Location()
+	y, m, d := s.Date()
+	hh, mm, ss := s.Clock()
+
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, hh, mm, ss, 0, loc)
+	}
+
+	var out []time.Time
+
+	switch r.Freq {
+	case Hourly, Minutely:
+		// sub-daily instances are a fixed elapsed time apart, so DST changes
+		// neither skip nor repeat one
+		t := s.Add(time.Duration(k) * r.step())
+		if r.matchesDay(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)) {
+			out = append(out, t)
+		}
+
+	case Daily:
+		day := time.Date(y, m, d+k*r.Interval, 0, 0, 0, 0, time.UTC)
+		if r.matchesDay(day) {
+			out = append(out, at(day.Date()))
+		}
+
+	case Weekly:
+		offset := (int(s.Weekday()) - int(r.WeekStart) + 7) % 7
+		first := time.Date(y, m, d-offset+7*k*r.Interval, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 7; i++ {
+			day := first.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 {
+				if day.Weekday() != s.Weekday() {
+					continue
+				}
+			} else if !r.hasWeekday(day.Weekday()) {
+				continue
+			}
+			if len(r.ByMonthDay) > 0 && !r.hasMonthDay(day) {
+				continue
+			}
+			if !r.hasMonth(day.Month()) {
+				continue
+			}
+			out = append(out, at(day.Date()))
+		}
+
+	case Monthly:
+		month := time.Date(y, m+time.Month(k*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		if !r.hasMonth(month.Month()) {
+			break
+		}
+		for _, day := range r.daysInMonth(month, d) {
+			out = append(out, at(month.Year(), month.Month(), day))
+		}
+
+	case Yearly:
+		// BYMONTH expands a yearly rule to each listed month; without it the
+		// rule stays in the month of dtstart.
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{m}
+		}
+		for _, mo := range months {
+			month := time.Date(y+k*r.Interval, mo, 1, 0, 0, 0, 0, time.UTC)
+			for _, day := range r.daysInMonth(month, d) {
+				out = append(out, at(month.Year(), month.Month(), day))
+			}
+		}
+	}
+
+	return out
+}
+
+// skipDay moves a sub-daily iterator whose last period was filtered out to
+// the first period of the next day, so filters like BYDAY=MO cost one empty
+// period per day rather than one per instance.
+func (it *Iterator) skipDay() {
+	last := it.start.Add(time.Duration(it.period-1) * it.rule.step())
+	y, m, d := last.Date()
+	next := time.Date(y, m, d+1, 0, 0, 0, 0, last.Location())
+	if k := int((next.Sub(it.start) + it.rule.step() - 1) / it.rule.step()); k > it.period {
+		it.period = k
+	}
+}
+
+// daysInMonth expands BYMONTHDAY/BYDAY within the month of first. Without
+// either, the day of month of dtstart (anchorDay) is used and months that are
+// too short are skipped, matching RFC 5545: a Feb 29 anniversary only occurs
+// in leap years, it never rolls over to Mar 1.
+func (r *Rule) daysInMonth(first time.Time, anchorDay int) []int {
+	n := daysIn(first.Year(), first.Month())
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if anchorDay > n {
+			return nil
+		}
+		return []int{anchorDay}
+	}
+
+	var out []int
+	for day := 1; day <= n; day++ {
+		date := time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+		if len(r.ByMonthDay) > 0 && !r.hasMonthDay(date) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesOrdinalDay(date, n) {
+			continue
+		}
+		out = append(out, day)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// matchesDay applies the BYDAY and BYMONTHDAY filters to a single day.
+func (r *Rule) matchesDay(day time.Time) bool {
+	if len(r.ByDay) > 0 && !r.hasWeekday(day.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !r.hasMonthDay(day) {
+		return false
+	}
+	return r.hasMonth(day.Month())
+}
+
+// hasMonth applies the BYMONTH filter; every month matches without it.
+func (r *Rule) hasMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) hasWeekday(wd time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d.Day == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMonthDay applies the BYMONTHDAY filter. Negative days count from the end
+// of the month, -1 being its last day: Feb 28 or 29, Apr 30, Jan 31.
+func (r *Rule) hasMonthDay(day time.Time) bool {
+	n := daysIn(day.Year(), day.Month())
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || (d < 0 && n+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesOrdinalDay reports whether date matches a BYDAY entry of a month with
+// n days, honouring ordinals such as "2TU" or "-1FR".
+func (r *Rule) matchesOrdinalDay(date time.Time, n int) bool {
+	for _, d := range r.ByDay {
+		if d.Day != date.Weekday() {
+			continue
+		}
+		switch {
+		case d.N == 0:
+			return true
+		case d.N > 0 && (date.Day()-1)/7+1 == d.N:
+			return true
+		case d.N < 0 && (n-date.Day())/7+1 == -d.N:
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// daysBetween counts calendar days between the dates of a and b (b >= a).
+func daysBetween(a, b time.Time) int {
+	ad := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	bd := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(bd.Sub(ad).Hours() / 24)
+}
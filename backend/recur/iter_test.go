@@ -0,0 +1,258 @@
+package recur
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// dates expands rule from dtstart into the first n instance dates.
+func dates(t *testing.T, rule string, dtstart time.Time, n int) []string {
+	t.Helper()
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, at := range r.All(dtstart, n) {
+		out = append(out, at.Format("2006-01-02"))
+	}
+	return out
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestByMonth(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "anniversary",
+			rule:    "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=14",
+			dtstart: day(2026, 3, 14),
+			want:    []string{"2026-03-14", "2027-03-14", "2028-03-14", "2029-03-14"},
+		},
+		{
+			name:    "leap day anniversary skips common years",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			dtstart: day(2024, 2, 29),
+			want:    []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:    "leap day anniversary without BY parts",
+			rule:    "FREQ=YEARLY",
+			dtstart: day(2024, 2, 29),
+			want:    []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+		{
+			name:    "yearly on several months",
+			rule:    "FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=1",
+			dtstart: day(2026, 1, 1),
+			want:    []string{"2026-01-01", "2026-07-01", "2027-01-01", "2027-07-01"},
+		},
+		{
+			name:    "fourth Thursday of November",
+			rule:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			dtstart: day(2026, 11, 26),
+			want:    []string{"2026-11-26", "2027-11-25", "2028-11-23"},
+		},
+		{
+			name:    "last Monday of May",
+			rule:    "FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO",
+			dtstart: day(2026, 5, 25),
+			want:    []string{"2026-05-25", "2027-05-31", "2028-05-29"},
+		},
+		{
+			name:    "every weekday of a month",
+			rule:    "FREQ=YEARLY;BYMONTH=8;BYDAY=SA,SU",
+			dtstart: day(2026, 8, 1),
+			want:    []string{"2026-08-01", "2026-08-02", "2026-08-08", "2026-08-09", "2026-08-15"},
+		},
+		{
+			name:    "monthly limited to some months",
+			rule:    "FREQ=MONTHLY;BYMONTH=3,6,9,12;BYMONTHDAY=15",
+			dtstart: day(2026, 1, 15),
+			want:    []string{"2026-03-15", "2026-06-15", "2026-09-15", "2026-12-15", "2027-03-15"},
+		},
+		{
+			name:    "monthly ordinal weekday in some months",
+			rule:    "FREQ=MONTHLY;BYMONTH=1,4;BYDAY=1MO",
+			dtstart: day(2026, 1, 5),
+			want:    []string{"2026-01-05", "2026-04-06", "2027-01-04", "2027-04-05"},
+		},
+		{
+			name:    "weekly in December only",
+			rule:    "FREQ=WEEKLY;BYMONTH=12;BYDAY=MO",
+			dtstart: day(2026, 11, 23),
+			want:    []string{"2026-12-07", "2026-12-14", "2026-12-21", "2026-12-28", "2027-12-06"},
+		},
+		{
+			name:    "daily in February",
+			rule:    "FREQ=DAILY;BYMONTH=2",
+			dtstart: day(2027, 2, 26),
+			want:    []string{"2027-02-26", "2027-02-27", "2027-02-28", "2028-02-01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dates(t, tt.rule, tt.dtstart, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Fatalf("dates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Negative BYMONTHDAY counts from the end of each month, whatever its
+// length. A day some months don't have is skipped there.
+func TestNegativeMonthDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "last day through a common year",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: day(2027, 1, 31),
+			want: []string{
+				"2027-01-31", "2027-02-28", "2027-03-31", "2027-04-30", "2027-05-31", "2027-06-30",
+				"2027-07-31", "2027-08-31", "2027-09-30", "2027-10-31", "2027-11-30", "2027-12-31",
+			},
+		},
+		{
+			name:    "last day through a leap February",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: day(2028, 1, 31),
+			want:    []string{"2028-01-31", "2028-02-29", "2028-03-31", "2028-04-30"},
+		},
+		{
+			name:    "last day of February across leap years",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			dtstart: day(2027, 2, 28),
+			want:    []string{"2027-02-28", "2028-02-29", "2029-02-28"},
+		},
+		{
+			name:    "last day of February in a century year",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			dtstart: day(2099, 2, 28),
+			want:    []string{"2099-02-28", "2100-02-28", "2101-02-28"},
+		},
+		{
+			name:    "second to last day",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-2",
+			dtstart: day(2028, 1, 30),
+			want:    []string{"2028-01-30", "2028-02-28", "2028-03-30", "2028-04-29"},
+		},
+		{
+			name:    "-31 is the 1st of 31-day months only",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-31",
+			dtstart: day(2028, 1, 1),
+			want:    []string{"2028-01-01", "2028-03-01", "2028-05-01", "2028-07-01", "2028-08-01", "2028-10-01"},
+		},
+		{
+			name:    "-29 exists in a leap February",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-29",
+			dtstart: day(2024, 2, 1),
+			want:    []string{"2024-02-01", "2028-02-01", "2032-02-01"},
+		},
+		{
+			name:    "first and last day",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=1,-1",
+			dtstart: day(2027, 2, 1),
+			want:    []string{"2027-02-01", "2027-02-28", "2027-03-01", "2027-03-31", "2027-04-01", "2027-04-30"},
+		},
+		{
+			name:    "positive and negative day on the same date",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=30,-1",
+			dtstart: day(2027, 4, 30),
+			want:    []string{"2027-04-30", "2027-05-30", "2027-05-31", "2027-06-30", "2027-07-30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dates(t, tt.rule, tt.dtstart, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Fatalf("dates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubDaily(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := func(rule string, dtstart time.Time, n int) []string {
+		r, err := Parse(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, at := range r.All(dtstart, n) {
+			out = append(out, at.UTC().Format("01-02 15:04"))
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		n       int
+		want    []string
+	}{
+		{
+			name:    "every two hours, five times",
+			rule:    "FREQ=HOURLY;INTERVAL=2;COUNT=5",
+			dtstart: time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+			n:       10,
+			want:    []string{"03-10 08:00", "03-10 10:00", "03-10 12:00", "03-10 14:00", "03-10 16:00"},
+		},
+		{
+			name:    "every 90 minutes until noon",
+			rule:    "FREQ=MINUTELY;INTERVAL=90;UNTIL=20260310T120000Z",
+			dtstart: time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC),
+			n:       10,
+			want:    []string{"03-10 06:00", "03-10 07:30", "03-10 09:00", "03-10 10:30", "03-10 12:00"},
+		},
+		{
+			// elapsed hours: the DST change neither skips nor repeats one
+			name:    "hourly across spring forward",
+			rule:    "FREQ=HOURLY;COUNT=4",
+			dtstart: time.Date(2026, 3, 29, 1, 0, 0, 0, berlin),
+			n:       4,
+			want:    []string{"03-29 00:00", "03-29 01:00", "03-29 02:00", "03-29 03:00"},
+		},
+		{
+			name:    "hourly on Mondays only",
+			rule:    "FREQ=HOURLY;INTERVAL=12;BYDAY=MO",
+			dtstart: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+			n:       4,
+			want:    []string{"03-09 00:00", "03-09 12:00", "03-16 00:00", "03-16 12:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := times(tt.rule, tt.dtstart, tt.n); !slices.Equal(got, tt.want) {
+				t.Fatalf("instances = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Seek lands on the instances of a far window without walking to it
+	r, _ := Parse("FREQ=MINUTELY;INTERVAL=90;UNTIL=20300101T000000Z")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var got []string
+	r.Between(start, time.Date(2029, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2029, 6, 1, 4, 0, 0, 0, time.UTC), func(at time.Time) bool {
+		got = append(got, at.Format("15:04"))
+		return true
+	})
+	if want := []string{"00:00", "01:30", "03:00"}; !slices.Equal(got, want) {
+		t.Fatalf("Between = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,331 @@
+// Package recur parses RFC 5545 RRULE strings and expands them into concrete
+// occurrence instants.
+//
+// Only the subset of the spec the calendar UI can produce is supported. Anything
+// else is rejected by Parse, so save-time validation and expansion always agree.
+package recur
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a rule.
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+	Hourly
+	Minutely
+)
+
+var freqNames = map[string]Frequency{
+	"MINUTELY": Minutely,
+	"HOURLY":   Hourly,
+	"DAILY":    Daily,
+	"WEEKLY":   Weekly,
+	"MONTHLY":  Monthly,
+	"YEARLY":   Yearly,
+}
+
+// step is the period of sub-daily frequencies, zero for the others.
+func (r *Rule) step() time.Duration {
+	switch r.Freq {
+	case Hourly:
+		return time.Duration(r.Interval) * time.Hour
+	case Minutely:
+		return time.Duration(r.Interval) * time.Minute
+	}
+	return 0
+}
+
+func (f Frequency) String() string {
+	for name, v := range freqNames {
+		if v == f {
+			return name
+		}
+	}
+	return "UNKNOWN"
+}
+
+var dayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// DayCode returns the two letter RRULE code of a weekday (e.g. "MO").
+func DayCode(d time.Weekday) string {
+	return strings.ToUpper(d.String()[:2])
+}
+
+// WeekdayNum is a BYDAY entry. N is the optional ordinal ("2TU", "-1FR");
+// zero means every matching weekday of the period.
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return DayCode(w.Day)
+	}
+	return strconv.Itoa(w.N) + DayCode(w.Day)
+}
+
+// Rule is a parsed RRULE.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+
+	// untilFloating is set when UNTIL had no zone designator and must be
+	// interpreted in the location of dtstart.
+	untilFloating bool
+}
+
+// ErrEmpty is returned by Parse for a blank rule.
+var ErrEmpty = errors.New("recur: empty rule")
+
+// Parse parses an RRULE value. A leading "RRULE:" prefix is accepted, as are
+// multi-line values where the RRULE is preceded by a DTSTART line (the DTSTART is
+// ignored because the event start is the source of truth).
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToUpper(line), "RRULE:") {
+			s = line[len("RRULE:"):]
+			break
+		}
+	}
+	if s == "" {
+		return nil, ErrEmpty
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+	var until string
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("recur: malformed part %q", part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+
+		switch key {
+		case "FREQ":
+			f, ok := freqNames[val]
+			if !ok {
+				return nil, fmt.Errorf("recur: unsupported FREQ %q", val)
+			}
+			r.Freq = f
+			hasFreq = true
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("recur: invalid INTERVAL %q", val)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("recur: invalid COUNT %q", val)
+			}
+			r.Count = n
+		case "UNTIL":
+			until = val
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(d)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("recur: invalid BYMONTHDAY %q", d)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, m := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(m)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("recur: invalid BYMONTH %q", m)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+			sort.Slice(r.ByMonth, func(i, j int) bool { return r.ByMonth[i] < r.ByMonth[j] })
+		case "WKST":
+			d, ok := dayCodes[val]
+			if !ok {
+				return nil, fmt.Errorf("recur: invalid WKST %q", val)
+			}
+			r.WeekStart = d
+		default:
+			return nil, fmt.Errorf("recur: unsupported part %q", key)
+		}
+	}
+
+	if !hasFreq {
+		return nil, errors.New("recur: missing FREQ")
+	}
+
+	if until != "" {
+		t, floating, err := parseUntil(until)
+		if err != nil {
+			return nil, err
+		}
+		r.Until = t
+		r.untilFloating = floating
+	}
+
+	// an open-ended rule repeating more often than hourly yields far too many
+	// instances for any window worth expanding
+	if step := r.step(); step > 0 && step < time.Hour && r.IsInfinite() {
+		return nil, errors.New("recur: a rule repeating more often than hourly needs COUNT or UNTIL")
+	}
+
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("recur: ordinal BYDAY %q requires FREQ=MONTHLY or YEARLY", wd)
+		}
+	}
+
+	return r, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("recur: invalid BYDAY %q", s)
+	}
+	day, ok := dayCodes[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("recur: invalid BYDAY %q", s)
+	}
+	wd := WeekdayNum{Day: day}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("recur: invalid BYDAY %q", s)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// parseUntil accepts the three UNTIL shapes seen in the wild: UTC date-time
+// ("20250101T090000Z"), floating date-time ("20250101T090000") and date only
+// ("20250101"). Date only values are inclusive of the whole day.
+func parseUntil(s string) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102T150405Z", s); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("20060102T150405", s); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse("20060102", s); err == nil {
+		return t.Add(24*time.Hour - time.Second), true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("recur: invalid UNTIL %q", s)
+}
+
+// UntilIn returns the UNTIL bound resolved against loc, the dtstart location:
+// an UNTIL without zone designator is a wall clock time there. Zero without
+// UNTIL.
+func (r *Rule) UntilIn(loc *time.Location) time.Time {
+	return r.until(loc)
+}
+
+// until returns the UNTIL bound resolved against loc (the dtstart location).
+func (r *Rule) until(loc *time.Location) time.Time {
+	if r.Until.IsZero() || !r.untilFloating {
+		return r.Until
+	}
+	u := r.Until
+	return time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+}
+
+// EndBefore returns a copy of the rule ending just before t: COUNT is dropped
+// and UNTIL becomes t minus one second, in UTC.
+func (r *Rule) EndBefore(t time.Time) *Rule {
+	c := *r
+	c.Count = 0
+	c.Until = t.Add(-time.Second).UTC().Truncate(time.Second)
+	c.untilFloating = false
+	return &c
+}
+
+// IsInfinite reports whether the rule has neither COUNT nor UNTIL.
+func (r *Rule) IsInfinite() bool {
+	return r.Count == 0 && r.Until.IsZero()
+}
+
+// String formats the rule back into RRULE value syntax (without the "RRULE:" prefix).
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq.String()}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = d.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = strconv.Itoa(int(m))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+DayCode(r.WeekStart))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.untilFloating {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
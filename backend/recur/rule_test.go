@@ -0,0 +1,125 @@
+package recur
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rule
+	}{
+		{"FREQ=DAILY", Rule{Freq: Daily, Interval: 1, WeekStart: time.Monday}},
+		{"freq=weekly;interval=2", Rule{Freq: Weekly, Interval: 2, WeekStart: time.Monday}},
+		{"RRULE:FREQ=MONTHLY;COUNT=10", Rule{Freq: Monthly, Interval: 1, Count: 10, WeekStart: time.Monday}},
+		{"DTSTART:20260310T090000Z\nRRULE:FREQ=YEARLY", Rule{Freq: Yearly, Interval: 1, WeekStart: time.Monday}},
+		{"FREQ=HOURLY;INTERVAL=3", Rule{Freq: Hourly, Interval: 3, WeekStart: time.Monday}},
+		{"FREQ=MINUTELY;INTERVAL=15;COUNT=4", Rule{Freq: Minutely, Interval: 15, Count: 4, WeekStart: time.Monday}},
+		{
+			"FREQ=DAILY;UNTIL=20260315T090000Z",
+			Rule{Freq: Daily, Interval: 1, Until: time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC), WeekStart: time.Monday},
+		},
+		{
+			"FREQ=DAILY;UNTIL=20260315T090000",
+			Rule{Freq: Daily, Interval: 1, Until: time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC), WeekStart: time.Monday, untilFloating: true},
+		},
+		{
+			"FREQ=DAILY;UNTIL=20260315",
+			Rule{Freq: Daily, Interval: 1, Until: time.Date(2026, 3, 15, 23, 59, 59, 0, time.UTC), WeekStart: time.Monday, untilFloating: true},
+		},
+		{
+			"FREQ=WEEKLY;BYDAY=MO,WE,FR;WKST=SU",
+			Rule{Freq: Weekly, Interval: 1, ByDay: []WeekdayNum{{Day: time.Monday}, {Day: time.Wednesday}, {Day: time.Friday}}, WeekStart: time.Sunday},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=2TU,-1FR",
+			Rule{Freq: Monthly, Interval: 1, ByDay: []WeekdayNum{{N: 2, Day: time.Tuesday}, {N: -1, Day: time.Friday}}, WeekStart: time.Monday},
+		},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", Rule{Freq: Monthly, Interval: 1, ByMonthDay: []int{1, -1}, WeekStart: time.Monday}},
+		{"FREQ=YEARLY;BYMONTH=12,3", Rule{Freq: Yearly, Interval: 1, ByMonth: []time.Month{time.March, time.December}, WeekStart: time.Monday}},
+		{"FREQ=DAILY;;COUNT=2;", Rule{Freq: Daily, Interval: 1, Count: 2, WeekStart: time.Monday}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("Parse = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"   ",
+		"COUNT=3",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;UNTIL=2026-03-15",
+		"FREQ=DAILY;COUNT",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=M",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=-32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=WEEKLY;WKST=XY",
+		"FREQ=MINUTELY",
+		"FREQ=MINUTELY;INTERVAL=30",
+	} {
+		if r, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", in, r)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;WKST=SU;COUNT=8",
+		"FREQ=MONTHLY;BYDAY=-1FR",
+		"FREQ=MONTHLY;BYMONTHDAY=-1;UNTIL=20261231T235959Z",
+		"FREQ=YEARLY;BYMONTHDAY=14;BYMONTH=3",
+		"FREQ=HOURLY;INTERVAL=2;UNTIL=20260315T090000",
+	} {
+		r, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.String(); got != in {
+			t.Errorf("Parse(%q).String() = %q", in, got)
+		}
+	}
+}
+
+func TestUntilIn(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utcRule, _ := Parse("FREQ=DAILY;UNTIL=20260315T090000Z")
+	floating, _ := Parse("FREQ=DAILY;UNTIL=20260315T090000")
+	open, _ := Parse("FREQ=DAILY")
+
+	if got, want := utcRule.UntilIn(tokyo), time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("UTC UNTIL in Tokyo = %v, want %v", got, want)
+	}
+	if got, want := floating.UntilIn(tokyo), time.Date(2026, 3, 15, 9, 0, 0, 0, tokyo); !got.Equal(want) {
+		t.Errorf("floating UNTIL in Tokyo = %v, want %v", got, want)
+	}
+	if got := open.UntilIn(tokyo); !got.IsZero() {
+		t.Errorf("UntilIn without UNTIL = %v", got)
+	}
+}
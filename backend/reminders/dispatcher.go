@@ -0,0 +1,281 @@
+// Package reminders delivers the reminders (reminderMinutes) of upcoming
+// occurrences to their owners.
+package reminders
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Reminder is one reminder due for delivery.
+type Reminder struct {
+	Occurrence calendar.Occurrence
+	EventID    string
+	Owner      string
+	Minutes    int
+	Type       string // the event reminderType
+	// Attempt is 1 for the reminder itself and counts up for escalation
+	// follow-ups.
+	Attempt int
+	// Escalate is the follow-up interval of the event, zero when it doesn't
+	// escalate.
+	Escalate time.Duration
+	// Channels are the names of the channels delivering the reminder, see
+	// calendar.Event.Channels.
+	Channels []string
+}
+
+// Channel delivers reminders to their owner, e.g. through Web Push.
+type Channel interface {
+	// Name is the reminderChannels value picking the channel.
+	Name() string
+	Deliver(app core.App, r Reminder) error
+}
+
+// maxChannelAttempts is how many times a channel tries to deliver a reminder.
+const maxChannelAttempts = 3
+
+// dispatcher checks every minute for reminders that became due since the
+// previous run. Of the reminders due while the server was down, only those
+// within cfg.ReminderGrace are sent on startup (see catchUp), and ones
+// recorded in sent_reminders are never sent twice. Each channel of a
+// reminder is recorded on its own, so a channel failing doesn't hold the
+// others back: it is retried on the next ticks while the reminder is at
+// most cfg.ReminderGrace late. Follow-ups of escalating events are tracked
+// in reminder_state (see escalate).
+type dispatcher struct {
+	app      core.App
+	cfg      *config.Config
+	channels []Channel
+
+	mu      sync.Mutex
+	last    time.Time
+	started bool // the missed reminders were reported
+	retries []retry
+}
+
+// retry is a failed delivery of a reminder through one channel.
+type retry struct {
+	r        Reminder
+	channel  Channel
+	attempts int
+}
+
+// Register schedules the reminder dispatcher with the channels enabled by cfg.
+// Email reminders are always on; they go through the mail queue.
+func Register(app core.App, cfg *config.Config) {
+	// the first tick also covers the grace window before startup
+	d := &dispatcher{app: app, cfg: cfg, last: time.Now().Add(-cfg.ReminderGrace)}
+
+	d.channels = append(d.channels, emailChannel{cfg: cfg}, newWebhookChannel(cfg))
+	if cfg.PushEnabled() {
+		d.channels = append(d.channels, &pushChannel{cfg: cfg})
+	}
+
+	app.Cron().MustAdd("scheduleReminders", "* * * * *", d.tick)
+}
+
+func (d *dispatcher) tick() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if !d.started {
+		d.started = true
+		if err := d.reportMissed(d.last); err != nil {
+			d.app.Logger().Error("reminders missed check failed", "error", err)
+		}
+	}
+	d.retry(now)
+	if err := d.dispatch(d.last, now); err != nil {
+		d.app.Logger().Error("reminders dispatch failed", "error", err)
+		return // retried with the same window on the next tick
+	}
+	d.last = now
+
+	if err := d.escalate(now); err != nil {
+		d.app.Logger().Error("reminders escalation failed", "error", err)
+	}
+}
+
+// deliver sends an escalation follow-up through the channels of r. Follow-ups
+// aren't recorded per channel, nor retried: the next one is on its way.
+func (d *dispatcher) deliver(r Reminder) {
+	for _, ch := range d.channels {
+		if !slices.Contains(r.Channels, ch.Name()) {
+			continue
+		}
+		if err := ch.Deliver(d.app, r); err != nil {
+			d.app.Logger().Warn("reminder delivery failed", "event", r.EventID, "start", r.Occurrence.Start, "attempt", r.Attempt, "channel", ch.Name(), "error", err)
+		}
+	}
+}
+
+// deliverOnce sends r through those of its channels not in sent, recording
+// each channel that delivered it, and reports whether any did. Failed
+// channels are queued for a retry.
+func (d *dispatcher) deliverOnce(r Reminder, sent map[string]bool) (bool, error) {
+	delivered := false
+	for _, ch := range d.channels {
+		if !slices.Contains(r.Channels, ch.Name()) || sent[""] || sent[ch.Name()] || d.queued(r, ch) {
+			continue
+		}
+		if err := ch.Deliver(d.app, r); err != nil {
+			d.app.Logger().Warn("reminder delivery failed", "event", r.EventID, "start", r.Occurrence.Start, "channel", ch.Name(), "error", err)
+			d.retries = append(d.retries, retry{r: r, channel: ch, attempts: 1})
+			continue
+		}
+		if err := markSent(d.app, r, ch.Name()); err != nil {
+			return delivered, err
+		}
+		delivered = true
+	}
+	return delivered, nil
+}
+
+// queued reports whether the delivery of r through ch awaits a retry, when a
+// dispatch runs again over the same window.
+func (d *dispatcher) queued(r Reminder, ch Channel) bool {
+	return slices.ContainsFunc(d.retries, func(p retry) bool {
+		return p.channel == ch && p.r.EventID == r.EventID && p.r.Minutes == r.Minutes && p.r.Occurrence.Start.Equal(r.Occurrence.Start)
+	})
+}
+
+// retry delivers the queued failed deliveries again, dropping those out of
+// attempts or more than cfg.ReminderGrace late. Deliveries still failing
+// after a restart are picked up by the catch-up of the first tick instead.
+func (d *dispatcher) retry(now time.Time) {
+	pending := d.retries
+	d.retries = nil
+	for _, p := range pending {
+		r := p.r
+		fireAt := r.Occurrence.Start.Add(-time.Duration(r.Minutes) * time.Minute)
+		if p.attempts >= maxChannelAttempts || now.Sub(fireAt) > d.cfg.ReminderGrace {
+			d.app.Logger().Error("reminder delivery given up", "event", r.EventID, "start", r.Occurrence.Start, "channel", p.channel.Name(), "attempts", p.attempts)
+			continue
+		}
+		if err := p.channel.Deliver(d.app, r); err != nil {
+			d.app.Logger().Warn("reminder delivery failed", "event", r.EventID, "start", r.Occurrence.Start, "channel", p.channel.Name(), "error", err)
+			p.attempts++
+			d.retries = append(d.retries, p)
+			continue
+		}
+		if err := markSent(d.app, r, p.channel.Name()); err != nil {
+			d.app.Logger().Error("reminder not recorded as sent", "event", r.EventID, "channel", p.channel.Name(), "error", err)
+		}
+		d.startEscalation(r, now)
+	}
+}
+
+// startEscalation schedules the follow-ups of r once a channel delivered it;
+// a reminder every channel failed to deliver is retried first. Later
+// deliveries through other channels keep the schedule already started.
+func (d *dispatcher) startEscalation(r Reminder, now time.Time) {
+	if r.Escalate <= 0 {
+		return
+	}
+	if err := startEscalation(d.app, r, now); err != nil {
+		d.app.Logger().Warn("reminder escalation not scheduled", "event", r.EventID, "start", r.Occurrence.Start, "error", err)
+	}
+}
+
+// dispatch delivers the reminders whose trigger time falls in [from, to).
+func (d *dispatcher) dispatch(from, to time.Time) error {
+	due, err := Due(d.app, d.cfg, from, to)
+	if err != nil {
+		return err
+	}
+	for _, r := range due {
+		sent, err := sentChannels(d.app, r)
+		if err != nil {
+			return err
+		}
+		delivered, err := d.deliverOnce(r, sent)
+		if err != nil {
+			return err
+		}
+		if delivered {
+			d.startEscalation(r, to)
+		}
+	}
+	return nil
+}
+
+// Due lists the reminders triggering in [from, to): occurrences starting in
+// the window shifted by each reminder lead time. Events without a time zone,
+// and floating ones, occur in their owner's zone (cfg.Timezone when unset),
+// as the owner's calendar shows them.
+func Due(app core.App, cfg *config.Config, from, to time.Time) ([]Reminder, error) {
+	events, err := calendar.FindReminderEvents(app, from, to.Add(cfg.Horizon))
+	if err != nil {
+		return nil, err
+	}
+
+	zones := map[string]*time.Location{}
+	var out []Reminder
+	for _, ev := range events {
+		loc, ok := zones[ev.Owner]
+		if !ok {
+			loc = OwnerZone(app, cfg, ev.Owner)
+			zones[ev.Owner] = loc
+		}
+		for _, m := range ev.ReminderMinutes {
+			lead := time.Duration(m) * time.Minute
+			for _, o := range ev.Occurrences(from.Add(lead), to.Add(lead), loc) {
+				// Occurrences also returns the ones overlapping the window start
+				if o.Start.Before(from.Add(lead)) {
+					continue
+				}
+				r := Reminder{Occurrence: o, EventID: ev.ID, Owner: ev.Owner, Minutes: m, Type: ev.ReminderType, Attempt: 1, Channels: ev.Channels()}
+				if ev.Escalates() {
+					r.Escalate = ev.EscalateEvery
+				}
+				out = append(out, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+// OwnerZone is the zone the events of owner are expanded in for their
+// reminders, that of the owner's calendar (see calendar.UserZone).
+func OwnerZone(app core.App, cfg *config.Config, owner string) *time.Location {
+	rec, _ := app.FindRecordById(calendar.UsersCollection, owner)
+	return calendar.UserZone(rec, cfg.Timezone)
+}
+
+// missedLookback is how far before the grace window reportMissed looks for
+// reminders that were never sent.
+const missedLookback = 24 * time.Hour
+
+// reportMissed logs the reminders due in the missedLookback before until that
+// were never sent, typically because the server was down. They are not sent
+// any more: a late flood of reminders for past events is worse than none.
+func (d *dispatcher) reportMissed(until time.Time) error {
+	due, err := Due(d.app, d.cfg, until.Add(-missedLookback), until)
+	if err != nil {
+		return err
+	}
+	missed := 0
+	for _, r := range due {
+		sent, err := isSent(d.app, r)
+		if err != nil {
+			return err
+		}
+		if sent {
+			continue
+		}
+		missed++
+		d.app.Logger().Warn("reminder missed", "event", r.EventID, "start", r.Occurrence.Start, "minutes", r.Minutes)
+	}
+	if missed > 0 {
+		d.app.Logger().Warn("reminders missed while the server was down", "count", missed, "graceMinutes", int(d.cfg.ReminderGrace/time.Minute))
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package reminders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+	_ "schedule/migrations"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// newTestApp is a migrated app with one user in the given time zone.
+func newTestApp(t *testing.T, timezone string) (*tests.TestApp, *core.Record) {
+	t.Helper()
+	app, err := tests.NewTestApp(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app.Cleanup)
+
+	users, err := app.FindCollectionByNameOrId(calendar.UsersCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := core.NewRecord(users)
+	user.SetEmail("owner@example.com")
+	user.SetPassword("password123")
+	user.Set("timezone", timezone)
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	return app, user
+}
+
+func saveEvent(t *testing.T, app core.App, fields map[string]any) *core.Record {
+	t.Helper()
+	events, err := app.FindCollectionByNameOrId(calendar.EventsCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := core.NewRecord(events)
+	rec.Load(fields)
+	if err := app.Save(rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestDueFloatingInOwnerZone(t *testing.T) {
+	app, owner := newTestApp(t, "Europe/Berlin")
+	cfg := testConfig(t)
+	// 09:00 wall clock wherever the owner is: 08:00 UTC in Berlin (CET)
+	saveEvent(t, app, map[string]any{
+		"title": "Gym", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 10:00:00.000Z",
+		"floating": true, "owner": owner.Id, "reminderMinutes": []int{10},
+	})
+
+	due, err := Due(app, cfg, time.Date(2026, 3, 10, 7, 45, 0, 0, time.UTC), time.Date(2026, 3, 10, 7, 55, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("expected 1 reminder due at 07:50 UTC, got %d", len(due))
+	}
+	if want := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC); !due[0].Occurrence.Start.Equal(want) {
+		t.Fatalf("expected the occurrence at %s, got %s", want, due[0].Occurrence.Start)
+	}
+
+	// nothing at the UTC reading of the wall clock
+	due, err = Due(app, cfg, time.Date(2026, 3, 10, 8, 45, 0, 0, time.UTC), time.Date(2026, 3, 10, 8, 55, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("expected no reminder at 08:50 UTC, got %d", len(due))
+	}
+}
+
+func TestDueSeriesFollowsOwnerDST(t *testing.T) {
+	app, owner := newTestApp(t, "Europe/Berlin")
+	cfg := testConfig(t)
+	// 09:00 in Berlin every day, from before the switch to summer time
+	saveEvent(t, app, map[string]any{
+		"title": "Standup", "start": "2026-03-27 08:00:00.000Z", "end": "2026-03-27 08:15:00.000Z",
+		"rrule": "FREQ=DAILY", "owner": owner.Id, "reminderMinutes": []int{10},
+	})
+
+	// 09:00 CEST is 07:00 UTC after March 29
+	due, err := Due(app, cfg, time.Date(2026, 3, 30, 6, 45, 0, 0, time.UTC), time.Date(2026, 3, 30, 6, 55, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("expected 1 reminder due at 06:50 UTC, got %d", len(due))
+	}
+	if want := time.Date(2026, 3, 30, 7, 0, 0, 0, time.UTC); !due[0].Occurrence.Start.Equal(want) {
+		t.Fatalf("expected the occurrence at %s, got %s", want, due[0].Occurrence.Start)
+	}
+}
+
+func TestDueOnlyLoadsReminderEvents(t *testing.T) {
+	app, owner := newTestApp(t, "")
+	for _, fields := range []map[string]any{
+		{"title": "With", "reminderMinutes": []int{5}, "owner": owner.Id},
+		{"title": "Without", "owner": owner.Id},
+		{"title": "Cleared", "reminderMinutes": []int{}, "owner": owner.Id},
+		{"title": "Unowned", "reminderMinutes": []int{5}},
+	} {
+		fields["start"], fields["end"] = "2026-03-10 09:00:00.000Z", "2026-03-10 10:00:00.000Z"
+		saveEvent(t, app, fields)
+	}
+
+	events, err := calendar.FindReminderEvents(app, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Title != "With" {
+		t.Fatalf("expected only the owned event with reminders, got %d events", len(events))
+	}
+}
+
+// flakyChannel fails its first failures deliveries.
+type flakyChannel struct {
+	failures  int
+	delivered int
+}
+
+func (c *flakyChannel) Name() string { return calendar.ChannelWebhook }
+
+func (c *flakyChannel) Deliver(app core.App, r Reminder) error {
+	if c.failures > 0 {
+		c.failures--
+		return errors.New("unreachable")
+	}
+	c.delivered++
+	return nil
+}
+
+func TestEscalationWaitsForADelivery(t *testing.T) {
+	app, owner := newTestApp(t, "")
+	cfg := testConfig(t)
+	ev := saveEvent(t, app, map[string]any{
+		"title": "Pills", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 09:05:00.000Z", "owner": owner.Id,
+		"reminderMinutes": []int{10}, "reminderChannels": []string{calendar.ChannelWebhook},
+		"escalateEveryMinutes": 5, "escalateMax": 2,
+	})
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	fireAt := start.Add(-10 * time.Minute)
+
+	ch := &flakyChannel{failures: 1}
+	d := &dispatcher{app: app, cfg: cfg, channels: []Channel{ch}}
+	if err := d.dispatch(fireAt.Add(-time.Minute), fireAt.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := findState(app, ev.Id, start); err != nil || state != nil {
+		t.Fatalf("expected no escalation while every channel failed, got %v (%v)", state, err)
+	}
+	if len(d.retries) != 1 {
+		t.Fatalf("expected the failed channel to be queued, got %d retries", len(d.retries))
+	}
+
+	d.retry(fireAt.Add(2 * time.Minute))
+	if ch.delivered != 1 {
+		t.Fatalf("expected the retry to deliver, got %d deliveries", ch.delivered)
+	}
+	state, err := findState(app, ev.Id, start)
+	if err != nil || state == nil {
+		t.Fatalf("expected the escalation to start after the retry delivered, got %v (%v)", state, err)
+	}
+}
+
+func TestEscalationFollowsOwnerDST(t *testing.T) {
+	app, owner := newTestApp(t, "Europe/Berlin")
+	cfg := testConfig(t)
+	// 09:00 in Berlin every day, from before the switch to summer time
+	ev := saveEvent(t, app, map[string]any{
+		"title": "Pills", "start": "2026-03-27 08:00:00.000Z", "end": "2026-03-27 08:05:00.000Z", "owner": owner.Id,
+		"rrule": "FREQ=DAILY", "reminderMinutes": []int{10}, "reminderChannels": []string{calendar.ChannelWebhook},
+		"escalateEveryMinutes": 5, "escalateMax": 2,
+	})
+	// 09:00 CEST is 07:00 UTC after March 29
+	start := time.Date(2026, 3, 30, 7, 0, 0, 0, time.UTC)
+	fireAt := start.Add(-10 * time.Minute)
+
+	ch := &flakyChannel{}
+	d := &dispatcher{app: app, cfg: cfg, channels: []Channel{ch}}
+	if err := d.dispatch(fireAt.Add(-time.Minute), fireAt.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if ch.delivered != 1 {
+		t.Fatalf("expected the first reminder, got %d deliveries", ch.delivered)
+	}
+
+	if err := d.escalate(fireAt.Add(7 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if ch.delivered != 2 {
+		t.Fatalf("expected a follow-up, got %d deliveries", ch.delivered)
+	}
+	state, err := findState(app, ev.Id, start)
+	if err != nil || state == nil {
+		t.Fatalf("expected the escalation state to be kept, got %v (%v)", state, err)
+	}
+	if got := state.GetInt("attempts"); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestFollowUpOfFloatingEvent(t *testing.T) {
+	app, owner := newTestApp(t, "Europe/Berlin")
+	cfg := testConfig(t)
+	saveEvent(t, app, map[string]any{
+		"title": "Gym", "start": "2026-03-10 09:00:00.000Z", "end": "2026-03-10 10:00:00.000Z", "owner": owner.Id,
+		"floating": true, "rrule": "FREQ=WEEKLY", "reminderMinutes": []int{10},
+		"escalateEveryMinutes": 5, "escalateMax": 2,
+	})
+	// 09:00 wall clock in Berlin, a week later
+	start := time.Date(2026, 3, 17, 8, 0, 0, 0, time.UTC)
+
+	due, err := Due(app, cfg, start.Add(-11*time.Minute), start.Add(-9*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("expected 1 reminder due, got %d", len(due))
+	}
+	collection, err := app.FindCollectionByNameOrId(ReminderStateCollection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := core.NewRecord(collection)
+	state.Set("event", due[0].EventID)
+	state.Set("occurrenceStart", start)
+	state.Set("attempts", 1)
+
+	r, limit, err := followUp(app, cfg, state, start.Add(-5*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 2 || !r.Occurrence.Start.Equal(start) || r.Attempt != 2 {
+		t.Fatalf("expected the second reminder of %s, got %+v (limit %d)", start, r, limit)
+	}
+}
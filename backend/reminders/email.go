@@ -0,0 +1,58 @@
+package reminders
+
+import (
+	"fmt"
+	"html"
+
+	"schedule/calendar"
+	"schedule/config"
+	"schedule/mailqueue"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// emailChannel queues reminders for the owner's address. The mail queue
+// paces the actual sends.
+type emailChannel struct {
+	cfg *config.Config
+}
+
+func (emailChannel) Name() string { return calendar.ChannelEmail }
+
+// Deliver enqueues the reminder email, its times formatted in the owner's
+// locale and timezone (the configured default zone when unset or unknown).
+func (c emailChannel) Deliver(app core.App, r Reminder) error {
+	owner, err := app.FindRecordById(calendar.UsersCollection, r.Owner)
+	if err != nil {
+		return err
+	}
+
+	loc := calendar.UserZone(owner, c.cfg.Timezone)
+	format := formatFor(owner.GetString("locale"))
+	start := r.Occurrence.Start.In(loc)
+
+	text := fmt.Sprintf("%s starts at %s.", r.Occurrence.Title, format.dateTime(start))
+	if r.Occurrence.AllDay {
+		text = fmt.Sprintf("%s is on %s.", r.Occurrence.Title, start.Format(format.Date))
+	}
+	if r.Occurrence.Location != "" {
+		text += "\nLocation: " + r.Occurrence.Location
+	}
+
+	subject := "Reminder: " + r.Occurrence.Title
+	if r.Attempt > 1 {
+		subject = "Reminder (again): " + r.Occurrence.Title
+	}
+	if r.Occurrence.AllDay {
+		subject += " on " + start.Format(format.Date)
+	} else {
+		subject += " at " + format.clockTime(start)
+	}
+
+	return mailqueue.Enqueue(app, mailqueue.Message{
+		To:      owner.Email(),
+		Subject: subject,
+		Text:    text,
+		HTML:    "<p>" + html.EscapeString(text) + "</p>",
+	})
+}
@@ -0,0 +1,160 @@
+package reminders
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ReminderStateCollection tracks the escalation of delivered reminders, one
+// record per occurrence: follow-ups sent so far, when the next one is due and
+// whether the owner dismissed the reminder.
+const ReminderStateCollection = "reminder_state"
+
+func stateKey(eventID string, start time.Time) dbx.HashExp {
+	return dbx.HashExp{
+		"event":           eventID,
+		"occurrenceStart": start.UTC().Format(types.DefaultDateLayout),
+	}
+}
+
+// findState returns the state of one occurrence, nil when there is none.
+func findState(app core.App, eventID string, start time.Time) (*core.Record, error) {
+	records, err := app.FindAllRecords(ReminderStateCollection, stateKey(eventID, start))
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return records[0], nil
+}
+
+// startEscalation schedules the first follow-up of r, delivered at now. An
+// occurrence escalates once: a second reminder lead time (or an earlier
+// dismissal) finds the state already there.
+func startEscalation(app core.App, r Reminder, now time.Time) error {
+	rec, err := findState(app, r.EventID, r.Occurrence.Start)
+	if err != nil || rec != nil {
+		return err
+	}
+
+	collection, err := app.FindCollectionByNameOrId(ReminderStateCollection)
+	if err != nil {
+		return err
+	}
+	rec = core.NewRecord(collection)
+	rec.Set("event", r.EventID)
+	rec.Set("occurrenceStart", r.Occurrence.Start)
+	rec.Set("attempts", 1)
+	rec.Set("nextAt", now.Add(r.Escalate))
+	return app.Save(rec)
+}
+
+// escalate sends the follow-ups due at now. Escalation of an occurrence ends
+// when it is dismissed, after the event's EscalateMax follow-ups, or once the
+// occurrence starts. Ended states are kept until then so a later lead time
+// doesn't start over; those whose event stopped escalating or lost the
+// instance are deleted right away.
+func (d *dispatcher) escalate(now time.Time) error {
+	if _, err := d.app.DB().Delete(ReminderStateCollection, dbx.NewExp(
+		"occurrenceStart <= {:now}", dbx.Params{"now": now.UTC().Format(types.DefaultDateLayout)},
+	)).Execute(); err != nil {
+		return err
+	}
+
+	due, err := d.app.FindRecordsByFilter(ReminderStateCollection,
+		"dismissed = false && nextAt != '' && nextAt <= {:now}", "nextAt", 0, 0,
+		dbx.Params{"now": now.UTC().Format(types.DefaultDateLayout)},
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range due {
+		r, limit, err := followUp(d.app, d.cfg, state, now)
+		if err != nil {
+			return err
+		}
+		if limit == 0 {
+			if err := d.app.Delete(state); err != nil {
+				return err
+			}
+			continue
+		}
+
+		d.deliver(r)
+
+		state.Set("attempts", r.Attempt)
+		if r.Attempt > limit {
+			state.Set("nextAt", "") // done, kept so the occurrence doesn't escalate again
+		} else {
+			state.Set("nextAt", now.Add(r.Escalate))
+		}
+		if err := d.app.Save(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followUp builds the next reminder of state along with the event's
+// EscalateMax, which is zero when the escalation is over. The instance is
+// looked up in the owner's zone, as Due found it.
+func followUp(app core.App, cfg *config.Config, state *core.Record, now time.Time) (Reminder, int, error) {
+	rec, err := app.FindRecordById(calendar.EventsCollection, state.GetString("event"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Reminder{}, 0, nil
+		}
+		return Reminder{}, 0, err
+	}
+	ev := calendar.EventFromRecord(rec)
+	attempts := state.GetInt("attempts")
+	if !ev.Escalates() || ev.Owner == "" || attempts > ev.EscalateMax {
+		return Reminder{}, 0, nil
+	}
+
+	start := state.GetDateTime("occurrenceStart").Time()
+	for _, o := range ev.Occurrences(start, start.Add(time.Second), OwnerZone(app, cfg, ev.Owner)) {
+		if !o.Start.Equal(start) {
+			continue
+		}
+		return Reminder{
+			Occurrence: o,
+			EventID:    ev.ID,
+			Owner:      ev.Owner,
+			Minutes:    int(start.Sub(now) / time.Minute),
+			Type:       ev.ReminderType,
+			Attempt:    attempts + 1,
+			Escalate:   ev.EscalateEvery,
+			Channels:   ev.Channels(),
+		}, ev.EscalateMax, nil
+	}
+	return Reminder{}, 0, nil
+}
+
+// Dismiss acknowledges the reminder of the occurrence of eventID starting at
+// start, stopping (or preventing) its follow-ups.
+func Dismiss(app core.App, eventID string, start time.Time) error {
+	rec, err := findState(app, eventID, start)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		collection, err := app.FindCollectionByNameOrId(ReminderStateCollection)
+		if err != nil {
+			return err
+		}
+		rec = core.NewRecord(collection)
+		rec.Set("event", eventID)
+		rec.Set("occurrenceStart", start)
+	}
+	rec.Set("dismissed", true)
+	rec.Set("nextAt", "")
+	return app.Save(rec)
+}
@@ -0,0 +1,66 @@
+package reminders
+
+import (
+	"strings"
+	"time"
+)
+
+// localeFormat is how a locale writes times and dates. Dates are numeric, Go
+// only knows English month and day names.
+type localeFormat struct {
+	Time string
+	Date string
+}
+
+// defaultLocaleFormat is used for users without a (known) locale: 24-hour
+// times and ISO dates read the same everywhere.
+var defaultLocaleFormat = localeFormat{Time: "15:04", Date: "2006-01-02"}
+
+// localeFormats maps lowercase BCP 47 tags, or just their language, to
+// their format.
+var localeFormats = map[string]localeFormat{
+	"en":    {Time: "3:04 PM", Date: "01/02/2006"},
+	"en-us": {Time: "3:04 PM", Date: "01/02/2006"},
+	"en-ca": {Time: "3:04 PM", Date: "2006-01-02"},
+	"en-au": {Time: "3:04 PM", Date: "02/01/2006"},
+	"en-gb": {Time: "15:04", Date: "02/01/2006"},
+	"en-ie": {Time: "15:04", Date: "02/01/2006"},
+	"de":    {Time: "15:04", Date: "02.01.2006"},
+	"fr":    {Time: "15:04", Date: "02/01/2006"},
+	"fr-ca": {Time: "15 h 04", Date: "2006-01-02"},
+	"es":    {Time: "15:04", Date: "02/01/2006"},
+	"it":    {Time: "15:04", Date: "02/01/2006"},
+	"nl":    {Time: "15:04", Date: "02-01-2006"},
+	"pt":    {Time: "15:04", Date: "02/01/2006"},
+	"sv":    {Time: "15:04", Date: "2006-01-02"},
+	"pl":    {Time: "15:04", Date: "02.01.2006"},
+	"ru":    {Time: "15:04", Date: "02.01.2006"},
+	"ja":    {Time: "15:04", Date: "2006/01/02"},
+	"zh":    {Time: "15:04", Date: "2006/01/02"},
+	"ko":    {Time: "PM 3:04", Date: "2006. 01. 02."},
+}
+
+// formatFor resolves locale ("en-US", "de_DE", "fr") to its format, trying
+// the whole tag before the language.
+func formatFor(locale string) localeFormat {
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if f, ok := localeFormats[tag]; ok {
+		return f
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if f, ok := localeFormats[lang]; ok {
+		return f
+	}
+	return defaultLocaleFormat
+}
+
+// clockTime formats the time of day of t with the zone abbreviation, e.g.
+// "9:00 AM EST" or "09:00 CET".
+func (f localeFormat) clockTime(t time.Time) string {
+	return t.Format(f.Time + " MST")
+}
+
+// dateTime formats the date and time of t with the zone abbreviation.
+func (f localeFormat) dateTime(t time.Time) string {
+	return t.Format(f.Date + " " + f.Time + " MST")
+}
@@ -0,0 +1,86 @@
+package reminders
+
+import (
+	"testing"
+	"time"
+
+	"schedule/mailqueue"
+)
+
+func TestLocaleFormats(t *testing.T) {
+	zone := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc
+	}
+	newYork, berlin, london := zone("America/New_York"), zone("Europe/Berlin"), zone("Europe/London")
+
+	tests := []struct {
+		locale        string
+		at            time.Time
+		clock, dateAt string
+	}{
+		{"en-US", time.Date(2026, 1, 15, 9, 0, 0, 0, newYork), "9:00 AM EST", "01/15/2026 9:00 AM EST"},
+		{"en-US", time.Date(2026, 7, 15, 21, 30, 0, 0, newYork), "9:30 PM EDT", "07/15/2026 9:30 PM EDT"},
+		{"EN-us", time.Date(2026, 1, 15, 9, 0, 0, 0, newYork), "9:00 AM EST", "01/15/2026 9:00 AM EST"},
+		{"en", time.Date(2026, 1, 15, 0, 5, 0, 0, newYork), "12:05 AM EST", "01/15/2026 12:05 AM EST"},
+		{"en-GB", time.Date(2026, 1, 15, 21, 0, 0, 0, london), "21:00 GMT", "15/01/2026 21:00 GMT"},
+		{"de", time.Date(2026, 1, 15, 9, 0, 0, 0, berlin), "09:00 CET", "15.01.2026 09:00 CET"},
+		{"de-AT", time.Date(2026, 7, 15, 9, 0, 0, 0, berlin), "09:00 CEST", "15.07.2026 09:00 CEST"},
+		{"de_DE", time.Date(2026, 1, 15, 9, 0, 0, 0, berlin), "09:00 CET", "15.01.2026 09:00 CET"},
+		{"fr-CA", time.Date(2026, 1, 15, 9, 0, 0, 0, newYork), "09 h 00 EST", "2026-01-15 09 h 00 EST"},
+		{"tlh", time.Date(2026, 1, 15, 9, 0, 0, 0, berlin), "09:00 CET", "2026-01-15 09:00 CET"},
+		{"", time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC), "09:00 UTC", "2026-01-15 09:00 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			f := formatFor(tt.locale)
+			if got := f.clockTime(tt.at); got != tt.clock {
+				t.Errorf("clockTime = %q, want %q", got, tt.clock)
+			}
+			if got := f.dateTime(tt.at); got != tt.dateAt {
+				t.Errorf("dateTime = %q, want %q", got, tt.dateAt)
+			}
+		})
+	}
+}
+
+func TestReminderEmailSubject(t *testing.T) {
+	tests := []struct {
+		locale, timezone string
+		allDay           bool
+		want             string
+	}{
+		{"en-US", "America/New_York", false, "Reminder: Standup at 9:00 AM EST"},
+		{"de_DE", "Europe/Berlin", false, "Reminder: Standup at 15:00 CET"},
+		{"en-GB", "Europe/London", true, "Reminder: Standup on 15/01/2026"},
+		{"", "", false, "Reminder: Standup at 14:00 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			app, owner := newTestApp(t, tt.timezone)
+			owner.Set("locale", tt.locale)
+			if err := app.Save(owner); err != nil {
+				t.Fatal(err)
+			}
+			cfg := testConfig(t)
+			cfg.Timezone = time.UTC
+
+			start := time.Date(2026, 1, 15, 14, 0, 0, 0, time.UTC)
+			r := Reminder{Owner: owner.Id, Minutes: 10, Attempt: 1}
+			r.Occurrence.Title, r.Occurrence.Start, r.Occurrence.AllDay = "Standup", start, tt.allDay
+			if err := (emailChannel{cfg: cfg}).Deliver(app, r); err != nil {
+				t.Fatal(err)
+			}
+			queued, err := app.FindFirstRecordByData(mailqueue.Collection, "to", "owner@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := queued.GetString("subject"); got != tt.want {
+				t.Fatalf("subject = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
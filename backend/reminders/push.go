@@ -0,0 +1,102 @@
+package reminders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/SherClockHolmes/webpush-go"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// PushSubscriptionsCollection stores the browser push subscriptions of users.
+const PushSubscriptionsCollection = "push_subscriptions"
+
+// pushTTL is how long (in seconds) the push service keeps an undelivered
+// reminder; a reminder delivered hours late is noise.
+const pushTTL = 60 * 60
+
+// pushChannel sends reminders as Web Push notifications signed with the
+// configured VAPID keys.
+type pushChannel struct {
+	cfg *config.Config
+}
+
+// pushPayload is the JSON the service worker receives.
+type pushPayload struct {
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	EventID      string `json:"eventId"`
+	OccurrenceID string `json:"occurrenceId"`
+	Start        string `json:"start"`
+	// Attempt is above 1 for escalation follow-ups; the service worker can
+	// offer a dismiss action calling /events/{id}/dismiss-reminder.
+	Attempt int `json:"attempt"`
+}
+
+func (*pushChannel) Name() string { return calendar.ChannelPush }
+
+// Deliver sends r to every subscription of its owner. Subscriptions the push
+// service reports as gone (404/410) are deleted.
+func (p *pushChannel) Deliver(app core.App, r Reminder) error {
+	subs, err := app.FindAllRecords(PushSubscriptionsCollection, dbx.HashExp{"user": r.Owner})
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	body := "Starts now"
+	if r.Minutes > 0 {
+		body = fmt.Sprintf("Starts in %d min", r.Minutes)
+	}
+	if r.Occurrence.Location != "" {
+		body += " · " + r.Occurrence.Location
+	}
+
+	payload, err := json.Marshal(pushPayload{
+		Title:        r.Occurrence.Title,
+		Body:         body,
+		EventID:      r.EventID,
+		OccurrenceID: r.Occurrence.ID,
+		Start:        calendar.ISO(r.Occurrence.Start),
+		Attempt:      r.Attempt,
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, sub := range subs {
+		resp, err := webpush.SendNotification(payload, &webpush.Subscription{
+			Endpoint: sub.GetString("endpoint"),
+			Keys: webpush.Keys{
+				P256dh: sub.GetString("p256dh"),
+				Auth:   sub.GetString("auth"),
+			},
+		}, &webpush.Options{
+			Subscriber:      p.cfg.VAPIDSubject,
+			VAPIDPublicKey:  p.cfg.VAPIDPublicKey,
+			VAPIDPrivateKey: p.cfg.VAPIDPrivateKey,
+			TTL:             pushTTL,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+			if err := app.Delete(sub); err != nil {
+				errs = append(errs, err)
+			}
+		case resp.StatusCode >= 400:
+			errs = append(errs, fmt.Errorf("push service responded %d for subscription %s", resp.StatusCode, sub.Id))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package reminders
+
+import (
+	"time"
+
+	"schedule/config"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// SentRemindersCollection records the reminders already delivered, keyed by
+// event, occurrence start and lead time.
+const SentRemindersCollection = "sent_reminders"
+
+func sentKey(r Reminder) dbx.HashExp {
+	return dbx.HashExp{
+		"event":           r.EventID,
+		"occurrenceStart": r.Occurrence.Start.UTC().Format(types.DefaultDateLayout),
+		"minutes":         r.Minutes,
+	}
+}
+
+// isSent reports whether r was already delivered through any channel (or
+// marked as such).
+func isSent(app core.App, r Reminder) (bool, error) {
+	n, err := app.CountRecords(SentRemindersCollection, sentKey(r))
+	return n > 0, err
+}
+
+// sentChannels returns the channels r was delivered through. An empty channel
+// stands for all of them: MarkSent and the rows recorded before reminders
+// were tracked per channel.
+func sentChannels(app core.App, r Reminder) (map[string]bool, error) {
+	records, err := app.FindAllRecords(SentRemindersCollection, sentKey(r))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]bool, len(records))
+	for _, rec := range records {
+		out[rec.GetString("channel")] = true
+	}
+	return out, nil
+}
+
+// markSent records r as delivered through channel, every channel when empty.
+func markSent(app core.App, r Reminder, channel string) error {
+	collection, err := app.FindCollectionByNameOrId(SentRemindersCollection)
+	if err != nil {
+		return err
+	}
+	rec := core.NewRecord(collection)
+	rec.Set("event", r.EventID)
+	rec.Set("occurrenceStart", r.Occurrence.Start)
+	rec.Set("minutes", r.Minutes)
+	rec.Set("channel", channel)
+	return app.Save(rec)
+}
+
+// ResetSent forgets the delivered reminders of the occurrences starting in
+// [from, to), so they can fire again. It returns how many were cleared.
+func ResetSent(app core.App, from, to time.Time) (int64, error) {
+	res, err := app.DB().Delete(SentRemindersCollection, dbx.NewExp(
+		"occurrenceStart >= {:from} AND occurrenceStart < {:to}",
+		dbx.Params{
+			"from": from.UTC().Format(types.DefaultDateLayout),
+			"to":   to.UTC().Format(types.DefaultDateLayout),
+		},
+	)).Execute()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MarkSent records every reminder triggering in [from, to) as delivered
+// without sending it, e.g. to backfill the state on a first deploy. It returns
+// how many were newly marked.
+func MarkSent(app core.App, cfg *config.Config, from, to time.Time) (int, error) {
+	due, err := Due(app, cfg, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	err = app.RunInTransaction(func(txApp core.App) error {
+		for _, r := range due {
+			sent, err := isSent(txApp, r)
+			if err != nil {
+				return err
+			}
+			if sent {
+				continue
+			}
+			if err := markSent(txApp, r, ""); err != nil {
+				return err
+			}
+			marked++
+		}
+		return nil
+	})
+	return marked, err
+}
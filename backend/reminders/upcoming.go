@@ -0,0 +1,184 @@
+package reminders
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Scheduled is a reminder the dispatcher is going to deliver.
+type Scheduled struct {
+	EventID      string    `json:"event"`
+	Title        string    `json:"title"`
+	OccurrenceID string    `json:"occurrenceId"`
+	Start        time.Time `json:"start"`
+	// Minutes is the lead time before Start, as in reminderMinutes.
+	Minutes int       `json:"minutes"`
+	FireAt  time.Time `json:"fireAt"`
+	// Attempt is above 1 for escalation follow-ups.
+	Attempt  int      `json:"attempt"`
+	Channels []string `json:"channels"`
+}
+
+// Upcoming lists the reminders of owner's events (every owner's when empty)
+// that fire in [from, to), sorted by fire time. from is raised to now, late
+// reminders are only caught up on startup. Reminders already sent through
+// every channel are left out, those sent through some only list the channels
+// still owed. Follow-ups of escalating events are projected from their
+// interval, except for dismissed reminders. Reminders can't be snoozed, nor
+// limited to the next occurrence of a series, so neither is accounted for.
+func Upcoming(app core.App, cfg *config.Config, owner string, from, to time.Time) ([]Scheduled, error) {
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	if !from.Before(to) {
+		return nil, nil
+	}
+
+	due, err := Due(app, cfg, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Scheduled
+	subscribed := map[string]bool{}
+	webhook := map[string]bool{}
+	add := func(r Reminder, at time.Time) {
+		// the channels that deliver r, as in dispatcher.deliver
+		channels := []string{}
+		if slices.Contains(r.Channels, calendar.ChannelEmail) {
+			channels = append(channels, calendar.ChannelEmail)
+		}
+		if cfg.PushEnabled() && slices.Contains(r.Channels, calendar.ChannelPush) {
+			has, ok := subscribed[r.Owner]
+			if !ok {
+				n, _ := app.CountRecords(PushSubscriptionsCollection, dbx.HashExp{"user": r.Owner})
+				has, subscribed[r.Owner] = n > 0, n > 0
+			}
+			if has {
+				channels = append(channels, calendar.ChannelPush)
+			}
+		}
+		if slices.Contains(r.Channels, calendar.ChannelWebhook) {
+			has, ok := webhook[r.Owner]
+			if !ok {
+				owner, err := app.FindRecordById(calendar.UsersCollection, r.Owner)
+				has = err == nil && owner.GetString("reminderWebhook") != ""
+				webhook[r.Owner] = has
+			}
+			if has {
+				channels = append(channels, calendar.ChannelWebhook)
+			}
+		}
+		out = append(out, Scheduled{
+			EventID:      r.EventID,
+			Title:        r.Occurrence.Title,
+			OccurrenceID: r.Occurrence.ID,
+			Start:        r.Occurrence.Start,
+			Minutes:      r.Minutes,
+			FireAt:       at,
+			Attempt:      r.Attempt,
+			Channels:     channels,
+		})
+	}
+
+	// escalating occurrences start their follow-ups with their first reminder
+	escalating := map[string]*Reminder{}
+	limits := map[string]int{}
+	for i, r := range due {
+		if owner != "" && r.Owner != owner {
+			continue
+		}
+		sent, err := sentChannels(app, r)
+		if err != nil {
+			return nil, err
+		}
+		// only the channels still owed, e.g. one being retried
+		pending := r
+		pending.Channels = slices.DeleteFunc(slices.Clone(r.Channels), func(ch string) bool { return sent[""] || sent[ch] })
+		if len(sent) > 0 && len(pending.Channels) == 0 {
+			continue
+		}
+		add(pending, r.Occurrence.Start.Add(-time.Duration(r.Minutes)*time.Minute))
+
+		if r.Escalate == 0 {
+			continue
+		}
+		key := calendar.OccurrenceID(r.EventID, r.Occurrence.Start)
+		if first, ok := escalating[key]; !ok || r.Minutes > first.Minutes {
+			escalating[key] = &due[i]
+		}
+	}
+	for key, r := range escalating {
+		state, err := findState(app, r.EventID, r.Occurrence.Start)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			delete(escalating, key) // already escalating, or dismissed
+			continue
+		}
+		rec, err := app.FindRecordById(calendar.EventsCollection, r.EventID)
+		if err != nil {
+			return nil, err
+		}
+		limits[key] = calendar.EventFromRecord(rec).EscalateMax
+	}
+	for key, r := range escalating {
+		at := r.Occurrence.Start.Add(-time.Duration(r.Minutes) * time.Minute)
+		projectFollowUps(add, *r, at.Add(r.Escalate), 2, limits[key], to)
+	}
+
+	// follow-ups of reminders already delivered
+	states, err := app.FindRecordsByFilter(ReminderStateCollection,
+		"dismissed = false && nextAt != '' && nextAt < {:to} && occurrenceStart > {:from}", "", 0, 0,
+		dbx.Params{
+			"from": from.UTC().Format(types.DefaultDateLayout),
+			"to":   to.UTC().Format(types.DefaultDateLayout),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		at := state.GetDateTime("nextAt").Time()
+		r, limit, err := followUp(app, cfg, state, at)
+		if err != nil {
+			return nil, err
+		}
+		if limit == 0 || (owner != "" && r.Owner != owner) {
+			continue
+		}
+		if at.Before(from) {
+			at = from // overdue, sent on the next tick
+		}
+		projectFollowUps(add, r, at, r.Attempt, limit, to)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].FireAt.Equal(out[j].FireAt) {
+			return out[i].FireAt.Before(out[j].FireAt)
+		}
+		return out[i].EventID < out[j].EventID
+	})
+	return out, nil
+}
+
+// projectFollowUps adds the follow-ups of r from attempt on, every r.Escalate
+// starting at at, while they fire before to and before the occurrence
+// starts. limit is the event's EscalateMax.
+func projectFollowUps(add func(Reminder, time.Time), r Reminder, at time.Time, attempt, limit int, to time.Time) {
+	for ; attempt <= limit+1 && at.Before(to) && at.Before(r.Occurrence.Start); attempt++ {
+		r.Attempt = attempt
+		r.Minutes = int(r.Occurrence.Start.Sub(at) / time.Minute)
+		add(r, at)
+		at = at.Add(r.Escalate)
+	}
+}
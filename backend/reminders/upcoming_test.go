@@ -0,0 +1,81 @@
+package reminders
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"schedule/calendar"
+)
+
+func TestUpcomingOwedChannels(t *testing.T) {
+	app, owner := newTestApp(t, "")
+	owner.Set("reminderWebhook", "https://hooks.example.com/reminders")
+	if err := app.Save(owner); err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig(t)
+	start := time.Now().UTC().Truncate(time.Minute).Add(2 * time.Hour)
+	saveEvent(t, app, map[string]any{
+		"title": "Standup", "start": start, "end": start.Add(15 * time.Minute), "owner": owner.Id,
+		"reminderMinutes": []int{10}, "reminderChannels": []string{calendar.ChannelEmail, calendar.ChannelWebhook},
+	})
+	from, to := start.Add(-time.Hour), start
+
+	channels := func() [][]string {
+		t.Helper()
+		items, err := Upcoming(app, cfg, owner.Id, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out [][]string
+		for _, it := range items {
+			out = append(out, it.Channels)
+		}
+		return out
+	}
+	due, err := Due(app, cfg, from, to)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("expected 1 reminder due, got %d (%v)", len(due), err)
+	}
+
+	if got := channels(); len(got) != 1 || !slices.Equal(got[0], []string{calendar.ChannelEmail, calendar.ChannelWebhook}) {
+		t.Fatalf("before any delivery: %v", got)
+	}
+	// the email went out, the webhook is being retried
+	if err := markSent(app, due[0], calendar.ChannelEmail); err != nil {
+		t.Fatal(err)
+	}
+	if got := channels(); len(got) != 1 || !slices.Equal(got[0], []string{calendar.ChannelWebhook}) {
+		t.Fatalf("after the email: %v", got)
+	}
+	if err := markSent(app, due[0], calendar.ChannelWebhook); err != nil {
+		t.Fatal(err)
+	}
+	if got := channels(); len(got) != 0 {
+		t.Fatalf("after every channel: %v", got)
+	}
+}
+
+func TestUpcomingLeavesOutMarkedReminders(t *testing.T) {
+	app, owner := newTestApp(t, "")
+	cfg := testConfig(t)
+	start := time.Now().UTC().Truncate(time.Minute).Add(2 * time.Hour)
+	saveEvent(t, app, map[string]any{
+		"title": "Standup", "start": start, "end": start.Add(15 * time.Minute), "owner": owner.Id,
+		"reminderMinutes": []int{10, 30}, "reminderChannels": []string{calendar.ChannelEmail},
+	})
+	from, to := start.Add(-time.Hour), start
+
+	// a row without a channel stands for all of them
+	if _, err := MarkSent(app, cfg, start.Add(-31*time.Minute), start.Add(-29*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	items, err := Upcoming(app, cfg, owner.Id, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Minutes != 10 {
+		t.Fatalf("expected only the 10 minute reminder, got %+v", items)
+	}
+}
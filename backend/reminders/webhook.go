@@ -0,0 +1,95 @@
+package reminders
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"schedule/calendar"
+	"schedule/config"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// webhookTimeout bounds one webhook call; the dispatcher waits for it.
+const webhookTimeout = 10 * time.Second
+
+// webhookChannel posts reminders as JSON to the reminderWebhook URL of their
+// owner, signed with cfg.ReminderWebhookSecret when set.
+type webhookChannel struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+// webhookPayload is the JSON body of a webhook reminder.
+type webhookPayload struct {
+	EventID      string    `json:"eventId"`
+	OccurrenceID string    `json:"occurrenceId"`
+	Title        string    `json:"title"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	AllDay       bool      `json:"allDay"`
+	Location     string    `json:"location,omitempty"`
+	Minutes      int       `json:"minutes"`
+	Attempt      int       `json:"attempt"`
+}
+
+func newWebhookChannel(cfg *config.Config) *webhookChannel {
+	return &webhookChannel{cfg: cfg, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (*webhookChannel) Name() string { return calendar.ChannelWebhook }
+
+// Deliver posts r to the owner's webhook. Any answer but a 2xx is a failure.
+func (w *webhookChannel) Deliver(app core.App, r Reminder) error {
+	owner, err := app.FindRecordById(calendar.UsersCollection, r.Owner)
+	if err != nil {
+		return err
+	}
+	url := owner.GetString("reminderWebhook")
+	if url == "" {
+		return errors.New("the owner has no reminderWebhook")
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		EventID:      r.EventID,
+		OccurrenceID: r.Occurrence.ID,
+		Title:        r.Occurrence.Title,
+		Start:        r.Occurrence.Start.UTC(),
+		End:          r.Occurrence.End.UTC(),
+		AllDay:       r.Occurrence.AllDay,
+		Location:     r.Occurrence.Location,
+		Minutes:      r.Minutes,
+		Attempt:      r.Attempt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.cfg.ReminderWebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(w.cfg.ReminderWebhookSecret))
+		mac.Write(body)
+		req.Header.Set("X-Schedule-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}